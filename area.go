@@ -0,0 +1,488 @@
+package vesselapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// MaxRadius is the largest radius, in meters, accepted by the Location
+// radius endpoints.
+const MaxRadius = 100_000
+
+const (
+	// defaultAreaBoxTile is the default edge length of bounding-box tiles.
+	defaultAreaBoxTile = 100_000
+
+	// defaultAreaCircleTile is the default edge length of the square each
+	// radius tile covers. Its half-diagonal stays under MaxRadius.
+	defaultAreaCircleTile = 135_000
+
+	// defaultAreaConcurrency is the default number of tiles queried at once.
+	defaultAreaConcurrency = 4
+
+	// metersPerDegreeLat is the length of one degree of latitude.
+	metersPerDegreeLat = geo.EarthRadius * math.Pi / 180
+)
+
+// AreaTileShape selects the kind of request used to cover an area.
+type AreaTileShape int
+
+const (
+	// AreaTileBox covers the area with bounding-box requests.
+	AreaTileBox AreaTileShape = iota
+
+	// AreaTileCircle covers the area with radius requests, each circle
+	// circumscribing one square tile.
+	AreaTileCircle
+)
+
+// AreaOptions configures area queries. A nil *AreaOptions uses the defaults.
+type AreaOptions struct {
+	// Shape selects bounding-box (default) or radius tiles.
+	Shape AreaTileShape
+
+	// TileSize is the edge length of each square tile in meters. Defaults to
+	// 100 km for boxes and 135 km for circles; radius tiles must keep their
+	// circumscribed radius within MaxRadius.
+	TileSize float64
+
+	// Concurrency is the maximum number of tiles queried at once. Defaults
+	// to 4.
+	Concurrency int
+
+	// TimeFrom and TimeTo bound the position timestamps, in RFC 3339 format.
+	TimeFrom *string
+	TimeTo   *string
+
+	// PaginationLimit is the page size used for each tile.
+	PaginationLimit *int
+}
+
+// VesselsInArea returns the latest vessel positions inside area, which may be
+// arbitrarily large: a geo.Polygon, a geo.MultiPolygon parsed from GeoJSON,
+// or a geo.Corridor around a route. The area is split into API-sized tiles
+// that are queried concurrently; every page of every tile is fetched.
+// Positions reported by more than one tile are deduplicated by MMSI (falling
+// back to IMO), keeping the one with the freshest Timestamp, and only
+// positions strictly inside area are returned. Results are ordered by MMSI.
+//
+// The first tile error cancels the remaining requests and is returned.
+func (s *LocationService) VesselsInArea(ctx context.Context, area geo.Region, opts *AreaOptions) ([]VesselPosition, error) {
+	if opts == nil {
+		opts = &AreaOptions{}
+	}
+	latest := newPositionSet()
+	err := queryArea(ctx, area, opts, PositionPoint, latest.add, func(ctx context.Context, t BBox) ([]VesselPosition, error) {
+		if opts.Shape == AreaTileCircle {
+			lat, lon, radius := tileCircle(t)
+			return s.AllVesselsRadius(ctx, &GetLocationVesselsRadiusParams{
+				FilterLatitude:  lat,
+				FilterLongitude: lon,
+				FilterRadius:    radius,
+				TimeFrom:        opts.TimeFrom,
+				TimeTo:          opts.TimeTo,
+				PaginationLimit: opts.PaginationLimit,
+			}).Collect()
+		}
+		return s.AllVesselsInBBox(ctx, t, &GetLocationVesselsBoundingBoxParams{
+			TimeFrom:        opts.TimeFrom,
+			TimeTo:          opts.TimeTo,
+			PaginationLimit: opts.PaginationLimit,
+		}).Collect()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return latest.sorted(), nil
+}
+
+// PortsInArea returns the ports strictly inside area, deduplicated by
+// UN/LOCODE and ordered by it. See VesselsInArea for how area is covered;
+// opts.TimeFrom and opts.TimeTo are ignored.
+func (s *LocationService) PortsInArea(ctx context.Context, area geo.Region, opts *AreaOptions) ([]Port, error) {
+	if opts == nil {
+		opts = &AreaOptions{}
+	}
+	set := newAreaSet(func(p Port) string {
+		if p.UnloCode != nil {
+			return *p.UnloCode
+		}
+		pt, _ := portPoint(p)
+		return featureKey(p.Name, pt)
+	})
+	err := queryArea(ctx, area, opts, portPoint, set.add, func(ctx context.Context, t BBox) ([]Port, error) {
+		if opts.Shape == AreaTileCircle {
+			lat, lon, radius := tileCircle(t)
+			return s.AllPortsRadius(ctx, &GetLocationPortsRadiusParams{
+				FilterLatitude:  lat,
+				FilterLongitude: lon,
+				FilterRadius:    radius,
+				PaginationLimit: opts.PaginationLimit,
+			}).Collect()
+		}
+		return s.AllPortsInBBox(ctx, t, &GetLocationPortsBoundingBoxParams{PaginationLimit: opts.PaginationLimit}).Collect()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set.sorted(), nil
+}
+
+// DGPSInArea returns the DGPS stations strictly inside area, deduplicated by
+// name and position. See VesselsInArea for how area is covered;
+// opts.TimeFrom and opts.TimeTo are ignored.
+func (s *LocationService) DGPSInArea(ctx context.Context, area geo.Region, opts *AreaOptions) ([]DGPSStation, error) {
+	if opts == nil {
+		opts = &AreaOptions{}
+	}
+	point := LocatablePoint[DGPSStation]
+	set := newAreaSet(func(d DGPSStation) string {
+		pt, _ := point(d)
+		return featureKey(d.Name, pt)
+	})
+	err := queryArea(ctx, area, opts, point, set.add, func(ctx context.Context, t BBox) ([]DGPSStation, error) {
+		if opts.Shape == AreaTileCircle {
+			lat, lon, radius := tileCircle(t)
+			return s.AllDGPSRadius(ctx, &GetLocationDgpsRadiusParams{
+				FilterLatitude:  lat,
+				FilterLongitude: lon,
+				FilterRadius:    radius,
+				PaginationLimit: opts.PaginationLimit,
+			}).Collect()
+		}
+		return s.AllDGPSInBBox(ctx, t, &GetLocationDgpsBoundingBoxParams{PaginationLimit: opts.PaginationLimit}).Collect()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set.sorted(), nil
+}
+
+// LightAidsInArea returns the light aids strictly inside area, deduplicated
+// by name and position. See VesselsInArea for how area is covered;
+// opts.TimeFrom and opts.TimeTo are ignored.
+func (s *LocationService) LightAidsInArea(ctx context.Context, area geo.Region, opts *AreaOptions) ([]LightAid, error) {
+	if opts == nil {
+		opts = &AreaOptions{}
+	}
+	point := LocatablePoint[LightAid]
+	set := newAreaSet(func(l LightAid) string {
+		pt, _ := point(l)
+		return featureKey(l.Name, pt)
+	})
+	err := queryArea(ctx, area, opts, point, set.add, func(ctx context.Context, t BBox) ([]LightAid, error) {
+		if opts.Shape == AreaTileCircle {
+			lat, lon, radius := tileCircle(t)
+			return s.AllLightAidsRadius(ctx, &GetLocationLightaidsRadiusParams{
+				FilterLatitude:  lat,
+				FilterLongitude: lon,
+				FilterRadius:    radius,
+				PaginationLimit: opts.PaginationLimit,
+			}).Collect()
+		}
+		return s.AllLightAidsInBBox(ctx, t, &GetLocationLightaidsBoundingBoxParams{PaginationLimit: opts.PaginationLimit}).Collect()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set.sorted(), nil
+}
+
+// MODUsInArea returns the mobile offshore drilling units strictly inside
+// area, deduplicated by name and position. See VesselsInArea for how area is
+// covered; opts.TimeFrom and opts.TimeTo are ignored.
+func (s *LocationService) MODUsInArea(ctx context.Context, area geo.Region, opts *AreaOptions) ([]MODU, error) {
+	if opts == nil {
+		opts = &AreaOptions{}
+	}
+	point := LocatablePoint[MODU]
+	set := newAreaSet(func(m MODU) string {
+		pt, _ := point(m)
+		return featureKey(m.Name, pt)
+	})
+	err := queryArea(ctx, area, opts, point, set.add, func(ctx context.Context, t BBox) ([]MODU, error) {
+		if opts.Shape == AreaTileCircle {
+			lat, lon, radius := tileCircle(t)
+			return s.AllMODUsRadius(ctx, &GetLocationModuRadiusParams{
+				FilterLatitude:  lat,
+				FilterLongitude: lon,
+				FilterRadius:    radius,
+				PaginationLimit: opts.PaginationLimit,
+			}).Collect()
+		}
+		return s.AllMODUsInBBox(ctx, t, &GetLocationModuBoundingBoxParams{PaginationLimit: opts.PaginationLimit}).Collect()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set.sorted(), nil
+}
+
+// RadioBeaconsInArea returns the radio beacons strictly inside area,
+// deduplicated by name and position. See VesselsInArea for how area is
+// covered; opts.TimeFrom and opts.TimeTo are ignored.
+func (s *LocationService) RadioBeaconsInArea(ctx context.Context, area geo.Region, opts *AreaOptions) ([]RadioBeacon, error) {
+	if opts == nil {
+		opts = &AreaOptions{}
+	}
+	point := LocatablePoint[RadioBeacon]
+	set := newAreaSet(func(r RadioBeacon) string {
+		pt, _ := point(r)
+		return featureKey(r.Name, pt)
+	})
+	err := queryArea(ctx, area, opts, point, set.add, func(ctx context.Context, t BBox) ([]RadioBeacon, error) {
+		if opts.Shape == AreaTileCircle {
+			lat, lon, radius := tileCircle(t)
+			return s.AllRadioBeaconsRadius(ctx, &GetLocationRadiobeaconsRadiusParams{
+				FilterLatitude:  lat,
+				FilterLongitude: lon,
+				FilterRadius:    radius,
+				PaginationLimit: opts.PaginationLimit,
+			}).Collect()
+		}
+		return s.AllRadioBeaconsInBBox(ctx, t, &GetLocationRadiobeaconsBoundingBoxParams{PaginationLimit: opts.PaginationLimit}).Collect()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set.sorted(), nil
+}
+
+// queryArea tiles area and runs query for each tile on a pool of workers.
+// Every item whose point lies strictly inside area is passed to add, which
+// is called with a lock held. The first query error cancels the remaining
+// tiles and is returned.
+func queryArea[T any](ctx context.Context, area geo.Region, opts *AreaOptions, point func(T) (geo.Point, bool), add func(T), query func(context.Context, BBox) ([]T, error)) error {
+	tiles, err := tileArea(area, opts)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	workers := concurrency(opts.Concurrency, defaultAreaConcurrency, len(tiles))
+	return runPool(ctx, len(tiles), workers, func(ctx context.Context, i int) error {
+		items, err := query(ctx, tiles[i])
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, item := range items {
+			if pt, ok := point(item); ok && area.Contains(pt) {
+				add(item)
+			}
+		}
+		return nil
+	})
+}
+
+// tileCircle returns the center and radius of the circle circumscribing t,
+// as parameters for the Location radius endpoints.
+func tileCircle(t BBox) (lat, lon *float64, radius float64) {
+	return Ptr((t.South + t.North) / 2), Ptr((t.West + t.East) / 2), math.Ceil(tileRadius(t))
+}
+
+// tileArea splits the bounds of area into square tiles of opts.TileSize
+// meters and returns those that overlap area.
+func tileArea(area geo.Region, opts *AreaOptions) ([]BBox, error) {
+	if area == nil {
+		return nil, fmt.Errorf("vesselapi: area is nil")
+	}
+	if err := area.Validate(); err != nil {
+		return nil, fmt.Errorf("vesselapi: invalid area: %w", err)
+	}
+	size := opts.TileSize
+	if size <= 0 {
+		size = defaultAreaBoxTile
+		if opts.Shape == AreaTileCircle {
+			size = defaultAreaCircleTile
+		}
+	}
+
+	south, west, north, east := area.Bounds()
+	if north <= south || east <= west {
+		return nil, fmt.Errorf("vesselapi: area has no extent")
+	}
+
+	latStep := size / metersPerDegreeLat
+	rows := int(math.Ceil((north - south) / latStep))
+	var tiles []BBox
+	for i := 0; i < rows; i++ {
+		bottom := south + float64(i)*latStep
+		top := math.Min(bottom+latStep, north)
+
+		// Tiles are widest on the edge closest to the equator.
+		widest := math.Min(math.Abs(bottom), math.Abs(top))
+		if bottom < 0 && top > 0 {
+			widest = 0
+		}
+		lonStep := math.Min(size/(metersPerDegreeLat*math.Cos(widest*math.Pi/180)), 360)
+		cols := int(math.Ceil((east - west) / lonStep))
+		for j := 0; j < cols; j++ {
+			left := west + float64(j)*lonStep
+			t := BBox{West: left, South: bottom, East: math.Min(left+lonStep, east), North: top}
+			if !area.IntersectsRect(t.South, t.West, t.North, t.East) {
+				continue
+			}
+			if opts.Shape == AreaTileCircle && tileRadius(t) > MaxRadius {
+				return nil, fmt.Errorf("vesselapi: tile size %.0f m exceeds the maximum query radius", size)
+			}
+			tiles = append(tiles, t)
+		}
+	}
+	return tiles, nil
+}
+
+// tileRadius returns the radius in meters of the circle centred on t that
+// contains all of its corners.
+func tileRadius(t BBox) float64 {
+	center := geo.Point{Lat: (t.South + t.North) / 2, Lon: (t.West + t.East) / 2}
+	var r float64
+	for _, c := range t.Polygon() {
+		r = math.Max(r, geo.Distance(center, c))
+	}
+	return r
+}
+
+// concurrency returns n clamped to [1, limit], or def if n is not positive.
+func concurrency(n, def, limit int) int {
+	if n <= 0 {
+		n = def
+	}
+	if n > limit {
+		n = limit
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// positionSet deduplicates vessel positions, keeping the freshest report per
+// vessel.
+type positionSet struct {
+	byKey map[string]VesselPosition
+	other []VesselPosition
+}
+
+func newPositionSet() *positionSet {
+	return &positionSet{byKey: make(map[string]VesselPosition)}
+}
+
+// add records p unless a report for the same vessel with a later Timestamp
+// is already present. Positions without an MMSI or IMO are kept as-is.
+func (s *positionSet) add(p VesselPosition) {
+	key := positionKey(p)
+	if key == "" {
+		s.other = append(s.other, p)
+		return
+	}
+	prev, ok := s.byKey[key]
+	if !ok || newerPosition(p, prev) {
+		s.byKey[key] = p
+	}
+}
+
+// sorted returns the deduplicated positions ordered by MMSI, then IMO.
+func (s *positionSet) sorted() []VesselPosition {
+	out := make([]VesselPosition, 0, len(s.byKey)+len(s.other))
+	for _, p := range s.byKey {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if mi, mj := Deref(out[i].Mmsi), Deref(out[j].Mmsi); mi != mj {
+			return mi < mj
+		}
+		return Deref(out[i].Imo) < Deref(out[j].Imo)
+	})
+	return append(out, s.other...)
+}
+
+// positionKey identifies the vessel a position belongs to.
+func positionKey(p VesselPosition) string {
+	switch {
+	case p.Mmsi != nil:
+		return fmt.Sprintf("mmsi:%d", *p.Mmsi)
+	case p.Imo != nil:
+		return fmt.Sprintf("imo:%d", *p.Imo)
+	}
+	return ""
+}
+
+// newerPosition reports whether a has a later Timestamp than b. A position
+// with an unparseable or missing timestamp is never newer than one with a
+// valid timestamp.
+func newerPosition(a, b VesselPosition) bool {
+	ta, errA := parseTimestamp(Deref(a.Timestamp))
+	if errA != nil {
+		return false
+	}
+	tb, errB := parseTimestamp(Deref(b.Timestamp))
+	return errB != nil || ta.After(tb)
+}
+
+// areaSet deduplicates area query results by key.
+type areaSet[T any] struct {
+	key   func(T) string
+	byKey map[string]T
+}
+
+func newAreaSet[T any](key func(T) string) *areaSet[T] {
+	return &areaSet[T]{key: key, byKey: make(map[string]T)}
+}
+
+// add records item, replacing any earlier item with the same key.
+func (s *areaSet[T]) add(item T) {
+	s.byKey[s.key(item)] = item
+}
+
+// sorted returns the deduplicated items ordered by key.
+func (s *areaSet[T]) sorted() []T {
+	keys := make([]string, 0, len(s.byKey))
+	for k := range s.byKey {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]T, len(keys))
+	for i, k := range keys {
+		out[i] = s.byKey[k]
+	}
+	return out
+}
+
+// featureKey identifies a navigation feature by name and position.
+func featureKey(name *string, pt geo.Point) string {
+	return fmt.Sprintf("%s@%.5f,%.5f", Deref(name), pt.Lat, pt.Lon)
+}
+
+// portPoint returns the coordinates of p, preferring its latitude and
+// longitude fields over its GeoJSON location.
+func portPoint(p Port) (geo.Point, bool) {
+	if p.Latitude != nil && p.Longitude != nil {
+		return geo.Point{Lat: *p.Latitude, Lon: *p.Longitude}, true
+	}
+	return locationPoint(p.Location)
+}
+
+// locationPoint returns the coordinates of a GeoJSON Point location.
+func locationPoint(loc *GithubComVesselapiCommonVesselDataContractsTypesGeoJSON) (geo.Point, bool) {
+	if loc == nil || loc.Coordinates == nil || len(*loc.Coordinates) < 2 {
+		return geo.Point{}, false
+	}
+	c := *loc.Coordinates
+	return geo.Point{Lat: float32Coord(c[1]), Lon: float32Coord(c[0])}, true
+}
+
+// float32Coord widens a float32 coordinate to the float64 with the same
+// shortest decimal representation, so that 4.1 stays 4.1 rather than
+// becoming 4.099999904632568.
+func float32Coord(f float32) float64 {
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	return v
+}
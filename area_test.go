@@ -0,0 +1,281 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+func TestTileArea_CoversBounds(t *testing.T) {
+	// Roughly the North Sea.
+	area := geo.Rectangle(51, -4, 61, 9)
+	tiles, err := tileArea(area, &AreaOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tiles) < 50 {
+		t.Fatalf("expected the area to be split into many tiles, got %d", len(tiles))
+	}
+	for _, tile := range tiles {
+		sw := geo.Point{Lat: tile.South, Lon: tile.West}
+		se := geo.Point{Lat: tile.South, Lon: tile.East}
+		nw := geo.Point{Lat: tile.North, Lon: tile.West}
+		if w := geo.Distance(sw, se); w > defaultAreaBoxTile+1 {
+			t.Errorf("tile %+v is %.0f m wide", tile, w)
+		}
+		if h := geo.Distance(sw, nw); h > defaultAreaBoxTile+1 {
+			t.Errorf("tile %+v is %.0f m tall", tile, h)
+		}
+	}
+
+	// Every point of the area falls in some tile.
+	for lat := 51.05; lat < 61; lat += 0.5 {
+		for lon := -3.95; lon < 9; lon += 0.5 {
+			covered := false
+			for _, tile := range tiles {
+				if lat >= tile.South && lat <= tile.North && lon >= tile.West && lon <= tile.East {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				t.Fatalf("point %f,%f not covered", lat, lon)
+			}
+		}
+	}
+}
+
+func TestTileArea_SkipsTilesOutsidePolygon(t *testing.T) {
+	triangle := geo.Polygon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}, {Lat: 10, Lon: 0}}
+	box, err := tileArea(geo.Rectangle(0, 0, 10, 10), &AreaOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tri, err := tileArea(triangle, &AreaOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tri) >= len(box) {
+		t.Errorf("expected fewer tiles for triangle (%d) than box (%d)", len(tri), len(box))
+	}
+}
+
+func TestTileArea_CircleRadius(t *testing.T) {
+	tiles, err := tileArea(geo.Rectangle(50, 0, 52, 3), &AreaOptions{Shape: AreaTileCircle})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tile := range tiles {
+		if r := tileRadius(tile); r > MaxRadius {
+			t.Errorf("tile radius %.0f exceeds maximum", r)
+		}
+	}
+
+	if _, err := tileArea(geo.Rectangle(50, 0, 52, 3), &AreaOptions{Shape: AreaTileCircle, TileSize: 200_000}); err == nil {
+		t.Error("expected error for oversized circle tiles")
+	}
+}
+
+func TestTileArea_Invalid(t *testing.T) {
+	for name, area := range map[string]geo.Polygon{
+		"too few vertices": {{Lat: 0, Lon: 0}, {Lat: 1, Lon: 1}},
+		"out of range":     geo.Rectangle(0, 170, 10, 190),
+		"no extent":        {{Lat: 1, Lon: 1}, {Lat: 1, Lon: 1}, {Lat: 1, Lon: 1}},
+	} {
+		if _, err := tileArea(area, &AreaOptions{}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestVesselsInArea_DeduplicatesAndFilters(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/location/vessels/bounding-box" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		south, _ := strconv.ParseFloat(q.Get("filter.latBottom"), 64)
+		west, _ := strconv.ParseFloat(q.Get("filter.lonLeft"), 64)
+
+		// Every tile reports the same vessel with a timestamp that depends on
+		// the tile, plus one vessel outside the requested area.
+		ts := fmt.Sprintf("2025-01-01T%02d:%02d:00Z", int(south*10)%24, int(west*10)%60)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(VesselsWithinLocationResponse{Vessels: &[]VesselPosition{
+			{Mmsi: Ptr(111), Latitude: Ptr(0.5), Longitude: Ptr(0.5), Timestamp: Ptr(ts)},
+			{Mmsi: Ptr(222), Latitude: Ptr(0.5), Longitude: Ptr(0.5), Timestamp: Ptr("2025-01-01T00:00:00Z")},
+			{Mmsi: Ptr(333), Latitude: Ptr(50.0), Longitude: Ptr(50.0), Timestamp: Ptr("2025-01-01T00:00:00Z")},
+		}})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	area := geo.Rectangle(0, 0, 2, 2)
+	got, err := vc.Location.VesselsInArea(context.Background(), area, &AreaOptions{Concurrency: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tiles, _ := tileArea(area, &AreaOptions{})
+	if int(requests.Load()) != len(tiles) {
+		t.Errorf("expected %d requests, got %d", len(tiles), requests.Load())
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 deduplicated positions, got %d", len(got))
+	}
+	if Deref(got[0].Mmsi) != 111 || Deref(got[1].Mmsi) != 222 {
+		t.Errorf("expected MMSIs 111, 222 in order, got %d, %d", Deref(got[0].Mmsi), Deref(got[1].Mmsi))
+	}
+
+	// The freshest of all tile reports for MMSI 111 wins.
+	var freshest string
+	for _, tile := range tiles {
+		ts := fmt.Sprintf("2025-01-01T%02d:%02d:00Z", int(tile.South*10)%24, int(tile.West*10)%60)
+		if ts > freshest {
+			freshest = ts
+		}
+	}
+	if Deref(got[0].Timestamp) != freshest {
+		t.Errorf("expected freshest timestamp %s, got %s", freshest, Deref(got[0].Timestamp))
+	}
+}
+
+func TestVesselsInArea_CircleTiles(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/location/vessels/radius" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		radius, _ := strconv.ParseFloat(r.URL.Query().Get("filter.radius"), 64)
+		if radius <= 0 || radius > MaxRadius {
+			t.Errorf("radius %f out of range", radius)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(VesselsWithinLocationResponse{})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := vc.Location.VesselsInArea(context.Background(), geo.Rectangle(0, 0, 1, 1), &AreaOptions{Shape: AreaTileCircle})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no positions, got %d", len(got))
+	}
+}
+
+func TestVesselsInArea_ReturnsFirstError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"bad box"}}`)
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = vc.Location.VesselsInArea(context.Background(), geo.Rectangle(0, 0, 3, 3), nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 APIError, got %v", err)
+	}
+}
+
+func TestTileArea_Corridor(t *testing.T) {
+	// An L-shaped route; tiles inside the bend are skipped.
+	route := geo.Corridor{Line: []geo.Point{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 5}, {Lat: 5, Lon: 5}}, Buffer: 20_000}
+	tiles, err := tileArea(route, &AreaOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tile := range tiles {
+		if tile.South > 1 && tile.East < 4 {
+			t.Errorf("unexpected tile %+v far from the route", tile)
+		}
+	}
+	s, w, n, e := route.Bounds()
+	box, _ := tileArea(geo.Rectangle(s, w, n, e), &AreaOptions{})
+	if len(tiles) >= len(box) {
+		t.Errorf("expected fewer tiles for corridor (%d) than its bounds (%d)", len(tiles), len(box))
+	}
+
+	if _, err := tileArea(geo.Corridor{Line: route.Line}, &AreaOptions{}); err == nil {
+		t.Error("expected error for corridor without buffer")
+	}
+}
+
+func TestPortsInArea_Corridor(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/location/ports/bounding-box" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PortsWithinLocationResponse{Ports: &[]Port{
+			{UnloCode: Ptr("AAAAA"), Latitude: Ptr(0.05), Longitude: Ptr(0.5)},
+			{UnloCode: Ptr("BBBBB"), Location: &GithubComVesselapiCommonVesselDataContractsTypesGeoJSON{Coordinates: &[]float32{0.7, 0.01}}},
+			{UnloCode: Ptr("CCCCC"), Latitude: Ptr(0.5), Longitude: Ptr(0.5)},
+			{UnloCode: Ptr("DDDDD")},
+		}})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	route := geo.Corridor{Line: []geo.Point{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}}, Buffer: 10_000}
+	got, err := vc.Location.PortsInArea(context.Background(), route, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || Deref(got[0].UnloCode) != "AAAAA" || Deref(got[1].UnloCode) != "BBBBB" {
+		t.Errorf("expected ports AAAAA, BBBBB, got %+v", got)
+	}
+}
+
+func TestLightAidsInArea_PolygonWithHole(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(LightAidsWithinLocationResponse{LightAids: &[]LightAid{
+			{Name: Ptr("outer"), Location: &GithubComVesselapiCommonVesselDataContractsTypesGeoJSON{Coordinates: &[]float32{0.2, 0.2}}},
+			{Name: Ptr("hole"), Location: &GithubComVesselapiCommonVesselDataContractsTypesGeoJSON{Coordinates: &[]float32{0.5, 0.5}}},
+		}})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	area, err := geo.ParseGeoJSONPolygon([]byte(`{"type":"Polygon","coordinates":[
+		[[0,0],[1,0],[1,1],[0,1],[0,0]],
+		[[0.4,0.4],[0.6,0.4],[0.6,0.6],[0.4,0.6],[0.4,0.4]]
+	]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := vc.Location.LightAidsInArea(context.Background(), area, &AreaOptions{Shape: AreaTileCircle})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || Deref(got[0].Name) != "outer" {
+		t.Errorf("expected only the light aid outside the hole, got %+v", got)
+	}
+}
@@ -0,0 +1,296 @@
+package vesselapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// BBox is a latitude/longitude bounding box in decimal degrees. A box whose
+// West edge lies east of its East edge after normalization crosses the
+// antimeridian, for example {West: 170, East: -170} covering the Fiji area.
+type BBox struct {
+	West  float64
+	South float64
+	East  float64
+	North float64
+}
+
+// Validate reports whether b describes a usable box: all edges finite,
+// latitudes within [-90, 90] and South not above North. Longitudes outside
+// [-180, 180] are accepted and wrapped by Normalize.
+func (b BBox) Validate() error {
+	for _, v := range []float64{b.West, b.South, b.East, b.North} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("vesselapi: bounding box has non-finite edge")
+		}
+	}
+	if b.South < -90 || b.North > 90 {
+		return fmt.Errorf("vesselapi: bounding box latitude out of range [-90, 90]")
+	}
+	if b.South > b.North {
+		return fmt.Errorf("vesselapi: bounding box south %g is above north %g", b.South, b.North)
+	}
+	return nil
+}
+
+// Normalize wraps the longitudes of b into [-180, 180]. A box spanning 360
+// degrees or more of longitude becomes the full [-180, 180] range. East is
+// never normalized to -180, so {West: 0, East: 180} is preserved.
+func (b BBox) Normalize() BBox {
+	if b.East-b.West >= 360 {
+		b.West, b.East = -180, 180
+		return b
+	}
+	b.West = geo.NormalizeLon(b.West)
+	b.East = geo.NormalizeLon(b.East)
+	if b.East == -180 {
+		b.East = 180
+	}
+	return b
+}
+
+// CrossesAntimeridian reports whether the normalized box spans the 180°
+// meridian.
+func (b BBox) CrossesAntimeridian() bool {
+	n := b.Normalize()
+	return n.West > n.East
+}
+
+// Split validates and normalizes b, then returns it as one box, or as two
+// boxes meeting at the antimeridian if it crosses it. The returned boxes are
+// suitable for the Location bounding-box endpoints.
+func (b BBox) Split() ([]BBox, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	n := b.Normalize()
+	if n.West <= n.East {
+		return []BBox{n}, nil
+	}
+	return []BBox{
+		{West: n.West, South: n.South, East: 180, North: n.North},
+		{West: -180, South: n.South, East: n.East, North: n.North},
+	}, nil
+}
+
+// Contains reports whether p lies within b, including its edges.
+func (b BBox) Contains(p geo.Point) bool {
+	if p.Lat < b.South || p.Lat > b.North {
+		return false
+	}
+	n := b.Normalize()
+	lon := geo.NormalizeLon(p.Lon)
+	if n.West <= n.East {
+		return (lon >= n.West && lon <= n.East) || (n.East == 180 && lon == -180)
+	}
+	return lon >= n.West || lon <= n.East
+}
+
+// Polygon returns b as a geo.Polygon. The box must not cross the
+// antimeridian; use Split first if it might.
+func (b BBox) Polygon() geo.Polygon {
+	return geo.Rectangle(b.South, b.West, b.North, b.East)
+}
+
+// filters returns pointers to the box edges in the order the generated
+// bounding-box params declare them.
+func (b BBox) filters() (lonLeft, lonRight, latBottom, latTop *float64) {
+	return Ptr(b.West), Ptr(b.East), Ptr(b.South), Ptr(b.North)
+}
+
+// errIterator returns an iterator that yields no items and reports err.
+func errIterator[T any](err error) *Iterator[T] {
+	return newIterator(func() ([]T, *string, error) {
+		return nil, nil, err
+	})
+}
+
+// concatFetch returns a fetchFunc that drains each of fetches in turn,
+// skipping empty pages so the iterator does not stop early.
+func concatFetch[T any](fetches ...fetchFunc[T]) fetchFunc[T] {
+	more := "more"
+	return func() ([]T, *string, error) {
+		for len(fetches) > 0 {
+			items, next, err := fetches[0]()
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(items) == 0 || next == nil || *next == "" {
+				fetches = fetches[1:]
+			}
+			if len(items) == 0 {
+				continue
+			}
+			if len(fetches) == 0 {
+				return items, nil, nil
+			}
+			return items, &more, nil
+		}
+		return nil, nil, nil
+	}
+}
+
+// AllVesselsInBBox returns an iterator over all vessel positions in box. Boxes
+// crossing the antimeridian are queried as two requests whose results are
+// merged. The Filter* fields of params are overwritten by box.
+func (s *LocationService) AllVesselsInBBox(ctx context.Context, box BBox, params *GetLocationVesselsBoundingBoxParams) *Iterator[VesselPosition] {
+	parts, err := box.Split()
+	if err != nil {
+		return errIterator[VesselPosition](err)
+	}
+	if params == nil {
+		params = &GetLocationVesselsBoundingBoxParams{}
+	}
+	fetches := make([]fetchFunc[VesselPosition], len(parts))
+	for i, part := range parts {
+		p := *params
+		p.FilterLonLeft, p.FilterLonRight, p.FilterLatBottom, p.FilterLatTop = part.filters()
+		fetches[i] = func() ([]VesselPosition, *string, error) {
+			resp, err := s.VesselsBoundingBox(ctx, &p)
+			if err != nil {
+				return nil, nil, err
+			}
+			p.PaginationNextToken = resp.NextToken
+			return derefSlice(resp.Vessels), resp.NextToken, nil
+		}
+	}
+	return newIterator(concatFetch(fetches...))
+}
+
+// AllPortsInBBox returns an iterator over all ports in box, splitting boxes
+// that cross the antimeridian. The Filter* fields of params are overwritten
+// by box.
+func (s *LocationService) AllPortsInBBox(ctx context.Context, box BBox, params *GetLocationPortsBoundingBoxParams) *Iterator[Port] {
+	parts, err := box.Split()
+	if err != nil {
+		return errIterator[Port](err)
+	}
+	if params == nil {
+		params = &GetLocationPortsBoundingBoxParams{}
+	}
+	fetches := make([]fetchFunc[Port], len(parts))
+	for i, part := range parts {
+		p := *params
+		p.FilterLonLeft, p.FilterLonRight, p.FilterLatBottom, p.FilterLatTop = part.filters()
+		fetches[i] = func() ([]Port, *string, error) {
+			resp, err := s.PortsBoundingBox(ctx, &p)
+			if err != nil {
+				return nil, nil, err
+			}
+			p.PaginationNextToken = resp.NextToken
+			return derefSlice(resp.Ports), resp.NextToken, nil
+		}
+	}
+	return newIterator(concatFetch(fetches...))
+}
+
+// AllDGPSInBBox returns an iterator over all DGPS stations in box, splitting
+// boxes that cross the antimeridian. The Filter* fields of params are
+// overwritten by box.
+func (s *LocationService) AllDGPSInBBox(ctx context.Context, box BBox, params *GetLocationDgpsBoundingBoxParams) *Iterator[DGPSStation] {
+	parts, err := box.Split()
+	if err != nil {
+		return errIterator[DGPSStation](err)
+	}
+	if params == nil {
+		params = &GetLocationDgpsBoundingBoxParams{}
+	}
+	fetches := make([]fetchFunc[DGPSStation], len(parts))
+	for i, part := range parts {
+		p := *params
+		p.FilterLonLeft, p.FilterLonRight, p.FilterLatBottom, p.FilterLatTop = part.filters()
+		fetches[i] = func() ([]DGPSStation, *string, error) {
+			resp, err := s.DGPSBoundingBox(ctx, &p)
+			if err != nil {
+				return nil, nil, err
+			}
+			p.PaginationNextToken = resp.NextToken
+			return derefSlice(resp.DgpsStations), resp.NextToken, nil
+		}
+	}
+	return newIterator(concatFetch(fetches...))
+}
+
+// AllLightAidsInBBox returns an iterator over all light aids in box, splitting
+// boxes that cross the antimeridian. The Filter* fields of params are
+// overwritten by box.
+func (s *LocationService) AllLightAidsInBBox(ctx context.Context, box BBox, params *GetLocationLightaidsBoundingBoxParams) *Iterator[LightAid] {
+	parts, err := box.Split()
+	if err != nil {
+		return errIterator[LightAid](err)
+	}
+	if params == nil {
+		params = &GetLocationLightaidsBoundingBoxParams{}
+	}
+	fetches := make([]fetchFunc[LightAid], len(parts))
+	for i, part := range parts {
+		p := *params
+		p.FilterLonLeft, p.FilterLonRight, p.FilterLatBottom, p.FilterLatTop = part.filters()
+		fetches[i] = func() ([]LightAid, *string, error) {
+			resp, err := s.LightAidsBoundingBox(ctx, &p)
+			if err != nil {
+				return nil, nil, err
+			}
+			p.PaginationNextToken = resp.NextToken
+			return derefSlice(resp.LightAids), resp.NextToken, nil
+		}
+	}
+	return newIterator(concatFetch(fetches...))
+}
+
+// AllMODUsInBBox returns an iterator over all MODUs in box, splitting boxes
+// that cross the antimeridian. The Filter* fields of params are overwritten
+// by box.
+func (s *LocationService) AllMODUsInBBox(ctx context.Context, box BBox, params *GetLocationModuBoundingBoxParams) *Iterator[MODU] {
+	parts, err := box.Split()
+	if err != nil {
+		return errIterator[MODU](err)
+	}
+	if params == nil {
+		params = &GetLocationModuBoundingBoxParams{}
+	}
+	fetches := make([]fetchFunc[MODU], len(parts))
+	for i, part := range parts {
+		p := *params
+		p.FilterLonLeft, p.FilterLonRight, p.FilterLatBottom, p.FilterLatTop = part.filters()
+		fetches[i] = func() ([]MODU, *string, error) {
+			resp, err := s.MODUsBoundingBox(ctx, &p)
+			if err != nil {
+				return nil, nil, err
+			}
+			p.PaginationNextToken = resp.NextToken
+			return derefSlice(resp.Modus), resp.NextToken, nil
+		}
+	}
+	return newIterator(concatFetch(fetches...))
+}
+
+// AllRadioBeaconsInBBox returns an iterator over all radio beacons in box,
+// splitting boxes that cross the antimeridian. The Filter* fields of params
+// are overwritten by box.
+func (s *LocationService) AllRadioBeaconsInBBox(ctx context.Context, box BBox, params *GetLocationRadiobeaconsBoundingBoxParams) *Iterator[RadioBeacon] {
+	parts, err := box.Split()
+	if err != nil {
+		return errIterator[RadioBeacon](err)
+	}
+	if params == nil {
+		params = &GetLocationRadiobeaconsBoundingBoxParams{}
+	}
+	fetches := make([]fetchFunc[RadioBeacon], len(parts))
+	for i, part := range parts {
+		p := *params
+		p.FilterLonLeft, p.FilterLonRight, p.FilterLatBottom, p.FilterLatTop = part.filters()
+		fetches[i] = func() ([]RadioBeacon, *string, error) {
+			resp, err := s.RadioBeaconsBoundingBox(ctx, &p)
+			if err != nil {
+				return nil, nil, err
+			}
+			p.PaginationNextToken = resp.NextToken
+			return derefSlice(resp.RadioBeacons), resp.NextToken, nil
+		}
+	}
+	return newIterator(concatFetch(fetches...))
+}
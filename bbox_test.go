@@ -0,0 +1,173 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+func TestBBox_Validate(t *testing.T) {
+	valid := []BBox{
+		{West: -10, South: -5, East: 10, North: 5},
+		{West: 170, South: -20, East: -170, North: -10},
+		{West: 190, South: 0, East: 200, North: 0},
+	}
+	for _, b := range valid {
+		if err := b.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error: %v", b, err)
+		}
+	}
+	invalid := []BBox{
+		{West: 0, South: 10, East: 1, North: 5},
+		{West: 0, South: -91, East: 1, North: 0},
+		{West: 0, South: 0, East: 1, North: 91},
+		{West: math.NaN(), South: 0, East: 1, North: 1},
+		{West: 0, South: 0, East: math.Inf(1), North: 1},
+	}
+	for _, b := range invalid {
+		if err := b.Validate(); err == nil {
+			t.Errorf("%+v: expected error", b)
+		}
+	}
+}
+
+func TestBBox_Normalize(t *testing.T) {
+	tests := []struct {
+		in, want BBox
+	}{
+		{BBox{West: -10, East: 10}, BBox{West: -10, East: 10}},
+		{BBox{West: 170, East: 190}, BBox{West: 170, East: -170}},
+		{BBox{West: 0, East: 180}, BBox{West: 0, East: 180}},
+		{BBox{West: -190, East: -170}, BBox{West: 170, East: -170}},
+		{BBox{West: -200, East: 200}, BBox{West: -180, East: 180}},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Normalize(); got != tt.want {
+			t.Errorf("Normalize(%+v) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBBox_Split(t *testing.T) {
+	parts, err := BBox{West: -10, South: 0, East: 10, North: 5}.Split()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parts) != 1 {
+		t.Fatalf("expected 1 part, got %d", len(parts))
+	}
+
+	// Bering Sea: 160°E to 160°W.
+	bering := BBox{West: 160, South: 52, East: -160, North: 66}
+	if !bering.CrossesAntimeridian() {
+		t.Fatal("expected box to cross the antimeridian")
+	}
+	parts, err = bering.Split()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []BBox{
+		{West: 160, South: 52, East: 180, North: 66},
+		{West: -180, South: 52, East: -160, North: 66},
+	}
+	if len(parts) != 2 || parts[0] != want[0] || parts[1] != want[1] {
+		t.Errorf("unexpected split %+v", parts)
+	}
+
+	if _, err := (BBox{South: 10, North: 0}).Split(); err == nil {
+		t.Error("expected validation error from Split")
+	}
+}
+
+func TestBBox_Contains(t *testing.T) {
+	fiji := BBox{West: 175, South: -20, East: -178, North: -15}
+	for _, p := range []geo.Point{{Lat: -17, Lon: 178}, {Lat: -17, Lon: -179}, {Lat: -17, Lon: 180}} {
+		if !fiji.Contains(p) {
+			t.Errorf("expected %+v inside", p)
+		}
+	}
+	for _, p := range []geo.Point{{Lat: -17, Lon: 0}, {Lat: -17, Lon: 170}, {Lat: -10, Lon: 178}} {
+		if fiji.Contains(p) {
+			t.Errorf("expected %+v outside", p)
+		}
+	}
+	if !(BBox{West: 170, South: 0, East: 180, North: 1}).Contains(geo.Point{Lat: 0.5, Lon: -180}) {
+		t.Error("expected -180 to match an east edge of 180")
+	}
+}
+
+func TestConcatFetch_SkipsEmptyPages(t *testing.T) {
+	tok := "next"
+	pages := map[string][][]int{"a": {{}}, "b": {{1, 2}, {3}}, "c": {{}}, "d": {{4}}}
+	fetch := func(name string) fetchFunc[int] {
+		i := 0
+		return func() ([]int, *string, error) {
+			page := pages[name][i]
+			i++
+			if i < len(pages[name]) {
+				return page, &tok, nil
+			}
+			return page, nil, nil
+		}
+	}
+	got, err := newIterator(concatFetch(fetch("a"), fetch("b"), fetch("c"), fetch("d"))).Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 4 || got[0] != 1 || got[3] != 4 {
+		t.Errorf("unexpected items %v", got)
+	}
+}
+
+func TestAllVesselsInBBox_MergesAntimeridianSplit(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		q := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		switch q.Get("filter.lonLeft") + "/" + q.Get("filter.lonRight") {
+		case "170/180":
+			json.NewEncoder(w).Encode(VesselsWithinLocationResponse{Vessels: &[]VesselPosition{{Mmsi: Ptr(1)}}})
+		case "-180/-170":
+			json.NewEncoder(w).Encode(VesselsWithinLocationResponse{Vessels: &[]VesselPosition{{Mmsi: Ptr(2)}}})
+		default:
+			t.Errorf("unexpected box %s", r.URL.RawQuery)
+		}
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := vc.Location.AllVesselsInBBox(context.Background(), BBox{West: 170, South: -20, East: 190, North: -10}, nil).Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || Deref(got[0].Mmsi) != 1 || Deref(got[1].Mmsi) != 2 {
+		t.Errorf("unexpected result %+v", got)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestAllPortsInBBox_InvalidBox(t *testing.T) {
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL("http://127.0.0.1:0"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	it := vc.Location.AllPortsInBBox(context.Background(), BBox{South: 5, North: 0}, nil)
+	if it.Next() {
+		t.Error("expected no items")
+	}
+	if it.Err() == nil {
+		t.Error("expected validation error")
+	}
+}
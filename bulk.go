@@ -0,0 +1,247 @@
+package vesselapi
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBulkConcurrency is the default number of items processed at
+	// once.
+	defaultBulkConcurrency = 4
+
+	// defaultBulkAttempts is the default number of attempts per item.
+	defaultBulkAttempts = 3
+
+	// defaultBulkBackoff is the default delay before the first retry.
+	defaultBulkBackoff = time.Second
+
+	// bulkOrderedWindow is how many items per worker may be started ahead
+	// of the oldest undelivered item in ordered mode.
+	bulkOrderedWindow = 4
+)
+
+// BulkOptions configures Bulk. A nil *BulkOptions uses the defaults.
+type BulkOptions struct {
+	// Concurrency is the number of items processed at once. Defaults to 4.
+	// Use WithVesselRateLimit on the client to bound the request rate.
+	Concurrency int
+
+	// MaxAttempts is the number of times an item is tried before its error
+	// is reported. Only rate-limit, server and network errors are retried.
+	// A VesselClient already retries these errors for every request, so
+	// when fn uses one, either create it with WithVesselRetry(0) to leave
+	// retries to Bulk, or set MaxAttempts to 1. Defaults to 3.
+	MaxAttempts int
+
+	// RetryBackoff is the delay before the first retry of an item; it
+	// doubles with each further attempt, with jitter. Defaults to 1s.
+	RetryBackoff time.Duration
+
+	// Ordered emits results in input order instead of completion order.
+	// Results that complete ahead of a slower earlier item are held back
+	// until it finishes. At most 4 × Concurrency items are started ahead of
+	// the oldest undelivered one, so a slow item stalls the pool instead of
+	// letting held results pile up in memory.
+	Ordered bool
+}
+
+// BulkResult is the outcome of processing one input item.
+type BulkResult[In, Out any] struct {
+	// Index is the position of the item in the input stream.
+	Index int
+
+	Input In
+	Value Out
+	Err   error
+
+	// Attempts is the number of times the item was tried.
+	Attempts int
+}
+
+// Bulk processes every item received from in with fn on a pool of workers
+// and sends the results on the returned channel, which is closed once in is
+// closed and every item has been processed.
+//
+// Items whose error is retryable (rate limited, server error or network
+// failure) are retried with backoff; a rate-limit error also pauses every
+// worker for the backoff period so the pool as a whole backs off. These
+// retries come on top of any made by the client fn uses; see
+// BulkOptions.MaxAttempts.
+//
+// When ctx is cancelled no further items are read, and results not yet
+// delivered are dropped. The caller must drain the returned channel.
+func Bulk[In, Out any](ctx context.Context, in <-chan In, fn func(context.Context, In) (Out, error), opts *BulkOptions) <-chan BulkResult[In, Out] {
+	if opts == nil {
+		opts = &BulkOptions{}
+	}
+	workers := concurrency(opts.Concurrency, defaultBulkConcurrency, math.MaxInt)
+	attempts := opts.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultBulkAttempts
+	}
+	backoff := opts.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultBulkBackoff
+	}
+
+	type job struct {
+		index int
+		input In
+	}
+	// In ordered mode each item takes a slot until its result is
+	// delivered, which bounds the results held back.
+	var slots chan struct{}
+	if opts.Ordered {
+		slots = make(chan struct{}, workers*bulkOrderedWindow)
+	}
+	jobs := make(chan job)
+	go func() {
+		defer close(jobs)
+		for i := 0; ; i++ {
+			if slots != nil {
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case jobs <- job{i, v}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	cooldown := newRateLimiter(0)
+	done := make(chan BulkResult[In, Out])
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				r := BulkResult[In, Out]{Index: j.index, Input: j.input}
+				r.Value, r.Attempts, r.Err = runBulkItem(ctx, j.input, fn, attempts, backoff, cooldown)
+				done <- r
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	out := make(chan BulkResult[In, Out])
+	go func() {
+		defer close(out)
+		send := func(r BulkResult[In, Out]) {
+			select {
+			case out <- r:
+			case <-ctx.Done():
+			}
+		}
+		if !opts.Ordered {
+			for r := range done {
+				send(r)
+			}
+			return
+		}
+		pending := make(map[int]BulkResult[In, Out])
+		next := 0
+		for r := range done {
+			pending[r.Index] = r
+			for {
+				p, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				<-slots
+				send(p)
+			}
+		}
+	}()
+	return out
+}
+
+// runBulkItem calls fn for input until it succeeds, fails with an error
+// that is not retryable, or runs out of attempts.
+func runBulkItem[In, Out any](ctx context.Context, input In, fn func(context.Context, In) (Out, error), attempts int, backoff time.Duration, cooldown *rateLimiter) (Out, int, error) {
+	for attempt := 1; ; attempt++ {
+		if err := cooldown.wait(ctx); err != nil {
+			var zero Out
+			return zero, attempt - 1, err
+		}
+		v, err := fn(ctx, input)
+		if err == nil || attempt >= attempts || !isRetryableErr(err) || ctx.Err() != nil {
+			return v, attempt, err
+		}
+		d := bulkBackoff(backoff, attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.IsRateLimited() {
+			cooldown.pause(d)
+		}
+		if err := sleepCtx(ctx, d); err != nil {
+			return v, attempt, err
+		}
+	}
+}
+
+// isRetryableErr reports whether err is a rate-limit or server error
+// response, or a transient network failure.
+func isRetryableErr(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryable(apiErr.StatusCode)
+	}
+	return isTemporaryErr(err)
+}
+
+// bulkBackoff returns base doubled for each attempt after the first, plus up
+// to 50% jitter, capped at maxBackoff.
+func bulkBackoff(base time.Duration, attempt int) time.Duration {
+	d := float64(base) * math.Pow(2, float64(attempt-1))
+	d += rand.Float64() * d / 2 //nolint:gosec
+	return time.Duration(math.Min(d, float64(maxBackoff)))
+}
+
+// enrichSections are the profile sections Enrich fetches by default.
+var enrichSections = []ProfileSection{ProfileDetails, ProfilePosition, ProfileLastPortEvent}
+
+// Enrich builds a VesselProfile for every IMO number received from ids using
+// Bulk. By default each profile holds the vessel details, last position and
+// last port event; pass sections to choose others.
+//
+// Each section request is retried by the client as configured with
+// WithVesselRetry, so Enrich does not retry whole profiles: opts.MaxAttempts
+// and opts.RetryBackoff are ignored. Sections that fail, such as a vessel
+// with no recorded position, are left in the profile's Errors; the item
+// fails only if every section failed.
+func (s *VesselsService) Enrich(ctx context.Context, ids <-chan string, opts *BulkOptions, sections ...ProfileSection) <-chan BulkResult[string, *VesselProfile] {
+	if len(sections) == 0 {
+		sections = enrichSections
+	}
+	o := BulkOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.MaxAttempts = 1
+	return Bulk(ctx, ids, func(ctx context.Context, id string) (*VesselProfile, error) {
+		return s.Profile(ctx, id, sections...)
+	}, &o)
+}
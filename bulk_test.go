@@ -0,0 +1,274 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func feed[T any](items ...T) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for _, v := range items {
+			ch <- v
+		}
+	}()
+	return ch
+}
+
+func TestBulk_OrderedOutput(t *testing.T) {
+	// Earlier items take longer, so completion order is reversed.
+	fn := func(ctx context.Context, n int) (int, error) {
+		time.Sleep(time.Duration(10-n) * 2 * time.Millisecond)
+		return n * n, nil
+	}
+	var got []int
+	for r := range Bulk(context.Background(), feed(0, 1, 2, 3, 4, 5, 6, 7, 8, 9), fn, &BulkOptions{Concurrency: 5, Ordered: true}) {
+		if r.Err != nil || r.Value != r.Input*r.Input || r.Index != r.Input {
+			t.Errorf("unexpected result %+v", r)
+		}
+		got = append(got, r.Input)
+	}
+	if fmt.Sprint(got) != "[0 1 2 3 4 5 6 7 8 9]" {
+		t.Errorf("expected input order, got %v", got)
+	}
+}
+
+func TestBulk_OrderedWindow(t *testing.T) {
+	release := make(chan struct{})
+	var started atomic.Int32
+	fn := func(ctx context.Context, n int) (int, error) {
+		started.Add(1)
+		if n == 0 {
+			<-release
+		}
+		return n, nil
+	}
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+	results := Bulk(context.Background(), feed(items...), fn, &BulkOptions{Concurrency: 2, Ordered: true})
+
+	// While item 0 is stuck, only the window of 4 × Concurrency items is
+	// started.
+	deadline := time.Now().Add(2 * time.Second)
+	for started.Load() < 8 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := started.Load(); n != 8 {
+		t.Errorf("expected 8 items started, got %d", n)
+	}
+
+	close(release)
+	next := 0
+	for r := range results {
+		if r.Input != next {
+			t.Fatalf("expected item %d, got %d", next, r.Input)
+		}
+		next++
+	}
+	if next != len(items) {
+		t.Errorf("expected %d results, got %d", len(items), next)
+	}
+}
+
+func TestBulk_CompletionOrder(t *testing.T) {
+	release := make(chan struct{})
+	fn := func(ctx context.Context, n int) (int, error) {
+		if n == 0 {
+			<-release
+		}
+		return n, nil
+	}
+	results := Bulk(context.Background(), feed(0, 1), fn, &BulkOptions{Concurrency: 2})
+	if r := <-results; r.Input != 1 {
+		t.Errorf("expected item 1 first, got %d", r.Input)
+	}
+	close(release)
+	if r := <-results; r.Input != 0 {
+		t.Errorf("expected item 0 second, got %d", r.Input)
+	}
+	if _, ok := <-results; ok {
+		t.Error("expected channel to be closed")
+	}
+}
+
+func TestBulk_Retries(t *testing.T) {
+	var calls sync.Map
+	fn := func(ctx context.Context, id string) (string, error) {
+		n, _ := calls.LoadOrStore(id, new(atomic.Int32))
+		attempt := n.(*atomic.Int32).Add(1)
+		switch {
+		case id == "flaky" && attempt < 3:
+			return "", &APIError{StatusCode: http.StatusServiceUnavailable}
+		case id == "throttled" && attempt < 2:
+			return "", &APIError{StatusCode: http.StatusTooManyRequests}
+		case id == "missing":
+			return "", &APIError{StatusCode: http.StatusNotFound}
+		case id == "down":
+			return "", &APIError{StatusCode: http.StatusBadGateway}
+		}
+		return "ok:" + id, nil
+	}
+	got := map[string]BulkResult[string, string]{}
+	for r := range Bulk(context.Background(), feed("flaky", "throttled", "missing", "down", "fine"), fn, &BulkOptions{RetryBackoff: time.Millisecond}) {
+		got[r.Input] = r
+	}
+	tests := []struct {
+		id       string
+		attempts int
+		failed   bool
+	}{
+		{"flaky", 3, false},
+		{"throttled", 2, false},
+		{"missing", 1, true},
+		{"down", 3, true},
+		{"fine", 1, false},
+	}
+	for _, tt := range tests {
+		r := got[tt.id]
+		if r.Attempts != tt.attempts {
+			t.Errorf("%s: expected %d attempts, got %d", tt.id, tt.attempts, r.Attempts)
+		}
+		if (r.Err != nil) != tt.failed {
+			t.Errorf("%s: unexpected error %v", tt.id, r.Err)
+		}
+	}
+}
+
+func TestBulk_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	results := Bulk(ctx, in, func(ctx context.Context, n int) (int, error) { return n, nil }, nil)
+	in <- 1
+	<-results
+	cancel()
+	for range results {
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(200)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected 5 events at 200/s to take at least 20ms, took %s", elapsed)
+	}
+
+	l.pause(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded while paused, got %v", err)
+	}
+}
+
+func TestWithVesselRateLimit(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0), WithVesselRateLimit(100))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vc.Ports.Get(context.Background(), "NLRTM") //nolint:errcheck
+		}()
+	}
+	wg.Wait()
+	if len(times) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(times))
+	}
+	if span := times[3].Sub(times[0]); span < 25*time.Millisecond {
+		t.Errorf("expected requests spread over at least 25ms, got %s", span)
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	var positionCalls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		id := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		switch {
+		case id[0] == "vessel" && len(id) == 2:
+			json.NewEncoder(w).Encode(VesselResponse{Vessel: &Vessel{Name: Ptr("V" + id[1])}})
+		case id[0] == "vessel" && id[2] == "position":
+			// The first position request is throttled.
+			if positionCalls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprint(w, `{"error":{"message":"slow down"}}`)
+				return
+			}
+			json.NewEncoder(w).Encode(VesselPositionResponse{VesselPosition: &VesselPosition{}})
+		case id[0] == "portevents":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"message":"no events"}}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []BulkResult[string, *VesselProfile]
+	for r := range vc.Vessels.Enrich(context.Background(), feed("1", "2", "3"), &BulkOptions{Ordered: true, MaxAttempts: 3}) {
+		got = append(got, r)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(got))
+	}
+	for i, r := range got {
+		if r.Err != nil {
+			t.Errorf("%s: unexpected error %v", r.Input, r.Err)
+			continue
+		}
+		if r.Input != fmt.Sprint(i+1) || Deref(r.Value.Vessel.Name) != "V"+r.Input {
+			t.Errorf("unexpected result %+v", r)
+		}
+		if r.Value.Position == nil {
+			t.Errorf("%s: expected position after retry", r.Input)
+		}
+		if _, ok := r.Value.Errors[ProfileLastPortEvent]; !ok {
+			t.Errorf("%s: expected permanent port event error to be kept", r.Input)
+		}
+		if r.Attempts != 1 {
+			t.Errorf("%s: expected the client to retry the request rather than Enrich the item, got %d attempts", r.Input, r.Attempts)
+		}
+	}
+	if n := positionCalls.Load(); n != 4 {
+		t.Errorf("expected only the throttled position request to be repeated, got %d position requests", n)
+	}
+}
@@ -50,6 +50,7 @@ type clientConfig struct {
 	httpClient *http.Client
 	userAgent  string
 	maxRetries int
+	rateLimit  float64
 }
 
 // WithVesselBaseURL sets the API base URL. Defaults to DefaultBaseURL.
@@ -83,6 +84,15 @@ func WithVesselRetry(maxRetries int) VesselClientOption {
 	}
 }
 
+// WithVesselRateLimit limits the client to requestsPerSecond requests per
+// second across all goroutines, counting retries. Requests over the limit
+// wait rather than fail. Defaults to no limit.
+func WithVesselRateLimit(requestsPerSecond float64) VesselClientOption {
+	return func(c *clientConfig) {
+		c.rateLimit = requestsPerSecond
+	}
+}
+
 // NewVesselClient creates a new high-level Vessel API client.
 // The apiKey is used as a Bearer token for authentication.
 func NewVesselClient(apiKey string, opts ...VesselClientOption) (*VesselClient, error) {
@@ -107,12 +117,16 @@ func NewVesselClient(apiKey string, opts ...VesselClientOption) (*VesselClient,
 		base = cfg.httpClient.Transport
 	}
 
+	var inner http.RoundTripper = &authTransport{
+		base:      base,
+		apiKey:    apiKey,
+		userAgent: cfg.userAgent,
+	}
+	if cfg.rateLimit > 0 {
+		inner = &rateLimitTransport{base: inner, limiter: newRateLimiter(cfg.rateLimit)}
+	}
 	transport := &retryTransport{
-		base: &authTransport{
-			base:      base,
-			apiKey:    apiKey,
-			userAgent: cfg.userAgent,
-		},
+		base:       inner,
 		maxRetries: cfg.maxRetries,
 	}
 
@@ -0,0 +1,245 @@
+package vesselapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VolatileFields are fields that change on every fetch without the record
+// itself changing. Pass them as DiffOptions.Ignore to compare content only.
+var VolatileFields = []string{"CollectedAt", "CachedAt"}
+
+// FieldChange is one difference found by Diff.
+type FieldChange struct {
+	// Path locates the field, as JSON field names joined with "." and
+	// slice indexes or map keys in brackets, such as "former_names[1].name".
+	Path string
+
+	// Old and New are the field's values with pointers dereferenced, or nil
+	// where the field is absent.
+	Old any
+	New any
+}
+
+// String formats the change as `path: old -> new`.
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Path, diffText(c.Old), diffText(c.New))
+}
+
+func diffText(v any) string {
+	if v == nil {
+		return "<nil>"
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
+
+// DiffOptions configures Diff. A nil *DiffOptions uses the defaults.
+type DiffOptions struct {
+	// Ignore lists fields to skip. An entry containing no "." or "[" is a
+	// field name and matches the Go or JSON name of a field at any depth,
+	// so "CollectedAt" skips every CollectedAt field; other entries are
+	// paths matched from the root, such as "owner.name". Defaults to
+	// ignoring nothing; see VolatileFields.
+	Ignore []string
+}
+
+// Diff compares two values of any API model and returns the fields that
+// differ, in field order. It follows pointers, nested structs, slices and
+// maps: slices are compared element by element, and a nil pointer or
+// missing element is compared as absent, so a struct that appears reports
+// each of its set fields with a nil Old. A nil and an empty slice are equal.
+func Diff[T any](a, b T, opts *DiffOptions) []FieldChange {
+	o := DiffOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	d := differ{names: make(map[string]bool), paths: make(map[string]bool)}
+	for _, ig := range o.Ignore {
+		if strings.ContainsAny(ig, ".[") {
+			d.paths[ig] = true
+		} else {
+			d.names[ig] = true
+		}
+	}
+	d.walk("", reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem(), 0)
+	return d.changes
+}
+
+// differ accumulates the changes found by Diff.
+type differ struct {
+	names   map[string]bool
+	paths   map[string]bool
+	changes []FieldChange
+}
+
+// maxDiffDepth bounds how deeply Diff follows values, so that cyclic
+// values terminate.
+const maxDiffDepth = 32
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// walk compares a and b at path. An invalid Value is absent.
+func (d *differ) walk(path string, a, b reflect.Value, depth int) {
+	a, b = diffDeref(a), diffDeref(b)
+	if !a.IsValid() && !b.IsValid() {
+		return
+	}
+	if d.paths[path] {
+		return
+	}
+	kind := a.Kind()
+	if !a.IsValid() {
+		kind = b.Kind()
+	}
+	if (a.IsValid() && b.IsValid() && a.Type() != b.Type()) || depth >= maxDiffDepth {
+		d.leaf(path, a, b)
+		return
+	}
+	switch {
+	case kind == reflect.Struct && diffType(a, b) != timeType:
+		t := diffType(a, b)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := jsonName(f)
+			if name == "" || d.names[f.Name] || d.names[name] {
+				continue
+			}
+			d.walk(joinPath(path, name, f.Anonymous), diffField(a, i), diffField(b, i), depth+1)
+		}
+	case kind == reflect.Slice || kind == reflect.Array:
+		n := max(diffLen(a), diffLen(b))
+		for i := 0; i < n; i++ {
+			d.walk(fmt.Sprintf("%s[%d]", path, i), diffIndex(a, i), diffIndex(b, i), depth+1)
+		}
+	case kind == reflect.Map:
+		for _, k := range diffKeys(a, b) {
+			d.walk(fmt.Sprintf("%s[%v]", path, k.Interface()), diffMapIndex(a, k), diffMapIndex(b, k), depth+1)
+		}
+	default:
+		d.leaf(path, a, b)
+	}
+}
+
+// leaf records a change if the scalar values a and b differ.
+func (d *differ) leaf(path string, a, b reflect.Value) {
+	var av, bv any
+	if a.IsValid() {
+		av = a.Interface()
+	}
+	if b.IsValid() {
+		bv = b.Interface()
+	}
+	if at, ok := av.(time.Time); ok {
+		if bt, ok := bv.(time.Time); ok && at.Equal(bt) {
+			return
+		}
+	}
+	if reflect.DeepEqual(av, bv) {
+		return
+	}
+	d.changes = append(d.changes, FieldChange{Path: path, Old: av, New: bv})
+}
+
+// diffDeref follows pointers and interfaces, returning an invalid Value
+// for nil.
+func diffDeref(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func diffType(a, b reflect.Value) reflect.Type {
+	if a.IsValid() {
+		return a.Type()
+	}
+	return b.Type()
+}
+
+func diffField(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	return v.Field(i)
+}
+
+func diffLen(v reflect.Value) int {
+	if !v.IsValid() {
+		return 0
+	}
+	return v.Len()
+}
+
+func diffIndex(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() || i >= v.Len() {
+		return reflect.Value{}
+	}
+	return v.Index(i)
+}
+
+func diffMapIndex(v, k reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	return v.MapIndex(k)
+}
+
+// diffKeys returns the keys of both maps, sorted by their text.
+func diffKeys(a, b reflect.Value) []reflect.Value {
+	seen := make(map[string]reflect.Value)
+	for _, m := range []reflect.Value{a, b} {
+		if !m.IsValid() {
+			continue
+		}
+		for _, k := range m.MapKeys() {
+			seen[fmt.Sprint(k.Interface())] = k
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	keys := make([]reflect.Value, len(names))
+	for i, n := range names {
+		keys[i] = seen[n]
+	}
+	return keys
+}
+
+// jsonName returns the JSON name of f, its Go name if it has none, or ""
+// if it is excluded from JSON.
+func jsonName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// joinPath appends a field name to path. Embedded structs add no name.
+func joinPath(path, name string, embedded bool) string {
+	switch {
+	case embedded:
+		return path
+	case path == "":
+		return name
+	default:
+		return path + "." + name
+	}
+}
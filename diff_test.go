@@ -0,0 +1,82 @@
+package vesselapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiff_Vessel(t *testing.T) {
+	type formerName = GithubComVesselapiCommonVesselDataContractsTypesVesselFormerName
+	a := Vessel{
+		Name:        Ptr("EVER GIVEN"),
+		CountryCode: Ptr("PA"),
+		Length:      Ptr(400),
+		FormerNames: &[]formerName{{Name: Ptr("A"), YearUntil: Ptr("2010")}},
+	}
+	b := a
+	b.CountryCode = Ptr("LR")
+	b.Length = nil
+	b.Builder = Ptr("Imabari")
+	b.FormerNames = &[]formerName{{Name: Ptr("A"), YearUntil: Ptr("2011")}, {Name: Ptr("EVER GIVEN")}}
+
+	got := Diff(a, b, nil)
+	want := []string{
+		`builder: <nil> -> "Imabari"`,
+		`country_code: "PA" -> "LR"`,
+		`former_names[0].year_until: "2010" -> "2011"`,
+		`former_names[1].name: <nil> -> "EVER GIVEN"`,
+		`length: 400 -> <nil>`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d changes, got %v", len(want), got)
+	}
+	for i, c := range got {
+		if c.String() != want[i] {
+			t.Errorf("change %d: expected %s, got %s", i, want[i], c)
+		}
+	}
+	if c := got[1]; c.Old != "PA" || c.New != "LR" {
+		t.Errorf("expected dereferenced values, got %#v", c)
+	}
+
+	if got := Diff(&a, &a, nil); len(got) != 0 {
+		t.Errorf("expected no changes for equal values, got %v", got)
+	}
+	empty := Vessel{FormerNames: &[]formerName{}}
+	if got := Diff(Vessel{}, empty, nil); len(got) != 0 {
+		t.Errorf("expected a nil and an empty slice to be equal, got %v", got)
+	}
+}
+
+func TestDiff_IgnoreAndNested(t *testing.T) {
+	type owner = GithubComVesselapiCommonVesselDataContractsTypesClassificationOwner
+	a := ClassificationVessel{CollectedAt: Ptr("2025-01-01T00:00:00Z"), Imo: Ptr(9811000)}
+	b := ClassificationVessel{CollectedAt: Ptr("2025-01-02T00:00:00Z"), Imo: Ptr(9811000), Owner: &owner{}}
+	if got := Diff(a, b, &DiffOptions{Ignore: VolatileFields}); len(got) != 0 {
+		t.Errorf("expected an empty new struct and the ignored field to show no changes, got %v", got)
+	}
+	if got := Diff(a, b, nil); len(got) != 1 || got[0].Path != "collectedAt" {
+		t.Errorf("expected only collectedAt to change, got %v", got)
+	}
+
+	p1 := Port{Name: Ptr("Rotterdam"), Country: &GithubComVesselapiCommonVesselDataContractsTypesPortCountry{Code: Ptr("NL"), Name: Ptr("Netherlands")}}
+	p2 := Port{Name: Ptr("Rotterdam"), Country: &GithubComVesselapiCommonVesselDataContractsTypesPortCountry{Code: Ptr("nl"), Name: Ptr("The Netherlands")}}
+	got := Diff(p1, p2, &DiffOptions{Ignore: []string{"country.code"}})
+	if len(got) != 1 || got[0].Path != "country.name" {
+		t.Errorf("expected only country.name to change, got %v", got)
+	}
+}
+
+func TestDiff_TimesAndMaps(t *testing.T) {
+	type record struct {
+		At    time.Time
+		Attrs map[string]any
+	}
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := record{At: at, Attrs: map[string]any{"a": 1, "b": "x"}}
+	b := record{At: at.In(time.FixedZone("CET", 3600)), Attrs: map[string]any{"a": 1, "b": "y", "c": true}}
+	got := Diff(a, b, nil)
+	if len(got) != 2 || got[0].Path != "Attrs[b]" || got[1].Path != "Attrs[c]" || got[1].Old != nil {
+		t.Errorf("expected changes to Attrs[b] and Attrs[c] only, got %v", got)
+	}
+}
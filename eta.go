@@ -0,0 +1,321 @@
+package vesselapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrETANotAvailable is returned when a vessel reports its ETA using one of
+// the AIS "not available" sentinels (month 0, day 0, hour 24 or minute 60)
+// or leaves the field empty.
+var ErrETANotAvailable = errors.New("vesselapi: ETA not available")
+
+// etaLookback is how far before the report timestamp a resolved ETA may fall.
+// AIS ETAs carry no year; of the candidate years, the one placing the ETA in
+// the window [report-etaLookback, report-etaLookback+1y) is chosen. This
+// favours future arrivals while still accepting ETAs that crews have not
+// updated for a few weeks after arriving.
+const etaLookback = 90 * 24 * time.Hour
+
+// aisETAPattern matches the AIS month/day/hour/minute ETA encodings, such as
+// "03-15 14:30", "03/15 14:30" and "03-15T14:30Z".
+var aisETAPattern = regexp.MustCompile(`^(\d{1,2})[-/](\d{1,2})[ T](\d{1,2}):(\d{1,2})(?::00)?(?:Z| ?UTC)?$`)
+
+// ResolveETA returns the absolute arrival time for e, using e.Timestamp as the
+// reference for year inference. It returns ErrETANotAvailable if the vessel
+// did not report an ETA.
+func ResolveETA(e VesselETA) (time.Time, error) {
+	if e.Timestamp == nil {
+		return time.Time{}, fmt.Errorf("vesselapi: ETA has no report timestamp")
+	}
+	ref, err := parseTimestamp(*e.Timestamp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ParseAISETA(Deref(e.Eta), ref)
+}
+
+// ParseAISETA parses an AIS ETA string and resolves it to an absolute UTC
+// time relative to ref, the time at which the ETA was reported.
+//
+// Year-less values are placed in the year that puts them no more than 90 days
+// before ref, which handles rollover at the turn of the year in both
+// directions. Values that already carry a full RFC 3339 timestamp are
+// returned as-is.
+func ParseAISETA(eta string, ref time.Time) (time.Time, error) {
+	eta = strings.TrimSpace(eta)
+	if eta == "" {
+		return time.Time{}, ErrETANotAvailable
+	}
+	if t, err := parseTimestamp(eta); err == nil {
+		return t, nil
+	}
+
+	m := aisETAPattern.FindStringSubmatch(eta)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("vesselapi: unrecognized ETA %q", eta)
+	}
+	month, _ := strconv.Atoi(m[1])
+	day, _ := strconv.Atoi(m[2])
+	hour, _ := strconv.Atoi(m[3])
+	minute, _ := strconv.Atoi(m[4])
+
+	if month == 0 || day == 0 || hour == 24 || minute == 60 {
+		return time.Time{}, ErrETANotAvailable
+	}
+	if month > 12 || day > 31 || hour > 23 || minute > 59 {
+		return time.Time{}, fmt.Errorf("vesselapi: invalid ETA %q", eta)
+	}
+
+	ref = ref.UTC()
+	earliest := ref.Add(-etaLookback)
+	for year := earliest.Year(); year <= earliest.Year()+1; year++ {
+		t := time.Date(year, time.Month(month), day, hour, minute, 0, 0, time.UTC)
+		if t.Day() != day {
+			// Day does not exist in this year (e.g. 29 February).
+			continue
+		}
+		if !t.Before(earliest) && t.Before(earliest.AddDate(1, 0, 0)) {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("vesselapi: invalid ETA %q", eta)
+}
+
+// DestinationCandidate is a possible UN/LOCODE match for a free-text AIS
+// destination.
+type DestinationCandidate struct {
+	// Unlocode is the candidate port's UN/LOCODE.
+	Unlocode string
+
+	// Port is the port record returned by the API.
+	Port Port
+
+	// Confidence is a score between 0 and 1. A destination that is itself a
+	// valid UN/LOCODE scores 1; name matches score lower depending on how
+	// closely the port name matches.
+	Confidence float64
+}
+
+// destinationQualifiers are trailing or leading words crews append to a
+// destination that describe where in the port area the vessel is going
+// rather than which port.
+var destinationQualifiers = map[string]bool{
+	"ANCH": true, "ANCHORAGE": true, "ANC": true, "OPL": true, "PBG": true,
+	"PILOT": true, "ROADS": true, "RADE": true, "OFF": true, "OFFSHORE": true,
+	"PORT": true, "STS": true, "BERTH": true, "TERMINAL": true, "INNER": true, "OUTER": true,
+}
+
+// destinationUnknown are values crews use when there is no destination yet.
+var destinationUnknown = map[string]bool{
+	"": true, "FOR ORDERS": true, "ORDERS": true, "TBA": true, "TBN": true,
+	"N/A": true, "NA": true, "NONE": true, "UNKNOWN": true,
+}
+
+// destinationAliases maps three-letter port abbreviations that crews use
+// without a country code, such as "RTM" for Rotterdam, to their UN/LOCODE.
+var destinationAliases = map[string]string{
+	"ALG": "ESALG", "AMS": "NLAMS", "ANR": "BEANR", "BCN": "ESBCN",
+	"BRE": "DEBRE", "BRV": "DEBRV", "FOS": "FRFOS", "FXT": "GBFXT",
+	"GOA": "ITGOA", "HAM": "DEHAM", "HKG": "HKHKG", "HOU": "USHOU",
+	"JEA": "AEJEA", "LAX": "USLAX", "LEH": "FRLEH", "LGB": "USLGB",
+	"NGB": "CNNGB", "NYC": "USNYC", "PIR": "GRPIR", "PKG": "MYPKG",
+	"PUS": "KRPUS", "RTM": "NLRTM", "SHA": "CNSHA", "SIN": "SGSIN",
+	"SOU": "GBSOU", "TPP": "MYTPP", "VLC": "ESVLC", "ZEE": "BEZEE",
+}
+
+var (
+	destinationSeparators = regexp.MustCompile(`[^A-Z0-9/ ]+`)
+	unlocodePattern       = regexp.MustCompile(`^([A-Z]{2}) ?([A-Z2-9]{3})$`)
+)
+
+// NormalizeDestination cleans up a free-text AIS destination. It upper-cases
+// the value, keeps only the final leg of "FROM>TO" routes, strips
+// punctuation and location qualifiers such as "ANCH" or "OPL", and collapses
+// whitespace. It returns "" for values such as "FOR ORDERS" that do not name
+// a destination.
+func NormalizeDestination(dest string) string {
+	s := strings.ToUpper(strings.TrimSpace(dest))
+	if i := strings.LastIndex(strings.TrimRight(s, "> "), ">"); i >= 0 {
+		s = s[i+1:]
+	}
+	s = strings.TrimSpace(strings.Trim(s, "> "))
+	if destinationUnknown[s] {
+		return ""
+	}
+	s = destinationSeparators.ReplaceAllString(s, " ")
+
+	fields := strings.Fields(s)
+	for len(fields) > 1 && destinationQualifiers[fields[len(fields)-1]] {
+		fields = fields[:len(fields)-1]
+	}
+	for len(fields) > 1 && destinationQualifiers[fields[0]] {
+		fields = fields[1:]
+	}
+	s = strings.Join(fields, " ")
+	if destinationUnknown[s] {
+		return ""
+	}
+	return s
+}
+
+// ResolveDestination maps a free-text AIS destination such as "NL RTM",
+// "ROTTERDAM>" or "RTM ANCH" to candidate ports, ordered by descending
+// confidence. Destinations that look like a UN/LOCODE, and common
+// three-letter abbreviations such as "RTM", are looked up directly with
+// Get; everything else falls back to a port name search, optionally
+// narrowed by a leading two-letter country code. A single five-letter word
+// such as "DOVER" is taken as a UN/LOCODE only if no port has that name.
+//
+// An empty slice is returned when nothing matches or the destination does
+// not name a port.
+func (s *PortsService) ResolveDestination(ctx context.Context, destination string) ([]DestinationCandidate, error) {
+	norm := NormalizeDestination(destination)
+	if norm == "" {
+		return nil, nil
+	}
+
+	best := make(map[string]DestinationCandidate)
+	add := func(c DestinationCandidate) {
+		if c.Unlocode == "" {
+			return
+		}
+		if prev, ok := best[c.Unlocode]; !ok || c.Confidence > prev.Confidence {
+			best[c.Unlocode] = c
+		}
+	}
+	lookup := func(code string, confidence float64) (*DestinationCandidate, error) {
+		resp, err := s.Get(ctx, code)
+		switch {
+		case err == nil && resp.Port != nil:
+			return &DestinationCandidate{Unlocode: code, Port: *resp.Port, Confidence: confidence}, nil
+		case err != nil && !isNotFound(err):
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// A bare five-letter word is as likely a port name as a UN/LOCODE, so
+	// its match is held back until the name search has run.
+	var word *DestinationCandidate
+	if m := unlocodePattern.FindStringSubmatch(norm); m != nil {
+		code := m[1] + m[2]
+		confidence := 1.0
+		if len(norm) != len(code) {
+			confidence = 0.95
+		}
+		c, err := lookup(code, confidence)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case c == nil:
+		case len(norm) == len(code):
+			word = c
+		default:
+			add(*c)
+		}
+	}
+	if code, ok := destinationAliases[norm]; ok {
+		c, err := lookup(code, 0.9)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			add(*c)
+		}
+	}
+
+	name, country := norm, ""
+	if f := strings.Fields(norm); len(f) > 1 && len(f[0]) == 2 {
+		name, country = strings.Join(f[1:], " "), f[0]
+	}
+	search := &SearchService{client: s.client}
+	queries := []GetSearchPortsParams{{FilterName: Ptr(name)}}
+	if country != "" {
+		queries = []GetSearchPortsParams{
+			{FilterName: Ptr(name), FilterCountry: Ptr(country)},
+			{FilterName: Ptr(norm)},
+		}
+	}
+	named := false
+	for i := range queries {
+		resp, err := search.Ports(ctx, &queries[i])
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, p := range Deref(resp.Ports) {
+			q := Deref(queries[i].FilterName)
+			similarity := nameSimilarity(q, Deref(p.Name))
+			if similarity == 1 {
+				named = true
+			}
+			confidence := similarity * 0.9
+			if country != "" && p.Country != nil && strings.EqualFold(Deref(p.Country.Code), country) {
+				confidence = confidence*0.9 + 0.1
+			}
+			add(DestinationCandidate{Unlocode: Deref(p.UnloCode), Port: p, Confidence: confidence})
+		}
+	}
+	if word != nil && !named {
+		add(*word)
+	}
+
+	out := make([]DestinationCandidate, 0, len(best))
+	for _, c := range best {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Confidence != out[j].Confidence {
+			return out[i].Confidence > out[j].Confidence
+		}
+		return out[i].Unlocode < out[j].Unlocode
+	})
+	return out, nil
+}
+
+// nameSimilarity scores how well a port name matches a normalized
+// destination query, between 0 and 1.
+func nameSimilarity(query, name string) float64 {
+	n := NormalizeDestination(name)
+	switch {
+	case n == "":
+		return 0
+	case n == query:
+		return 1
+	case strings.HasPrefix(n, query) || strings.HasPrefix(query, n):
+		return 0.8
+	case strings.Contains(n, query) || strings.Contains(query, n):
+		return 0.6
+	}
+	qt := strings.Fields(query)
+	nt := make(map[string]bool)
+	for _, t := range strings.Fields(n) {
+		nt[t] = true
+	}
+	shared := 0
+	for _, t := range qt {
+		if nt[t] {
+			shared++
+		}
+	}
+	if len(qt) == 0 {
+		return 0
+	}
+	return 0.5 * float64(shared) / float64(len(qt))
+}
+
+// isNotFound reports whether err is a 404 APIError.
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.IsNotFound()
+}
@@ -0,0 +1,235 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAISETA(t *testing.T) {
+	ref := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		eta  string
+		ref  time.Time
+		want time.Time
+	}{
+		{"same year", "06-15 08:30", ref, time.Date(2025, 6, 15, 8, 30, 0, 0, time.UTC)},
+		{"slash separator", "06/15 08:30", ref, time.Date(2025, 6, 15, 8, 30, 0, 0, time.UTC)},
+		{"T separator with zone", "06-15T08:30Z", ref, time.Date(2025, 6, 15, 8, 30, 0, 0, time.UTC)},
+		{"recently passed", "05-20 00:00", ref, time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)},
+		{"rollover into next year", "01-05 10:00", time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)},
+		{"rollover from previous year", "12-28 10:00", time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 28, 10, 0, 0, 0, time.UTC)},
+		{"leap day skips non-leap year", "02-29 12:00", time.Date(2027, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"full timestamp", "2025-07-01T06:00:00Z", ref, time.Date(2025, 7, 1, 6, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAISETA(tt.eta, tt.ref)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseAISETA_NotAvailable(t *testing.T) {
+	ref := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	for _, eta := range []string{"", "00-00 24:60", "00-15 08:30", "06-00 08:30", "06-15 24:00", "06-15 08:60"} {
+		if _, err := ParseAISETA(eta, ref); !errors.Is(err, ErrETANotAvailable) {
+			t.Errorf("%q: expected ErrETANotAvailable, got %v", eta, err)
+		}
+	}
+}
+
+func TestParseAISETA_Invalid(t *testing.T) {
+	ref := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	for _, eta := range []string{"tomorrow", "13-01 00:00", "06-32 00:00", "06-15 23:61"} {
+		_, err := ParseAISETA(eta, ref)
+		if err == nil {
+			t.Errorf("%q: expected error", eta)
+		}
+		if errors.Is(err, ErrETANotAvailable) {
+			t.Errorf("%q: expected parse error, got ErrETANotAvailable", eta)
+		}
+	}
+}
+
+func TestResolveETA(t *testing.T) {
+	got, err := ResolveETA(VesselETA{
+		Eta:       Ptr("01-02 06:00"),
+		Timestamp: Ptr("2025-12-30T18:00:00Z"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Date(2026, 1, 2, 6, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	if _, err := ResolveETA(VesselETA{Eta: Ptr("01-02 06:00")}); err == nil {
+		t.Error("expected error for missing timestamp")
+	}
+}
+
+func TestNormalizeDestination(t *testing.T) {
+	tests := map[string]string{
+		"NL RTM":          "NL RTM",
+		"ROTTERDAM>":      "ROTTERDAM",
+		" rtm anch ":      "RTM",
+		"NLRTM>DEHAM":     "DEHAM",
+		"NLRTM > DE HAM>": "DE HAM",
+		"ANTWERP OPL":     "ANTWERP",
+		"SINGAPORE.":      "SINGAPORE",
+		"FOR ORDERS":      "",
+		"TBA":             "",
+		"":                "",
+	}
+	for in, want := range tests {
+		if got := NormalizeDestination(in); got != want {
+			t.Errorf("NormalizeDestination(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestResolveDestination_Unlocode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/port/NLRTM":
+			json.NewEncoder(w).Encode(PortResponse{Port: &Port{Name: Ptr("Rotterdam"), UnloCode: Ptr("NLRTM")}})
+		case "/search/ports":
+			json.NewEncoder(w).Encode(FindPortsResponse{Ports: &[]Port{}})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := vc.Ports.ResolveDestination(context.Background(), "NL RTM")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 candidate, got %d", len(got))
+	}
+	if got[0].Unlocode != "NLRTM" || got[0].Confidence != 0.95 {
+		t.Errorf("unexpected candidate %+v", got[0])
+	}
+}
+
+func TestResolveDestination_AliasesAndWords(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/port/NLRTM":
+			json.NewEncoder(w).Encode(PortResponse{Port: &Port{Name: Ptr("Rotterdam"), UnloCode: Ptr("NLRTM")}})
+		case "/port/DOVER", "/port/MTMLA":
+			code := strings.TrimPrefix(r.URL.Path, "/port/")
+			json.NewEncoder(w).Encode(PortResponse{Port: &Port{Name: Ptr("Some Port"), UnloCode: Ptr(code)}})
+		case "/search/ports":
+			ports := []Port{}
+			if r.URL.Query().Get("filter.name") == "DOVER" {
+				ports = append(ports, Port{Name: Ptr("Dover"), UnloCode: Ptr("GBDVR")})
+			}
+			json.NewEncoder(w).Encode(FindPortsResponse{Ports: &ports})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"message":"not found"}}`)
+		}
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := map[string][]string{
+		// An abbreviation is looked up through the alias table.
+		"RTM ANCH": {"NLRTM"},
+		// A five-letter word that names a port is not a UN/LOCODE.
+		"DOVER": {"GBDVR"},
+		// A five-letter word that names no port is a UN/LOCODE if Get
+		// finds it.
+		"MTMLA": {"MTMLA"},
+		// A five-letter word that is neither matches nothing.
+		"MALTA": {},
+	}
+	for dest, want := range tests {
+		got, err := vc.Ports.ResolveDestination(context.Background(), dest)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", dest, err)
+		}
+		codes := []string{}
+		for _, c := range got {
+			codes = append(codes, c.Unlocode)
+		}
+		if fmt.Sprint(codes) != fmt.Sprint(want) {
+			t.Errorf("%s: expected %v, got %v", dest, want, codes)
+		}
+	}
+}
+
+func TestResolveDestination_NameSearch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/search/ports" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("filter.name"); got != "ROTTERDAM" {
+			t.Errorf("expected filter.name=ROTTERDAM, got %q", got)
+		}
+		json.NewEncoder(w).Encode(FindPortsResponse{Ports: &[]Port{
+			{Name: Ptr("Rotterdam Botlek"), UnloCode: Ptr("NLBOT")},
+			{Name: Ptr("Rotterdam"), UnloCode: Ptr("NLRTM")},
+		}})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := vc.Ports.ResolveDestination(context.Background(), "ROTTERDAM>")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 candidates, got %d", len(got))
+	}
+	if got[0].Unlocode != "NLRTM" {
+		t.Errorf("expected exact name match first, got %s", got[0].Unlocode)
+	}
+	if got[0].Confidence <= got[1].Confidence {
+		t.Errorf("expected descending confidence, got %f then %f", got[0].Confidence, got[1].Confidence)
+	}
+}
+
+func TestResolveDestination_NoDestination(t *testing.T) {
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL("http://127.0.0.1:0"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := vc.Ports.ResolveDestination(context.Background(), "FOR ORDERS")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no candidates, got %d", len(got))
+	}
+}
@@ -0,0 +1,91 @@
+package vesselapi
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// docWriter streams a document made of a header, any number of elements
+// and a footer. The header is written with the first element, or by close
+// if there are none. The first write error is kept and returned by every
+// later call.
+type docWriter struct {
+	w      io.Writer
+	format string
+	header string
+	footer string
+
+	started bool
+	closed  bool
+	err     error
+}
+
+// element writes one element of the document.
+func (d *docWriter) element(data []byte) error {
+	if d.closed {
+		return fmt.Errorf("vesselapi: write to closed %s writer", d.format)
+	}
+	if err := d.start(); err != nil {
+		return err
+	}
+	return d.write(data)
+}
+
+// close writes the footer. It does not close the underlying writer.
+func (d *docWriter) close() error {
+	if d.closed {
+		return d.err
+	}
+	if err := d.start(); err != nil {
+		return err
+	}
+	d.closed = true
+	return d.write([]byte(d.footer))
+}
+
+func (d *docWriter) start() error {
+	if d.started {
+		return d.err
+	}
+	d.started = true
+	return d.write([]byte(d.header))
+}
+
+func (d *docWriter) write(data []byte) error {
+	if d.err != nil {
+		return d.err
+	}
+	if _, err := d.w.Write(data); err != nil {
+		d.err = fmt.Errorf("vesselapi: writing %s: %w", d.format, err)
+	}
+	return d.err
+}
+
+// copyIterator writes every item of it with write and returns the number
+// written. It stops at the first error.
+func copyIterator[T any](it *Iterator[T], write func(T) error) (int, error) {
+	n := 0
+	for it.Next() {
+		if err := write(it.Value()); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, it.Err()
+}
+
+// xmlEscape returns s with XML special characters escaped.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s)) //nolint:errcheck // strings.Builder does not fail
+	return b.String()
+}
+
+// formatCoord formats a coordinate with the fewest digits that represent it
+// exactly.
+func formatCoord(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,175 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+)
+
+func portEvent(imo int, port, event string) vesselapi.PortEvent {
+	return vesselapi.PortEvent{
+		Event:     vesselapi.Ptr(event),
+		Timestamp: vesselapi.Ptr("2025-01-01T00:00:00Z"),
+		Port: &vesselapi.GithubComVesselapiCommonVesselDataContractsTypesPortReference{
+			UnloCode: vesselapi.Ptr(port),
+			Name:     vesselapi.Ptr("Rotterdam"),
+		},
+		Vessel: &vesselapi.GithubComVesselapiCommonVesselDataContractsTypesVesselReference{Imo: vesselapi.Ptr(imo)},
+	}
+}
+
+func TestFlattener_Columns(t *testing.T) {
+	f, err := NewFlattener[vesselapi.PortEvent](nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := strings.Join(f.Columns(), ",")
+	want := "event,port.country,port.name,port.unlo_code,timestamp,vessel.imo,vessel.mmsi,vessel.name"
+	if got != want {
+		t.Errorf("expected columns %s, got %s", want, got)
+	}
+
+	goNames, err := NewFlattener[*vesselapi.PortEvent](&Options{Naming: NameGo, Separator: "_"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cols := goNames.Columns(); cols[1] != "Port_Country" {
+		t.Errorf("expected Go field names, got %v", cols)
+	}
+}
+
+func TestFlattener_SelectColumns(t *testing.T) {
+	f, err := NewFlattener[vesselapi.PortEvent](&Options{Columns: []string{"vessel.imo", "port", "event"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(f.Columns(), ","); got != "vessel.imo,port.country,port.name,port.unlo_code,event" {
+		t.Errorf("unexpected columns %s", got)
+	}
+	row := f.Strings(portEvent(9811000, "NLRTM", "Arrival"))
+	if got := strings.Join(row, ","); got != "9811000,,Rotterdam,NLRTM,Arrival" {
+		t.Errorf("unexpected row %s", got)
+	}
+
+	if _, err := NewFlattener[vesselapi.PortEvent](&Options{Columns: []string{"nope"}}); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+	if _, err := NewFlattener[int](nil); err == nil {
+		t.Error("expected an error for a non-struct type")
+	}
+}
+
+func TestFlattener_NilAndSlices(t *testing.T) {
+	f, err := NewFlattener[vesselapi.Vessel](&Options{Columns: []string{"name", "imo", "former_names"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.Strings(vesselapi.Vessel{}); strings.Join(got, "|") != "||" {
+		t.Errorf("expected empty cells for nil fields, got %q", got)
+	}
+	if got := f.Values(vesselapi.Vessel{}); got[0] != nil || got[1] != nil {
+		t.Errorf("expected nil values for nil fields, got %v", got)
+	}
+
+	v := vesselapi.Vessel{
+		Name: vesselapi.Ptr("EVER GIVEN"),
+		Imo:  vesselapi.Ptr(9811000),
+		FormerNames: &[]vesselapi.GithubComVesselapiCommonVesselDataContractsTypesVesselFormerName{
+			{Name: vesselapi.Ptr("EVER GREEN"), YearUntil: vesselapi.Ptr("2018")},
+		},
+	}
+	row := f.Strings(v)
+	if row[0] != "EVER GIVEN" || row[1] != "9811000" {
+		t.Errorf("unexpected row %q", row)
+	}
+	if row[2] != `[{"name":"EVER GREEN","year_until":"2018"}]` {
+		t.Errorf("expected struct slices as JSON, got %s", row[2])
+	}
+
+	type scalars struct {
+		Tags  []string  `json:"tags"`
+		Speed *float32  `json:"speed"`
+		When  time.Time `json:"when"`
+	}
+	sf, err := NewFlattener[scalars](&Options{SliceSeparator: "|"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	row = sf.Strings(scalars{
+		Tags:  []string{"a", "b"},
+		Speed: vesselapi.Ptr(float32(12.1)),
+		When:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if strings.Join(row, ",") != "a|b,12.1,2025-01-01T00:00:00Z" {
+		t.Errorf("unexpected row %q", row)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	events := []vesselapi.PortEvent{
+		portEvent(1, "NLRTM", "Arrival"),
+		{Event: vesselapi.Ptr("Departure, late")},
+	}
+	var buf bytes.Buffer
+	n, err := WriteCSV(&buf, FromSlice(events), &Options{Columns: []string{"event", "port.unlo_code", "vessel.imo"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 rows, got %d", n)
+	}
+	want := "event,port.unlo_code,vessel.imo\nArrival,NLRTM,1\n\"Departure, late\",,\n"
+	if buf.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, buf.String())
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := WriteNDJSON(&buf, FromSlice([]vesselapi.PortEvent{portEvent(1, "NLRTM", "Arrival"), {}}), &Options{Columns: []string{"vessel.imo", "event"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 lines, got %d", n)
+	}
+	want := "{\"vessel.imo\":1,\"event\":\"Arrival\"}\n{\"vessel.imo\":null,\"event\":null}\n"
+	if buf.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, buf.String())
+	}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("invalid JSON line %s", line)
+		}
+	}
+}
+
+type failingIterator struct{ err error }
+
+func (it failingIterator) Next() bool                 { return false }
+func (it failingIterator) Value() vesselapi.PortEvent { return vesselapi.PortEvent{} }
+func (it failingIterator) Err() error                 { return it.err }
+
+func TestWrite_IteratorError(t *testing.T) {
+	boom := errors.New("boom")
+	var buf bytes.Buffer
+	if _, err := WriteCSV[vesselapi.PortEvent](&buf, failingIterator{boom}, nil); !errors.Is(err, boom) {
+		t.Errorf("expected the iterator error from WriteCSV, got %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "event,") {
+		t.Errorf("expected the header to be written, got %q", buf.String())
+	}
+	if _, err := WriteNDJSON[vesselapi.PortEvent](&buf, failingIterator{boom}, nil); !errors.Is(err, boom) {
+		t.Errorf("expected the iterator error from WriteNDJSON, got %v", err)
+	}
+}
+
+func TestIteratorCompatibility(t *testing.T) {
+	// *vesselapi.Iterator satisfies Iterator.
+	var _ Iterator[vesselapi.Vessel] = (*vesselapi.Iterator[vesselapi.Vessel])(nil)
+}
@@ -0,0 +1,268 @@
+// Package export streams API models to tabular formats. A Flattener turns
+// any struct type, such as the generated vesselapi models, into a flat list
+// of named columns by reflection, following nested structs and pointers;
+// WriteCSV and WriteNDJSON stream an iterator of such values to CSV or
+// newline-delimited JSON.
+//
+// Column names are the JSON field names joined with a separator, so a
+// vessel's country code becomes "country.code". A nil pointer anywhere on
+// the way to a column leaves it empty.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDepth bounds how deeply nested structs are followed, so that
+// self-referential types terminate.
+const maxDepth = 8
+
+// Naming selects how column names are derived from struct fields.
+type Naming int
+
+const (
+	// NameJSON names columns after the fields' JSON names, falling back to
+	// the Go field name for fields without a json tag.
+	NameJSON Naming = iota
+
+	// NameGo names columns after the Go field names.
+	NameGo
+)
+
+// Options configures a Flattener. A nil *Options uses the defaults.
+type Options struct {
+	// Columns selects and orders the columns by name. A name may also be
+	// a prefix of nested columns, so "country" selects "country.code" and
+	// "country.name". Defaults to every column in field order.
+	Columns []string
+
+	// Naming selects how column names are derived. Defaults to NameJSON.
+	Naming Naming
+
+	// Separator joins the names of nested fields. Defaults to ".".
+	Separator string
+
+	// SliceSeparator joins the elements of slices of strings, numbers and
+	// booleans in text output. Defaults to ";". Other slices and maps are
+	// written as JSON.
+	SliceSeparator string
+}
+
+// Flattener maps values of type T to flat rows.
+type Flattener[T any] struct {
+	opts    Options
+	columns []column
+}
+
+// column is one flattened field: its name and the field index path from T.
+type column struct {
+	name string
+	path [][]int
+}
+
+// NewFlattener returns a Flattener for T, which must be a struct or a
+// pointer to one. It returns an error if a selected column does not exist.
+func NewFlattener[T any](opts *Options) (*Flattener[T], error) {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Separator == "" {
+		o.Separator = "."
+	}
+	if o.SliceSeparator == "" {
+		o.SliceSeparator = ";"
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("export: %s is not a struct type", t)
+	}
+	all := collectColumns(t, "", nil, o, 0)
+	if len(o.Columns) == 0 {
+		return &Flattener[T]{opts: o, columns: all}, nil
+	}
+
+	var selected []column
+	for _, want := range o.Columns {
+		found := false
+		for _, c := range all {
+			if c.name == want || strings.HasPrefix(c.name, want+o.Separator) {
+				selected = append(selected, c)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("export: unknown column %q for %s", want, t)
+		}
+	}
+	return &Flattener[T]{opts: o, columns: selected}, nil
+}
+
+// collectColumns returns the columns of struct type t. path holds the
+// field index path of t itself; each element steps through one pointer.
+func collectColumns(t reflect.Type, prefix string, path [][]int, o Options, depth int) []column {
+	var cols []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := fieldName(f, o.Naming)
+		if name == "" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		fieldPath := append(append([][]int(nil), path...), f.Index)
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) && depth < maxDepth {
+			nested := prefix + name + o.Separator
+			if f.Anonymous {
+				nested = prefix
+			}
+			cols = append(cols, collectColumns(ft, nested, fieldPath, o, depth+1)...)
+			continue
+		}
+		cols = append(cols, column{name: prefix + name, path: fieldPath})
+	}
+	return cols
+}
+
+// fieldName returns the column name of f, or "" if f is excluded.
+func fieldName(f reflect.StructField, naming Naming) string {
+	if naming == NameGo {
+		return f.Name
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// Columns returns the column names in output order.
+func (f *Flattener[T]) Columns() []string {
+	names := make([]string, len(f.columns))
+	for i, c := range f.columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// Values returns the value of every column of v, with pointers
+// dereferenced. Columns behind a nil pointer are nil.
+func (f *Flattener[T]) Values(v T) []any {
+	root := reflect.ValueOf(&v).Elem()
+	values := make([]any, len(f.columns))
+	for i, c := range f.columns {
+		if fv, ok := lookup(root, c.path); ok {
+			values[i] = fv.Interface()
+		}
+	}
+	return values
+}
+
+// Strings returns the text of every column of v. Columns behind a nil
+// pointer are empty.
+func (f *Flattener[T]) Strings(v T) []string {
+	root := reflect.ValueOf(&v).Elem()
+	row := make([]string, len(f.columns))
+	for i, c := range f.columns {
+		if fv, ok := lookup(root, c.path); ok {
+			row[i] = f.text(fv)
+		}
+	}
+	return row
+}
+
+// lookup follows path from v, dereferencing pointers, and reports false if
+// it meets a nil pointer.
+func lookup(v reflect.Value, path [][]int) (reflect.Value, bool) {
+	v, ok := deref(v)
+	if !ok {
+		return v, false
+	}
+	for _, index := range path {
+		v, ok = deref(v.FieldByIndex(index))
+		if !ok {
+			return v, false
+		}
+	}
+	return v, true
+}
+
+func deref(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// text formats a dereferenced column value.
+func (f *Flattener[T]) text(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Slice, reflect.Array:
+		if scalarKind(v.Type().Elem()) {
+			parts := make([]string, v.Len())
+			for i := range parts {
+				if ev, ok := deref(v.Index(i)); ok {
+					parts[i] = f.text(ev)
+				}
+			}
+			return strings.Join(parts, f.opts.SliceSeparator)
+		}
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return t.Format(time.RFC3339Nano)
+		}
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return string(data)
+}
+
+// scalarKind reports whether t, after dereferencing pointers, is a string,
+// number or boolean.
+func scalarKind(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
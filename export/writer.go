@@ -0,0 +1,108 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Iterator is the subset of vesselapi.Iterator used to stream values.
+type Iterator[T any] interface {
+	Next() bool
+	Value() T
+	Err() error
+}
+
+// sliceIterator iterates over a slice.
+type sliceIterator[T any] struct {
+	items []T
+	i     int
+}
+
+// FromSlice returns an Iterator over items, for exporting values that are
+// already in memory.
+func FromSlice[T any](items []T) Iterator[T] {
+	return &sliceIterator[T]{items: items, i: -1}
+}
+
+func (it *sliceIterator[T]) Next() bool {
+	it.i++
+	return it.i < len(it.items)
+}
+
+func (it *sliceIterator[T]) Value() T { return it.items[it.i] }
+
+func (it *sliceIterator[T]) Err() error { return nil }
+
+// WriteCSV writes a header row followed by one row per value of it, and
+// returns the number of rows written, excluding the header. Rows are
+// flushed after every value, so output appears as pages are fetched. Empty
+// cells stand for nil pointers.
+func WriteCSV[T any](w io.Writer, it Iterator[T], opts *Options) (int, error) {
+	f, err := NewFlattener[T](opts)
+	if err != nil {
+		return 0, err
+	}
+	cw := csv.NewWriter(w)
+	if err := writeCSVRow(cw, f.Columns()); err != nil {
+		return 0, err
+	}
+	n := 0
+	for it.Next() {
+		if err := writeCSVRow(cw, f.Strings(it.Value())); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, it.Err()
+}
+
+func writeCSVRow(cw *csv.Writer, row []string) error {
+	if err := cw.Write(row); err != nil {
+		return fmt.Errorf("export: writing CSV: %w", err)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("export: writing CSV: %w", err)
+	}
+	return nil
+}
+
+// WriteNDJSON writes one JSON object per value of it, one per line, and
+// returns the number written. Each object holds the flattened columns in
+// column order; columns behind a nil pointer are null.
+func WriteNDJSON[T any](w io.Writer, it Iterator[T], opts *Options) (int, error) {
+	f, err := NewFlattener[T](opts)
+	if err != nil {
+		return 0, err
+	}
+	columns := f.Columns()
+	keys := make([][]byte, len(columns))
+	for i, c := range columns {
+		keys[i], _ = json.Marshal(c)
+	}
+
+	n := 0
+	for it.Next() {
+		line := []byte{'{'}
+		for i, v := range f.Values(it.Value()) {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return n, fmt.Errorf("export: encoding %s: %w", columns[i], err)
+			}
+			if i > 0 {
+				line = append(line, ',')
+			}
+			line = append(line, keys[i]...)
+			line = append(line, ':')
+			line = append(line, data...)
+		}
+		line = append(line, '}', '\n')
+		if _, err := w.Write(line); err != nil {
+			return n, fmt.Errorf("export: writing NDJSON: %w", err)
+		}
+		n++
+	}
+	return n, it.Err()
+}
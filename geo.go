@@ -0,0 +1,77 @@
+package vesselapi
+
+import (
+	"errors"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// ErrNoPosition is returned when a VesselPosition has no latitude or
+// longitude.
+var ErrNoPosition = errors.New("vesselapi: position has no coordinates")
+
+// AIS "not available" values for speed and course over ground.
+const (
+	sogNotAvailable = 102.3
+	cogNotAvailable = 360
+)
+
+// PositionPoint returns the coordinates of p as a geo.Point. It reports false
+// if either coordinate is missing.
+func PositionPoint(p VesselPosition) (geo.Point, bool) {
+	if p.Latitude == nil || p.Longitude == nil {
+		return geo.Point{}, false
+	}
+	return geo.Point{Lat: *p.Latitude, Lon: *p.Longitude}, true
+}
+
+// PositionMotion returns the position, course and speed of p as a
+// geo.Motion.
+//
+// A vessel without a usable course or speed is treated as stationary: if
+// either Sog or Cog is nil, or holds an AIS "not available" value (Sog of
+// 102.3 knots or more, Cog of 360 degrees or more), the returned speed is 0.
+// It returns ErrNoPosition if p has no coordinates.
+func PositionMotion(p VesselPosition) (geo.Motion, error) {
+	pt, ok := PositionPoint(p)
+	if !ok {
+		return geo.Motion{}, ErrNoPosition
+	}
+	m := geo.Motion{Position: pt}
+	if p.Sog != nil && p.Cog != nil && *p.Sog >= 0 && *p.Sog < sogNotAvailable && *p.Cog >= 0 && *p.Cog < cogNotAvailable {
+		m.Speed = float64(*p.Sog)
+		m.Course = float64(*p.Cog)
+	}
+	return m, nil
+}
+
+// ClosestApproach computes the closest point of approach (CPA) and time to
+// closest point of approach (TCPA) between two vessels, assuming both hold
+// their reported course and speed. Course and speed follow the rules of
+// PositionMotion.
+//
+// When both positions carry a Timestamp, the older report is first projected
+// forward to the time of the newer one, and TCPA is measured from the newer
+// timestamp. Otherwise the reports are assumed to be simultaneous.
+func ClosestApproach(a, b VesselPosition) (geo.CPA, error) {
+	ma, err := PositionMotion(a)
+	if err != nil {
+		return geo.CPA{}, err
+	}
+	mb, err := PositionMotion(b)
+	if err != nil {
+		return geo.CPA{}, err
+	}
+	if a.Timestamp != nil && b.Timestamp != nil {
+		ta, errA := parseTimestamp(*a.Timestamp)
+		tb, errB := parseTimestamp(*b.Timestamp)
+		if errA == nil && errB == nil {
+			if ta.Before(tb) {
+				ma.Position = ma.Project(tb.Sub(ta))
+			} else {
+				mb.Position = mb.Project(ta.Sub(tb))
+			}
+		}
+	}
+	return geo.ClosestApproach(ma, mb), nil
+}
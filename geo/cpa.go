@@ -0,0 +1,99 @@
+package geo
+
+import (
+	"math"
+	"time"
+)
+
+// Motion is a position together with a course and speed over ground.
+type Motion struct {
+	// Position is the current position.
+	Position Point
+
+	// Course is the course over ground in degrees clockwise from true north.
+	Course float64
+
+	// Speed is the speed over ground in knots.
+	Speed float64
+}
+
+// Project returns the position reached after travelling for d at the
+// motion's course and speed along a great circle. Negative durations project
+// backwards.
+func (m Motion) Project(d time.Duration) Point {
+	dist := m.Speed * MetersPerSecondPerKnot * d.Seconds()
+	return Destination(m.Position, m.Course, dist)
+}
+
+// CPA describes the closest point of approach between two moving vessels.
+type CPA struct {
+	// TCPA is the time from now until the closest approach. It is negative
+	// when the vessels are already diverging, in which case the closest
+	// approach lies in the past; it is zero when there is no relative motion.
+	// It is capped at a week either way, beyond which the closest approach
+	// is reported at the cap.
+	TCPA time.Duration
+
+	// Distance is the separation at the closest approach, in meters.
+	Distance float64
+
+	// DistanceNM is the separation at the closest approach, in nautical miles.
+	DistanceNM float64
+
+	// A and B are the projected positions of each vessel at the closest
+	// approach.
+	A, B Point
+}
+
+const (
+	// maxTCPA caps the time to the closest approach, which grows without
+	// bound as the relative speed nears zero.
+	maxTCPA = 7 * 24 * time.Hour
+
+	// minRelativeSpeed is the relative speed in meters per second below
+	// which two vessels are treated as on parallel courses, about 0.01 kn.
+	minRelativeSpeed = 0.005
+)
+
+// ClosestApproach computes the closest point of approach between a and b,
+// assuming both hold their current course and speed.
+//
+// Relative motion is solved on a local tangent plane centred between the two
+// vessels, which is accurate for the separations at which CPA is meaningful
+// (tens of nautical miles). The reported distance is the great-circle
+// distance between the projected positions.
+func ClosestApproach(a, b Motion) CPA {
+	mid := Intermediate(a.Position, b.Position, 0.5)
+	cosLat := math.Cos(radians(mid.Lat))
+
+	// Relative position of b from a in meters (east, north).
+	dLon := radians(NormalizeLon(b.Position.Lon - a.Position.Lon))
+	rx := dLon * cosLat * EarthRadius
+	ry := radians(b.Position.Lat-a.Position.Lat) * EarthRadius
+
+	// Relative velocity of b with respect to a in meters per second.
+	avx, avy := velocity(a)
+	bvx, bvy := velocity(b)
+	vx, vy := bvx-avx, bvy-avy
+
+	var t float64
+	if v2 := vx*vx + vy*vy; v2 >= minRelativeSpeed*minRelativeSpeed {
+		t = -(rx*vx + ry*vy) / v2
+	}
+	// Clamp in seconds before converting, so that the Duration cannot
+	// overflow.
+	t = math.Max(math.Min(t, maxTCPA.Seconds()), -maxTCPA.Seconds())
+	tcpa := time.Duration(t * float64(time.Second))
+
+	pa, pb := a.Project(tcpa), b.Project(tcpa)
+	dist := Distance(pa, pb)
+	return CPA{TCPA: tcpa, Distance: dist, DistanceNM: MetersToNM(dist), A: pa, B: pb}
+}
+
+// velocity returns the east and north components of m's velocity in meters
+// per second.
+func velocity(m Motion) (float64, float64) {
+	speed := m.Speed * MetersPerSecondPerKnot
+	c := radians(m.Course)
+	return speed * math.Sin(c), speed * math.Cos(c)
+}
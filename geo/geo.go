@@ -0,0 +1,210 @@
+// Package geo provides spherical-earth geodesy helpers for working with
+// vessel positions: great-circle and rhumb-line distance and bearing,
+// destination-point projection, and closest point of approach.
+//
+// Coordinates are decimal degrees, distances are meters and speeds are
+// knots unless a function name says otherwise. The earth is modelled as a
+// sphere with the IUGG mean radius, which is accurate to about 0.5% — ample
+// for navigation displays and alerting, but not for surveying.
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// EarthRadius is the mean earth radius in meters.
+	EarthRadius = 6371008.8
+
+	// MetersPerNauticalMile is the length of one international nautical mile.
+	MetersPerNauticalMile = 1852.0
+
+	// MetersPerSecondPerKnot converts a speed in knots to meters per second.
+	MetersPerSecondPerKnot = MetersPerNauticalMile / 3600
+)
+
+// Point is a geographic position in decimal degrees.
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// validate reports whether p lies within the valid coordinate ranges.
+func (p Point) validate() error {
+	if math.IsNaN(p.Lat) || math.IsNaN(p.Lon) || p.Lat < -90 || p.Lat > 90 || p.Lon < -180 || p.Lon > 180 {
+		return fmt.Errorf("geo: coordinate (%g, %g) out of range", p.Lat, p.Lon)
+	}
+	return nil
+}
+
+// MetersToNM converts meters to nautical miles.
+func MetersToNM(m float64) float64 { return m / MetersPerNauticalMile }
+
+// NMToMeters converts nautical miles to meters.
+func NMToMeters(nm float64) float64 { return nm * MetersPerNauticalMile }
+
+// NormalizeLon wraps a longitude into the range [-180, 180).
+func NormalizeLon(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
+
+// normalizeBearing wraps a bearing into the range [0, 360).
+func normalizeBearing(b float64) float64 {
+	b = math.Mod(b, 360)
+	if b < 0 {
+		b += 360
+	}
+	return b
+}
+
+func radians(d float64) float64 { return d * math.Pi / 180 }
+func degrees(r float64) float64 { return r * 180 / math.Pi }
+
+// Distance returns the great-circle distance between a and b in meters,
+// using the haversine formula.
+func Distance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLon := radians(b.Lon - a.Lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// Bearing returns the initial great-circle bearing from a to b in degrees
+// clockwise from true north, in the range [0, 360). The bearing is 0 when a
+// and b coincide.
+func Bearing(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLon := radians(b.Lon - a.Lon)
+	y := math.Sin(dLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+	return normalizeBearing(degrees(math.Atan2(y, x)))
+}
+
+// FinalBearing returns the great-circle bearing on arrival at b when
+// travelling from a, in degrees in the range [0, 360).
+func FinalBearing(a, b Point) float64 {
+	return normalizeBearing(Bearing(b, a) + 180)
+}
+
+// Destination returns the point reached by travelling distance meters from p
+// along the great circle with the given initial bearing in degrees.
+func Destination(p Point, bearing, distance float64) Point {
+	d := distance / EarthRadius
+	brng := radians(bearing)
+	lat1, lon1 := radians(p.Lat), radians(p.Lon)
+	sinLat2 := math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(brng)
+	lat2 := math.Asin(sinLat2)
+	lon2 := lon1 + math.Atan2(math.Sin(brng)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*sinLat2)
+	return Point{Lat: degrees(lat2), Lon: NormalizeLon(degrees(lon2))}
+}
+
+// Intermediate returns the point at fraction f (0 to 1) of the way along the
+// great circle from a to b.
+func Intermediate(a, b Point, f float64) Point {
+	d := Distance(a, b) / EarthRadius
+	if d == 0 {
+		return a
+	}
+	lat1, lon1 := radians(a.Lat), radians(a.Lon)
+	lat2, lon2 := radians(b.Lat), radians(b.Lon)
+	fa := math.Sin((1-f)*d) / math.Sin(d)
+	fb := math.Sin(f*d) / math.Sin(d)
+	x := fa*math.Cos(lat1)*math.Cos(lon1) + fb*math.Cos(lat2)*math.Cos(lon2)
+	y := fa*math.Cos(lat1)*math.Sin(lon1) + fb*math.Cos(lat2)*math.Sin(lon2)
+	z := fa*math.Sin(lat1) + fb*math.Sin(lat2)
+	return Point{
+		Lat: degrees(math.Atan2(z, math.Sqrt(x*x+y*y))),
+		Lon: NormalizeLon(degrees(math.Atan2(y, x))),
+	}
+}
+
+// DistanceToSegment returns the shortest great-circle distance in meters from
+// p to the great-circle segment between a and b.
+func DistanceToSegment(p, a, b Point) float64 {
+	dab := Distance(a, b)
+	dap := Distance(a, p)
+	if dab == 0 || dap == 0 {
+		return dap
+	}
+	// Beyond either end of the segment the closest point is an endpoint.
+	if math.Cos(radians(Bearing(a, p)-Bearing(a, b))) <= 0 {
+		return dap
+	}
+	if math.Cos(radians(Bearing(b, p)-Bearing(b, a))) <= 0 {
+		return Distance(b, p)
+	}
+	xt := math.Asin(math.Sin(dap/EarthRadius) * math.Sin(radians(Bearing(a, p)-Bearing(a, b))))
+	return math.Abs(xt) * EarthRadius
+}
+
+// rhumbStretch returns the difference in isometric latitude between lat1 and
+// lat2 (the "projected" latitude difference on a Mercator chart).
+func rhumbStretch(lat1, lat2 float64) float64 {
+	return math.Log(math.Tan(math.Pi/4+lat2/2) / math.Tan(math.Pi/4+lat1/2))
+}
+
+// rhumbDeltaLon returns the longitude difference from a to b in radians,
+// taking the shorter way around the antimeridian.
+func rhumbDeltaLon(a, b Point) float64 {
+	dLon := radians(b.Lon - a.Lon)
+	if math.Abs(dLon) > math.Pi {
+		if dLon > 0 {
+			dLon -= 2 * math.Pi
+		} else {
+			dLon += 2 * math.Pi
+		}
+	}
+	return dLon
+}
+
+// RhumbDistance returns the distance in meters between a and b along a
+// rhumb line (a path of constant bearing).
+func RhumbDistance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dPsi := rhumbStretch(lat1, lat2)
+	q := math.Cos(lat1)
+	if math.Abs(dPsi) > 1e-12 {
+		q = dLat / dPsi
+	}
+	dLon := rhumbDeltaLon(a, b)
+	return math.Sqrt(dLat*dLat+q*q*dLon*dLon) * EarthRadius
+}
+
+// RhumbBearing returns the constant bearing from a to b along a rhumb line,
+// in degrees in the range [0, 360).
+func RhumbBearing(a, b Point) float64 {
+	dPsi := rhumbStretch(radians(a.Lat), radians(b.Lat))
+	return normalizeBearing(degrees(math.Atan2(rhumbDeltaLon(a, b), dPsi)))
+}
+
+// RhumbDestination returns the point reached by travelling distance meters
+// from p along a rhumb line with the given bearing in degrees.
+func RhumbDestination(p Point, bearing, distance float64) Point {
+	d := distance / EarthRadius
+	brng := radians(bearing)
+	lat1, lon1 := radians(p.Lat), radians(p.Lon)
+	dLat := d * math.Cos(brng)
+	lat2 := lat1 + dLat
+	// Clamp if the line passes a pole.
+	if math.Abs(lat2) > math.Pi/2 {
+		if lat2 > 0 {
+			lat2 = math.Pi - lat2
+		} else {
+			lat2 = -math.Pi - lat2
+		}
+	}
+	dPsi := rhumbStretch(lat1, lat2)
+	q := math.Cos(lat1)
+	if math.Abs(dPsi) > 1e-12 {
+		q = dLat / dPsi
+	}
+	dLon := d * math.Sin(brng) / q
+	return Point{Lat: degrees(lat2), Lon: NormalizeLon(degrees(lon1 + dLon))}
+}
@@ -0,0 +1,372 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func approx(t *testing.T, name string, got, want, tol float64) {
+	t.Helper()
+	if math.Abs(got-want) > tol {
+		t.Errorf("%s: expected %.4f (±%g), got %.4f", name, want, tol, got)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	landsEnd := Point{Lat: 50.0663, Lon: -5.7147}
+	johnOGroats := Point{Lat: 58.6439, Lon: -3.0700}
+	approx(t, "distance", Distance(landsEnd, johnOGroats), 968_900, 500)
+	approx(t, "reverse", Distance(johnOGroats, landsEnd), Distance(landsEnd, johnOGroats), 1e-6)
+	approx(t, "zero", Distance(landsEnd, landsEnd), 0, 1e-9)
+
+	// One minute of latitude is close to one nautical mile.
+	approx(t, "one minute", MetersToNM(Distance(Point{0, 0}, Point{1.0 / 60, 0})), 1, 0.001)
+
+	// Across the antimeridian.
+	approx(t, "antimeridian", Distance(Point{0, 179.5}, Point{0, -179.5}), 111_195, 10)
+}
+
+func TestBearing(t *testing.T) {
+	landsEnd := Point{Lat: 50.0663, Lon: -5.7147}
+	johnOGroats := Point{Lat: 58.6439, Lon: -3.0700}
+	approx(t, "initial", Bearing(landsEnd, johnOGroats), 9.1198, 0.001)
+	approx(t, "final", FinalBearing(landsEnd, johnOGroats), 11.2752, 0.001)
+	approx(t, "east", Bearing(Point{0, 0}, Point{0, 1}), 90, 1e-9)
+	approx(t, "west across antimeridian", Bearing(Point{0, -179.5}, Point{0, 179.5}), 270, 1e-9)
+}
+
+func TestDestination(t *testing.T) {
+	start := Point{Lat: 53.3206, Lon: -1.7297}
+	got := Destination(start, 96.0217, 124_800)
+	approx(t, "lat", got.Lat, 53.1883, 0.001)
+	approx(t, "lon", got.Lon, 0.1333, 0.001)
+
+	// Round trip.
+	back := Destination(got, FinalBearing(start, got)+180, Distance(start, got))
+	approx(t, "round trip lat", back.Lat, start.Lat, 1e-6)
+	approx(t, "round trip lon", back.Lon, start.Lon, 1e-6)
+
+	// Crossing the antimeridian normalizes longitude.
+	got = Destination(Point{0, 179.9}, 90, 22_239)
+	approx(t, "wrapped lon", got.Lon, -179.9, 0.001)
+}
+
+func TestIntermediate(t *testing.T) {
+	a, b := Point{0, 0}, Point{0, 10}
+	mid := Intermediate(a, b, 0.5)
+	approx(t, "lat", mid.Lat, 0, 1e-9)
+	approx(t, "lon", mid.Lon, 5, 1e-9)
+	if got := Intermediate(a, a, 0.5); got != a {
+		t.Errorf("expected coincident point, got %+v", got)
+	}
+}
+
+func TestRhumb(t *testing.T) {
+	a := Point{Lat: 51.127, Lon: 1.338}
+	b := Point{Lat: 50.964, Lon: 1.853}
+	approx(t, "distance", RhumbDistance(a, b), 40_310, 20)
+	approx(t, "bearing", RhumbBearing(a, b), 116.7226, 0.001)
+
+	dest := RhumbDestination(a, RhumbBearing(a, b), RhumbDistance(a, b))
+	approx(t, "destination lat", dest.Lat, b.Lat, 1e-6)
+	approx(t, "destination lon", dest.Lon, b.Lon, 1e-6)
+
+	// Due east along a parallel.
+	approx(t, "parallel bearing", RhumbBearing(Point{60, 0}, Point{60, 10}), 90, 1e-9)
+	approx(t, "parallel distance", RhumbDistance(Point{60, 0}, Point{60, 10}), 555_975, 10)
+
+	// Rhumb lines take the short way across the antimeridian.
+	approx(t, "antimeridian bearing", RhumbBearing(Point{0, 179}, Point{0, -179}), 90, 1e-9)
+	approx(t, "antimeridian distance", RhumbDistance(Point{0, 179}, Point{0, -179}), 222_390, 10)
+}
+
+func TestNormalizeLon(t *testing.T) {
+	for in, want := range map[float64]float64{0: 0, 180: -180, -180: -180, 190: -170, -190: 170, 540: -180, 359: -1} {
+		approx(t, "normalize", NormalizeLon(in), want, 1e-9)
+	}
+}
+
+func TestClosestApproach_HeadOn(t *testing.T) {
+	// Two vessels 10 NM apart on the equator closing at 10 knots each.
+	a := Motion{Position: Point{0, 0}, Course: 90, Speed: 10}
+	b := Motion{Position: Destination(Point{0, 0}, 90, NMToMeters(10)), Course: 270, Speed: 10}
+	cpa := ClosestApproach(a, b)
+	approx(t, "tcpa minutes", cpa.TCPA.Minutes(), 30, 0.05)
+	approx(t, "distance", cpa.Distance, 0, 5)
+	approx(t, "distance nm", cpa.DistanceNM, MetersToNM(cpa.Distance), 1e-9)
+}
+
+func TestClosestApproach_Crossing(t *testing.T) {
+	// b crosses a's bow 1 NM ahead while a is stopped.
+	a := Motion{Position: Point{0, 0}}
+	start := Destination(Destination(Point{0, 0}, 0, NMToMeters(1)), 270, NMToMeters(5))
+	b := Motion{Position: start, Course: 90, Speed: 12}
+	cpa := ClosestApproach(a, b)
+	approx(t, "tcpa minutes", cpa.TCPA.Minutes(), 25, 0.1)
+	approx(t, "distance nm", cpa.DistanceNM, 1, 0.001)
+}
+
+func TestClosestApproach_Diverging(t *testing.T) {
+	a := Motion{Position: Point{0, 0}, Course: 270, Speed: 10}
+	b := Motion{Position: Point{0, 0.1}, Course: 90, Speed: 10}
+	cpa := ClosestApproach(a, b)
+	if cpa.TCPA >= 0 {
+		t.Errorf("expected negative TCPA for diverging vessels, got %s", cpa.TCPA)
+	}
+}
+
+func TestClosestApproach_NoRelativeMotion(t *testing.T) {
+	a := Motion{Position: Point{10, 10}, Course: 45, Speed: 8}
+	b := Motion{Position: Point{10.1, 10}, Course: 45, Speed: 8}
+	cpa := ClosestApproach(a, b)
+	if cpa.TCPA != 0 {
+		t.Errorf("expected zero TCPA, got %s", cpa.TCPA)
+	}
+	approx(t, "distance", cpa.Distance, Distance(a.Position, b.Position), 1e-6)
+}
+
+func TestClosestApproach_NearlyParallel(t *testing.T) {
+	// A relative speed of about 1e-6 m/s over 10,000 km is parallel.
+	a := Motion{Position: Point{0, 0}, Course: 90, Speed: 10}
+	b := Motion{Position: Point{0, 90}, Course: 90, Speed: 10 - 2e-6}
+	if cpa := ClosestApproach(a, b); cpa.TCPA != 0 {
+		t.Errorf("expected zero TCPA, got %s", cpa.TCPA)
+	}
+
+	// A slow but real closing speed is capped rather than overflowing.
+	b.Speed = 9.98
+	cpa := ClosestApproach(a, b)
+	if cpa.TCPA != maxTCPA {
+		t.Errorf("expected TCPA capped at %s, got %s", maxTCPA, cpa.TCPA)
+	}
+	if math.IsNaN(cpa.Distance) || cpa.Distance <= 0 {
+		t.Errorf("expected a positive distance, got %v", cpa.Distance)
+	}
+}
+
+func TestMotionProject(t *testing.T) {
+	m := Motion{Position: Point{0, 0}, Course: 0, Speed: 60}
+	got := m.Project(time.Minute)
+	approx(t, "one nm north", MetersToNM(Distance(m.Position, got)), 1, 1e-6)
+	approx(t, "bearing", Bearing(m.Position, got), 0, 1e-6)
+}
+
+func TestPolygonContains(t *testing.T) {
+	square := Rectangle(0, 0, 10, 10)
+	if !square.Contains(Point{5, 5}) {
+		t.Error("expected center inside square")
+	}
+	if square.Contains(Point{11, 5}) || square.Contains(Point{5, -1}) {
+		t.Error("expected outside points to be excluded")
+	}
+
+	// Concave "C" shape open to the east.
+	c := Polygon{{0, 0}, {0, 10}, {3, 10}, {3, 3}, {7, 3}, {7, 10}, {10, 10}, {10, 0}}
+	if c.Contains(Point{5, 6}) {
+		t.Error("expected point in the notch to be outside")
+	}
+	if !c.Contains(Point{5, 1}) {
+		t.Error("expected point in the spine to be inside")
+	}
+}
+
+func TestPolygonBounds(t *testing.T) {
+	s, w, n, e := Polygon{{1, 5}, {-2, 7}, {4, -3}}.Bounds()
+	if s != -2 || w != -3 || n != 4 || e != 7 {
+		t.Errorf("unexpected bounds %f %f %f %f", s, w, n, e)
+	}
+}
+
+func TestPolygonIntersectsRect(t *testing.T) {
+	triangle := Polygon{{0, 0}, {0, 10}, {10, 0}}
+	tests := []struct {
+		name                     string
+		south, west, north, east float64
+		want                     bool
+	}{
+		{"contains vertex", -1, -1, 1, 1, true},
+		{"inside polygon", 1, 1, 2, 2, true},
+		{"contains polygon", -5, -5, 15, 15, true},
+		{"edge crossing", 4, -1, 6, 11, true},
+		{"beyond hypotenuse", 8, 8, 9, 9, false},
+		{"disjoint", 20, 20, 30, 30, false},
+	}
+	for _, tt := range tests {
+		if got := triangle.IntersectsRect(tt.south, tt.west, tt.north, tt.east); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestDistanceToSegment(t *testing.T) {
+	a, b := Point{0, 0}, Point{0, 2}
+	oneDeg := Distance(Point{0, 0}, Point{1, 0})
+	approx(t, "abeam", DistanceToSegment(Point{1, 1}, a, b), oneDeg, 200)
+	approx(t, "before start", DistanceToSegment(Point{0, -1}, a, b), Distance(Point{0, -1}, a), 1e-6)
+	approx(t, "after end", DistanceToSegment(Point{1, 3}, a, b), Distance(Point{1, 3}, b), 1e-6)
+	approx(t, "on segment", DistanceToSegment(Point{0, 1}, a, b), 0, 1e-6)
+	approx(t, "degenerate", DistanceToSegment(Point{1, 0}, a, a), oneDeg, 1e-6)
+}
+
+func TestMultiPolygon_Holes(t *testing.T) {
+	// A 10° square with a 2° hole, plus a separate island.
+	m := MultiPolygon{
+		Rectangle(0, 0, 10, 10),
+		Rectangle(4, 4, 6, 6),
+		Rectangle(20, 20, 21, 21),
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, p := range []Point{{1, 1}, {9, 5}, {20.5, 20.5}} {
+		if !m.Contains(p) {
+			t.Errorf("expected %+v inside", p)
+		}
+	}
+	for _, p := range []Point{{5, 5}, {15, 15}, {-1, 5}} {
+		if m.Contains(p) {
+			t.Errorf("expected %+v outside", p)
+		}
+	}
+	s, w, n, e := m.Bounds()
+	if s != 0 || w != 0 || n != 21 || e != 21 {
+		t.Errorf("unexpected bounds %v %v %v %v", s, w, n, e)
+	}
+	if m.IntersectsRect(12, 12, 15, 15) {
+		t.Error("expected no overlap between the members")
+	}
+	if (MultiPolygon{}).Validate() == nil {
+		t.Error("expected error for empty multipolygon")
+	}
+}
+
+func TestCorridor(t *testing.T) {
+	// A 20 km wide lane running east along the equator, then north.
+	c := Corridor{Line: []Point{{0, 0}, {0, 2}, {2, 2}}, Buffer: 10_000}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, p := range []Point{{0.05, 1}, {-0.05, 0}, {1, 2.05}, {0.05, 2.05}} {
+		if !c.Contains(p) {
+			t.Errorf("expected %+v inside", p)
+		}
+	}
+	for _, p := range []Point{{0.2, 1}, {1, 1.8}, {0, -0.2}, {2.2, 2}} {
+		if c.Contains(p) {
+			t.Errorf("expected %+v outside", p)
+		}
+	}
+
+	s, w, n, e := c.Bounds()
+	for _, p := range []Point{{-0.089, 0}, {0, -0.089}, {2.089, 2}, {2, 2.089}} {
+		if p.Lat < s || p.Lat > n || p.Lon < w || p.Lon > e {
+			t.Errorf("expected %+v within bounds %v %v %v %v", p, s, w, n, e)
+		}
+	}
+	if !c.IntersectsRect(0.05, 0.5, 0.5, 1) {
+		t.Error("expected box touching the buffer to intersect")
+	}
+	if c.IntersectsRect(0.5, 0.5, 1.5, 1.5) {
+		t.Error("expected box inside the bend to be skipped")
+	}
+
+	for _, bad := range []Corridor{
+		{Line: []Point{{0, 0}}, Buffer: 1},
+		{Line: []Point{{0, 0}, {0, 1}}},
+		{Line: []Point{{0, 0}, {95, 1}}, Buffer: 1},
+	} {
+		if bad.Validate() == nil {
+			t.Errorf("%+v: expected error", bad)
+		}
+	}
+}
+
+func TestParseGeoJSONPolygon(t *testing.T) {
+	m, err := ParseGeoJSONPolygon([]byte(`{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[
+		[[0,0],[10,0],[10,10],[0,10],[0,0]],
+		[[4,4],[6,4],[6,6],[4,6],[4,4]]
+	]}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m) != 2 || len(m[0]) != 4 {
+		t.Fatalf("expected 2 rings of 4 vertices, got %v", m)
+	}
+	if m[0][1] != (Point{Lat: 0, Lon: 10}) {
+		t.Errorf("expected [lon, lat] order, got %+v", m[0][1])
+	}
+	if m.Contains(Point{5, 5}) || !m.Contains(Point{1, 1}) {
+		t.Error("expected hole to be excluded")
+	}
+
+	m, err = ParseGeoJSONPolygon([]byte(`{"type":"MultiPolygon","coordinates":[
+		[[[0,0],[1,0],[1,1],[0,0]]],
+		[[[5,5],[6,5],[6,6],[5,5]]]
+	]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m) != 2 {
+		t.Errorf("expected 2 rings, got %d", len(m))
+	}
+
+	for _, bad := range []string{
+		`{"type":"Point","coordinates":[0,0]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,1]]]}`,
+		`{"type":"Feature"}`,
+		`not json`,
+	} {
+		if _, err := ParseGeoJSONPolygon([]byte(bad)); err == nil {
+			t.Errorf("%s: expected error", bad)
+		}
+	}
+}
+
+func TestParseGeoJSONLine(t *testing.T) {
+	line, err := ParseGeoJSONLine([]byte(`{"type":"LineString","coordinates":[[4.1,51.9],[3.0,52.5,0]]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(line) != 2 || line[0] != (Point{Lat: 51.9, Lon: 4.1}) {
+		t.Errorf("unexpected line %+v", line)
+	}
+	if _, err := ParseGeoJSONLine([]byte(`{"type":"LineString","coordinates":[[4.1]]}`)); err == nil {
+		t.Error("expected error for short position")
+	}
+	if _, err := ParseGeoJSONLine([]byte(`{"type":"Polygon","coordinates":[]}`)); err == nil {
+		t.Error("expected error for wrong type")
+	}
+}
+
+func TestCircle(t *testing.T) {
+	c := Circle{Center: Point{50, 0}, Radius: 10_000}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.Contains(Point{50.05, 0}) || c.Contains(Point{50.1, 0}) {
+		t.Error("unexpected containment")
+	}
+	s, w, n, e := c.Bounds()
+	for _, bearing := range []float64{0, 90, 180, 270} {
+		p := Destination(c.Center, bearing, c.Radius)
+		if p.Lat < s-1e-9 || p.Lat > n+1e-9 || p.Lon < w-1e-9 || p.Lon > e+1e-9 {
+			t.Errorf("edge point %+v outside bounds", p)
+		}
+	}
+	approx(t, "edge distance inside", c.DistanceToEdge(Destination(c.Center, 45, 7_000)), 3_000, 1)
+	approx(t, "edge distance outside", c.DistanceToEdge(Destination(c.Center, 45, 12_000)), 2_000, 1)
+	if (Circle{Center: Point{0, 0}}).Validate() == nil {
+		t.Error("expected error for zero radius")
+	}
+}
+
+func TestDistanceToEdge(t *testing.T) {
+	sq := Rectangle(0, 0, 1, 1)
+	oneDeg := Distance(Point{0, 0}, Point{1, 0})
+	approx(t, "polygon centre", sq.DistanceToEdge(Point{0.5, 0.5}), oneDeg/2, 200)
+	approx(t, "polygon outside", sq.DistanceToEdge(Point{0.5, 2}), oneDeg, 200)
+	approx(t, "multipolygon hole", MultiPolygon{Rectangle(0, 0, 10, 10), Rectangle(4, 4, 6, 6)}.DistanceToEdge(Point{5, 5}), oneDeg*math.Cos(5*math.Pi/180), 200)
+	c := Corridor{Line: []Point{{0, 0}, {0, 1}}, Buffer: 1000}
+	approx(t, "corridor", c.DistanceToEdge(Point{0, 0.5}), 1000, 1e-6)
+}
@@ -0,0 +1,118 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// geoJSON holds the members of a GeoJSON geometry or Feature that the
+// parsers below understand.
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+}
+
+// decodeGeometry unmarshals data as a GeoJSON geometry, unwrapping a Feature
+// if necessary.
+func decodeGeometry(data []byte) (*geoJSON, error) {
+	var g geoJSON
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("geo: decoding GeoJSON: %w", err)
+	}
+	if g.Type == "Feature" {
+		if g.Geometry == nil {
+			return nil, fmt.Errorf("geo: GeoJSON feature has no geometry")
+		}
+		return g.Geometry, nil
+	}
+	return &g, nil
+}
+
+// ParseGeoJSONPolygon parses a GeoJSON Polygon or MultiPolygon geometry, or a
+// Feature wrapping one, into a MultiPolygon. Holes become inner rings.
+func ParseGeoJSONPolygon(data []byte) (MultiPolygon, error) {
+	g, err := decodeGeometry(data)
+	if err != nil {
+		return nil, err
+	}
+	var m MultiPolygon
+	switch g.Type {
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &rings); err != nil {
+			return nil, fmt.Errorf("geo: decoding Polygon coordinates: %w", err)
+		}
+		for _, r := range rings {
+			ring, err := ringFromCoordinates(r)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, ring)
+		}
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("geo: decoding MultiPolygon coordinates: %w", err)
+		}
+		for _, rings := range polygons {
+			for _, r := range rings {
+				ring, err := ringFromCoordinates(r)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, ring)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("geo: unsupported GeoJSON geometry type %q, want Polygon or MultiPolygon", g.Type)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParseGeoJSONLine parses a GeoJSON LineString geometry, or a Feature wrapping
+// one, into its points. Combine the result with a buffer distance to build a
+// Corridor.
+func ParseGeoJSONLine(data []byte) ([]Point, error) {
+	g, err := decodeGeometry(data)
+	if err != nil {
+		return nil, err
+	}
+	if g.Type != "LineString" {
+		return nil, fmt.Errorf("geo: unsupported GeoJSON geometry type %q, want LineString", g.Type)
+	}
+	var coords [][]float64
+	if err := json.Unmarshal(g.Coordinates, &coords); err != nil {
+		return nil, fmt.Errorf("geo: decoding LineString coordinates: %w", err)
+	}
+	return pointsFromCoordinates(coords)
+}
+
+// ringFromCoordinates converts a closed GeoJSON linear ring into a Polygon,
+// dropping the repeated closing position.
+func ringFromCoordinates(coords [][]float64) (Polygon, error) {
+	pts, err := pointsFromCoordinates(coords)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(pts); n > 1 && pts[0] == pts[n-1] {
+		pts = pts[:n-1]
+	}
+	return Polygon(pts), nil
+}
+
+// pointsFromCoordinates converts GeoJSON [longitude, latitude] positions into
+// points.
+func pointsFromCoordinates(coords [][]float64) ([]Point, error) {
+	pts := make([]Point, len(coords))
+	for i, c := range coords {
+		if len(c) < 2 {
+			return nil, fmt.Errorf("geo: GeoJSON position %d has %d coordinates", i, len(c))
+		}
+		pts[i] = Point{Lat: c[1], Lon: c[0]}
+	}
+	return pts, nil
+}
@@ -0,0 +1,137 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Polygon is a simple polygon given as a ring of vertices. The ring is
+// implicitly closed; repeating the first vertex at the end is allowed but
+// not required.
+//
+// Polygon operations treat latitude and longitude as planar coordinates,
+// which matches how the API evaluates bounding boxes. Polygons must not
+// cross the antimeridian.
+type Polygon []Point
+
+// Rectangle returns the polygon covering the box between the given edges.
+func Rectangle(south, west, north, east float64) Polygon {
+	return Polygon{{south, west}, {south, east}, {north, east}, {north, west}}
+}
+
+// Validate reports whether p has at least three vertices, all within the
+// valid latitude and longitude ranges.
+func (p Polygon) Validate() error {
+	if len(p) < 3 {
+		return fmt.Errorf("geo: polygon must have at least 3 vertices, got %d", len(p))
+	}
+	for _, v := range p {
+		if err := v.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bounds returns the smallest latitude/longitude box containing p.
+func (p Polygon) Bounds() (south, west, north, east float64) {
+	if len(p) == 0 {
+		return 0, 0, 0, 0
+	}
+	south, north = p[0].Lat, p[0].Lat
+	west, east = p[0].Lon, p[0].Lon
+	for _, v := range p[1:] {
+		south = min(south, v.Lat)
+		north = max(north, v.Lat)
+		west = min(west, v.Lon)
+		east = max(east, v.Lon)
+	}
+	return south, west, north, east
+}
+
+// Contains reports whether pt lies strictly inside p, using the even-odd
+// rule. Points exactly on an edge may be reported either way.
+func (p Polygon) Contains(pt Point) bool {
+	inside := false
+	n := len(p)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Lat > pt.Lat) != (b.Lat > pt.Lat) {
+			lon := a.Lon + (pt.Lat-a.Lat)*(b.Lon-a.Lon)/(b.Lat-a.Lat)
+			if pt.Lon < lon {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// IntersectsRect reports whether p and the box between the given edges
+// overlap.
+func (p Polygon) IntersectsRect(south, west, north, east float64) bool {
+	if len(p) == 0 {
+		return false
+	}
+	ps, pw, pn, pe := p.Bounds()
+	if ps > north || pn < south || pw > east || pe < west {
+		return false
+	}
+	// A polygon vertex inside the box.
+	for _, v := range p {
+		if v.Lat >= south && v.Lat <= north && v.Lon >= west && v.Lon <= east {
+			return true
+		}
+	}
+	// A box corner inside the polygon.
+	rect := Rectangle(south, west, north, east)
+	for _, c := range rect {
+		if p.Contains(c) {
+			return true
+		}
+	}
+	// Crossing edges.
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		for j := range rect {
+			if segmentsIntersect(a, b, rect[j], rect[(j+1)%len(rect)]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DistanceToEdge returns the great-circle distance in meters from pt to the
+// nearest edge of p.
+func (p Polygon) DistanceToEdge(pt Point) float64 {
+	d := math.Inf(1)
+	for i := range p {
+		d = math.Min(d, DistanceToSegment(pt, p[i], p[(i+1)%len(p)]))
+	}
+	return d
+}
+
+// segmentsIntersect reports whether segments ab and cd intersect, treating
+// coordinates as planar.
+func segmentsIntersect(a, b, c, d Point) bool {
+	d1 := cross(c, d, a)
+	d2 := cross(c, d, b)
+	d3 := cross(a, b, c)
+	d4 := cross(a, b, d)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return (d1 == 0 && onSegment(c, d, a)) || (d2 == 0 && onSegment(c, d, b)) ||
+		(d3 == 0 && onSegment(a, b, c)) || (d4 == 0 && onSegment(a, b, d))
+}
+
+// cross returns the z component of (b-a) × (c-a).
+func cross(a, b, c Point) float64 {
+	return (b.Lon-a.Lon)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lon-a.Lon)
+}
+
+// onSegment reports whether c, known to be collinear with ab, lies on ab.
+func onSegment(a, b, c Point) bool {
+	return min(a.Lon, b.Lon) <= c.Lon && c.Lon <= max(a.Lon, b.Lon) &&
+		min(a.Lat, b.Lat) <= c.Lat && c.Lat <= max(a.Lat, b.Lat)
+}
@@ -0,0 +1,221 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Region is an area that can be tested for containment and covered with
+// latitude/longitude boxes. Polygon, MultiPolygon, Circle and Corridor
+// implement Region.
+type Region interface {
+	// Validate reports whether the region is well formed.
+	Validate() error
+
+	// Bounds returns the smallest latitude/longitude box containing the
+	// region.
+	Bounds() (south, west, north, east float64)
+
+	// Contains reports whether pt lies strictly inside the region.
+	Contains(pt Point) bool
+
+	// IntersectsRect reports whether the region may overlap the box between
+	// the given edges. It may return false positives but never false
+	// negatives.
+	IntersectsRect(south, west, north, east float64) bool
+
+	// DistanceToEdge returns the distance in meters from pt to the nearest
+	// point on the region's boundary, whether pt is inside or outside.
+	DistanceToEdge(pt Point) float64
+}
+
+var (
+	_ Region = Polygon(nil)
+	_ Region = MultiPolygon(nil)
+	_ Region = Circle{}
+	_ Region = Corridor{}
+)
+
+// MultiPolygon is a set of rings combined with the even-odd rule: a point is
+// inside when it falls inside an odd number of rings. This covers both
+// GeoJSON polygons with holes (the holes are inner rings) and GeoJSON
+// multi-polygons whose members do not overlap.
+type MultiPolygon []Polygon
+
+// Validate reports whether m has at least one ring and every ring is valid.
+func (m MultiPolygon) Validate() error {
+	if len(m) == 0 {
+		return fmt.Errorf("geo: multipolygon has no rings")
+	}
+	for i, ring := range m {
+		if err := ring.Validate(); err != nil {
+			return fmt.Errorf("geo: ring %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Bounds returns the smallest latitude/longitude box containing every ring.
+func (m MultiPolygon) Bounds() (south, west, north, east float64) {
+	for i, ring := range m {
+		s, w, n, e := ring.Bounds()
+		if i == 0 {
+			south, west, north, east = s, w, n, e
+			continue
+		}
+		south, west = min(south, s), min(west, w)
+		north, east = max(north, n), max(east, e)
+	}
+	return south, west, north, east
+}
+
+// Contains reports whether pt lies inside an odd number of rings.
+func (m MultiPolygon) Contains(pt Point) bool {
+	inside := false
+	for _, ring := range m {
+		if ring.Contains(pt) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// IntersectsRect reports whether any ring overlaps the box. A box lying
+// entirely within a hole is still reported.
+func (m MultiPolygon) IntersectsRect(south, west, north, east float64) bool {
+	for _, ring := range m {
+		if ring.IntersectsRect(south, west, north, east) {
+			return true
+		}
+	}
+	return false
+}
+
+// DistanceToEdge returns the distance in meters from pt to the nearest ring
+// edge.
+func (m MultiPolygon) DistanceToEdge(pt Point) float64 {
+	d := math.Inf(1)
+	for _, ring := range m {
+		d = math.Min(d, ring.DistanceToEdge(pt))
+	}
+	return d
+}
+
+// Circle is the area within Radius meters of Center.
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+// Validate reports whether c has a valid center and a positive radius.
+func (c Circle) Validate() error {
+	if !(c.Radius > 0) || math.IsInf(c.Radius, 0) {
+		return fmt.Errorf("geo: circle radius must be positive, got %g", c.Radius)
+	}
+	return c.Center.validate()
+}
+
+// Bounds returns the smallest latitude/longitude box containing c, clamped
+// to the valid coordinate ranges.
+func (c Circle) Bounds() (south, west, north, east float64) {
+	return Corridor{Buffer: c.Radius}.expand(c.Center.Lat, c.Center.Lon, c.Center.Lat, c.Center.Lon)
+}
+
+// Contains reports whether pt lies less than Radius meters from Center.
+func (c Circle) Contains(pt Point) bool {
+	return Distance(c.Center, pt) < c.Radius
+}
+
+// IntersectsRect reports whether the box overlaps the bounds of c.
+func (c Circle) IntersectsRect(south, west, north, east float64) bool {
+	s, w, n, e := c.Bounds()
+	return s <= north && n >= south && w <= east && e >= west
+}
+
+// DistanceToEdge returns the distance in meters from pt to the circle.
+func (c Circle) DistanceToEdge(pt Point) float64 {
+	return math.Abs(Distance(c.Center, pt) - c.Radius)
+}
+
+// Corridor is the area within Buffer meters of a polyline, such as a
+// shipping lane or a planned route. Distances are great-circle distances.
+type Corridor struct {
+	Line   []Point
+	Buffer float64
+}
+
+// Validate reports whether c has at least two points, a positive buffer,
+// and coordinates within range.
+func (c Corridor) Validate() error {
+	if len(c.Line) < 2 {
+		return fmt.Errorf("geo: corridor line must have at least 2 points, got %d", len(c.Line))
+	}
+	if !(c.Buffer > 0) || math.IsInf(c.Buffer, 0) {
+		return fmt.Errorf("geo: corridor buffer must be positive, got %g", c.Buffer)
+	}
+	for _, p := range c.Line {
+		if err := p.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bounds returns the bounds of the line expanded by the buffer, clamped to
+// the valid coordinate ranges.
+func (c Corridor) Bounds() (south, west, north, east float64) {
+	south, west, north, east = Polygon(c.Line).Bounds()
+	return c.expand(south, west, north, east)
+}
+
+// Contains reports whether pt lies less than Buffer meters from the line.
+func (c Corridor) Contains(pt Point) bool {
+	if len(c.Line) == 1 {
+		return Distance(pt, c.Line[0]) < c.Buffer
+	}
+	for i := 1; i < len(c.Line); i++ {
+		if DistanceToSegment(pt, c.Line[i-1], c.Line[i]) < c.Buffer {
+			return true
+		}
+	}
+	return false
+}
+
+// IntersectsRect reports whether the box overlaps the buffered bounds of any
+// segment of the line.
+func (c Corridor) IntersectsRect(south, west, north, east float64) bool {
+	for i := 1; i < len(c.Line); i++ {
+		s, w, n, e := Polygon(c.Line[i-1 : i+1]).Bounds()
+		s, w, n, e = c.expand(s, w, n, e)
+		if s <= north && n >= south && w <= east && e >= west {
+			return true
+		}
+	}
+	return false
+}
+
+// DistanceToEdge returns the distance in meters from pt to the edge of the
+// buffered line.
+func (c Corridor) DistanceToEdge(pt Point) float64 {
+	d := math.Inf(1)
+	for i := 1; i < len(c.Line); i++ {
+		d = math.Min(d, DistanceToSegment(pt, c.Line[i-1], c.Line[i]))
+	}
+	if len(c.Line) == 1 {
+		d = Distance(pt, c.Line[0])
+	}
+	return math.Abs(d - c.Buffer)
+}
+
+// expand grows a box by the corridor buffer in every direction.
+func (c Corridor) expand(south, west, north, east float64) (float64, float64, float64, float64) {
+	dLat := degrees(c.Buffer / EarthRadius)
+	south = math.Max(south-dLat, -90)
+	north = math.Min(north+dLat, 90)
+	widest := math.Max(math.Abs(south), math.Abs(north))
+	if widest >= 90 {
+		return south, -180, north, 180
+	}
+	dLon := dLat / math.Cos(radians(widest))
+	return south, math.Max(west-dLon, -180), north, math.Min(east+dLon, 180)
+}
@@ -0,0 +1,79 @@
+package vesselapi
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+func TestPositionMotion_MissingCourseOrSpeed(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  VesselPosition
+	}{
+		{"nil sog", VesselPosition{Latitude: Ptr(1.0), Longitude: Ptr(2.0), Cog: Ptr(float32(90))}},
+		{"nil cog", VesselPosition{Latitude: Ptr(1.0), Longitude: Ptr(2.0), Sog: Ptr(float32(12))}},
+		{"sog not available", VesselPosition{Latitude: Ptr(1.0), Longitude: Ptr(2.0), Sog: Ptr(float32(102.3)), Cog: Ptr(float32(90))}},
+		{"cog not available", VesselPosition{Latitude: Ptr(1.0), Longitude: Ptr(2.0), Sog: Ptr(float32(12)), Cog: Ptr(float32(360))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := PositionMotion(tt.pos)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if m.Speed != 0 {
+				t.Errorf("expected stationary motion, got speed %f", m.Speed)
+			}
+			if m.Position != (geo.Point{Lat: 1, Lon: 2}) {
+				t.Errorf("unexpected position %+v", m.Position)
+			}
+		})
+	}
+}
+
+func TestPositionMotion_NoCoordinates(t *testing.T) {
+	if _, err := PositionMotion(VesselPosition{Latitude: Ptr(1.0)}); !errors.Is(err, ErrNoPosition) {
+		t.Errorf("expected ErrNoPosition, got %v", err)
+	}
+	if _, ok := PositionPoint(VesselPosition{Longitude: Ptr(1.0)}); ok {
+		t.Error("expected PositionPoint to report missing coordinates")
+	}
+}
+
+func TestClosestApproach_AlignsTimestamps(t *testing.T) {
+	// a reported 30 minutes before b while steaming east at 10 knots; by b's
+	// timestamp a has moved 5 NM and sits 5 NM west of b, closing at 20 knots.
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	bPoint := geo.Destination(geo.Point{}, 90, geo.NMToMeters(10))
+	a := VesselPosition{
+		Latitude: Ptr(0.0), Longitude: Ptr(0.0),
+		Sog: Ptr(float32(10)), Cog: Ptr(float32(90)),
+		Timestamp: Ptr(base.Add(-30 * time.Minute).Format(time.RFC3339)),
+	}
+	b := VesselPosition{
+		Latitude: Ptr(bPoint.Lat), Longitude: Ptr(bPoint.Lon),
+		Sog: Ptr(float32(10)), Cog: Ptr(float32(270)),
+		Timestamp: Ptr(base.Format(time.RFC3339)),
+	}
+	cpa, err := ClosestApproach(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(cpa.TCPA.Minutes()-15) > 0.1 {
+		t.Errorf("expected TCPA of 15 minutes, got %s", cpa.TCPA)
+	}
+	if cpa.DistanceNM > 0.01 {
+		t.Errorf("expected near-zero CPA, got %f NM", cpa.DistanceNM)
+	}
+}
+
+func TestClosestApproach_MissingPosition(t *testing.T) {
+	a := VesselPosition{Latitude: Ptr(0.0), Longitude: Ptr(0.0)}
+	if _, err := ClosestApproach(a, VesselPosition{}); !errors.Is(err, ErrNoPosition) {
+		t.Errorf("expected ErrNoPosition, got %v", err)
+	}
+}
@@ -0,0 +1,414 @@
+package vesselapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// GeofenceEventType identifies the kind of transition a GeofenceEvent
+// reports.
+type GeofenceEventType string
+
+const (
+	// GeofenceEnter reports a vessel entering a zone. A vessel already
+	// inside a zone when first observed is reported with Initial set.
+	GeofenceEnter GeofenceEventType = "enter"
+
+	// GeofenceExit reports a vessel leaving a zone.
+	GeofenceExit GeofenceEventType = "exit"
+
+	// GeofenceDwell reports a vessel that has stayed in a zone for longer
+	// than the zone's MaxDwell. It is emitted once per visit.
+	GeofenceDwell GeofenceEventType = "dwell_exceeded"
+
+	// GeofenceError reports a failed poll. The monitor keeps running.
+	GeofenceError GeofenceEventType = "error"
+)
+
+// Geofence is a named zone to monitor.
+type Geofence struct {
+	// Name identifies the zone in events and must be unique.
+	Name string
+
+	// Area is the zone itself, typically a geo.Polygon, geo.MultiPolygon
+	// or geo.Circle.
+	Area geo.Region
+
+	// MaxDwell, if positive, emits GeofenceDwell once a vessel has been
+	// inside the zone for longer than this.
+	MaxDwell time.Duration
+}
+
+// GeofenceEvent is a zone transition detected by a GeofenceMonitor.
+type GeofenceEvent struct {
+	Type GeofenceEventType
+
+	// Fence is the name of the zone. It is empty for GeofenceError.
+	Fence string
+
+	// ID is the vessel's IMO or MMSI number, following the monitor's
+	// IDType.
+	ID int
+
+	// Position is the report that caused the transition. For an exit
+	// detected because a vessel no longer appears in a zone query, it is
+	// the last report seen inside the zone.
+	Position VesselPosition
+
+	// At is the time of the transition: the report timestamp when
+	// available, otherwise the time it was observed.
+	At time.Time
+
+	// Since is when the vessel entered the zone, for exit and dwell events.
+	Since time.Time
+
+	// Initial marks an enter event for a vessel that was already inside
+	// the zone when first observed.
+	Initial bool
+
+	// Err is the poll error for GeofenceError events.
+	Err error
+}
+
+// Dwell returns the time the vessel spent in the zone up to the event, or
+// zero for enter events.
+func (e GeofenceEvent) Dwell() time.Duration {
+	if e.Since.IsZero() {
+		return 0
+	}
+	return e.At.Sub(e.Since)
+}
+
+// GeofenceOptions configures a GeofenceMonitor. A nil *GeofenceOptions uses
+// the defaults.
+type GeofenceOptions struct {
+	// IDs is the fleet to monitor. When set, positions are polled with
+	// Vessels.PositionsBatch and checked against every zone. When empty,
+	// every vessel inside the zones is monitored by polling
+	// Location.VesselsInArea for each zone, and vessels are identified by
+	// MMSI.
+	IDs []int
+
+	// IDType selects whether IDs are IMO (default) or MMSI numbers.
+	IDType GetVesselsPositionsParamsFilterIdType
+
+	// Margin is the hysteresis band in meters: a vessel must be at least
+	// this far inside a zone's edge to enter it, and this far outside to
+	// leave it, so that positions jittering along the border do not flap.
+	// When IDs is empty, zones are queried grown by the margin so that
+	// vessels just outside the edge are still seen.
+	Margin float64
+
+	// Interval is the time between polls. Defaults to one minute.
+	Interval time.Duration
+
+	// Jitter randomizes each interval by up to this fraction in either
+	// direction. Defaults to 0.1; set a negative value to disable.
+	Jitter float64
+
+	// Buffer is the capacity of the events channel.
+	Buffer int
+
+	// Area configures the zone queries used when IDs is empty.
+	Area *AreaOptions
+}
+
+// GeofenceMonitor tracks which vessels are inside which zones and emits
+// enter, exit and dwell events. Run polls the API; Observe can also be fed
+// positions from any other source.
+type GeofenceMonitor struct {
+	client *VesselClient
+	fences []Geofence
+	opts   GeofenceOptions
+	events chan GeofenceEvent
+	now    func() time.Time
+
+	mu      sync.Mutex
+	running bool
+	state   map[fenceKey]*fenceState
+}
+
+// fenceKey identifies one vessel in one zone.
+type fenceKey struct {
+	fence string
+	id    int
+}
+
+// fenceState is the monitor's view of one vessel in one zone.
+type fenceState struct {
+	inside  bool
+	since   time.Time
+	dwelled bool
+	last    VesselPosition
+}
+
+// NewGeofenceMonitor returns a monitor for the given zones. It returns an
+// error if a zone has no name, a duplicate name or an invalid area.
+func NewGeofenceMonitor(client *VesselClient, fences []Geofence, opts *GeofenceOptions) (*GeofenceMonitor, error) {
+	o := GeofenceOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.IDType == "" {
+		o.IDType = GetVesselsPositionsParamsFilterIdTypeImo
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultWatchInterval
+	}
+	if o.Jitter == 0 {
+		o.Jitter = defaultWatchJitter
+	}
+	seen := make(map[string]bool, len(fences))
+	for _, f := range fences {
+		if f.Name == "" {
+			return nil, errors.New("vesselapi: geofence has no name")
+		}
+		if seen[f.Name] {
+			return nil, fmt.Errorf("vesselapi: duplicate geofence %q", f.Name)
+		}
+		seen[f.Name] = true
+		if f.Area == nil {
+			return nil, fmt.Errorf("vesselapi: geofence %q has no area", f.Name)
+		}
+		if err := f.Area.Validate(); err != nil {
+			return nil, fmt.Errorf("vesselapi: geofence %q: %w", f.Name, err)
+		}
+	}
+	return &GeofenceMonitor{
+		client: client,
+		fences: append([]Geofence(nil), fences...),
+		opts:   o,
+		events: make(chan GeofenceEvent, max(o.Buffer, 0)),
+		now:    time.Now,
+		state:  make(map[fenceKey]*fenceState),
+	}, nil
+}
+
+// Events returns the channel on which Run delivers events. It is closed
+// when Run returns.
+func (m *GeofenceMonitor) Events() <-chan GeofenceEvent {
+	return m.events
+}
+
+// Inside returns the names of the zones the vessel is currently inside, in
+// the order the zones were given.
+func (m *GeofenceMonitor) Inside(id int) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for _, f := range m.fences {
+		if st := m.state[fenceKey{f.Name, id}]; st != nil && st.inside {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// Observe checks one position report for the vessel against every zone and
+// returns the resulting events. Reports older than one already observed
+// for the vessel are ignored.
+func (m *GeofenceMonitor) Observe(id int, p VesselPosition) []GeofenceEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var events []GeofenceEvent
+	for _, f := range m.fences {
+		events = append(events, m.observe(f, id, p)...)
+	}
+	return events
+}
+
+// observe checks p against one zone. The caller must hold m.mu.
+func (m *GeofenceMonitor) observe(f Geofence, id int, p VesselPosition) []GeofenceEvent {
+	pt, ok := PositionPoint(p)
+	if !ok {
+		return nil
+	}
+	key := fenceKey{f.Name, id}
+	st, known := m.state[key]
+	if known && newerPosition(st.last, p) {
+		return nil
+	}
+	at := m.reportTime(p)
+	contains := f.Area.Contains(pt)
+	clear := m.opts.Margin <= 0 || f.Area.DistanceToEdge(pt) >= m.opts.Margin
+
+	if !known {
+		st = &fenceState{}
+		m.state[key] = st
+	}
+	st.last = p
+
+	var events []GeofenceEvent
+	switch {
+	case !st.inside && contains && clear:
+		st.inside, st.since, st.dwelled = true, at, false
+		events = append(events, GeofenceEvent{Type: GeofenceEnter, Fence: f.Name, ID: id, Position: p, At: at, Initial: !known})
+	case st.inside && !contains && clear:
+		st.inside = false
+		events = append(events, GeofenceEvent{Type: GeofenceExit, Fence: f.Name, ID: id, Position: p, At: at, Since: st.since})
+	}
+	if st.inside && !st.dwelled && f.MaxDwell > 0 && at.Sub(st.since) > f.MaxDwell {
+		st.dwelled = true
+		events = append(events, GeofenceEvent{Type: GeofenceDwell, Fence: f.Name, ID: id, Position: p, At: at, Since: st.since})
+	}
+	return events
+}
+
+// reportTime returns the timestamp of p, or the current time if it has
+// none.
+func (m *GeofenceMonitor) reportTime(p VesselPosition) time.Time {
+	if ts, err := parseTimestamp(Deref(p.Timestamp)); err == nil {
+		return ts
+	}
+	return m.now()
+}
+
+// Run polls immediately and then every Interval until ctx is cancelled,
+// then closes the events channel and returns nil. Run may only be called
+// once.
+func (m *GeofenceMonitor) Run(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return errors.New("vesselapi: geofence monitor already running")
+	}
+	m.running = true
+	m.mu.Unlock()
+	defer close(m.events)
+
+	for {
+		for _, ev := range m.poll(ctx) {
+			select {
+			case m.events <- ev:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		if sleepCtx(ctx, jitterDuration(m.opts.Interval, m.opts.Jitter)) != nil {
+			return nil
+		}
+	}
+}
+
+// poll fetches positions and returns the resulting events.
+func (m *GeofenceMonitor) poll(ctx context.Context) []GeofenceEvent {
+	var events []GeofenceEvent
+	fail := func(fence string, err error) {
+		if ctx.Err() == nil {
+			events = append(events, GeofenceEvent{Type: GeofenceError, Fence: fence, At: m.now(), Err: err})
+		}
+	}
+
+	if len(m.opts.IDs) > 0 {
+		res, err := m.client.Vessels.PositionsBatch(ctx, m.opts.IDs, &PositionsBatchOptions{IDType: m.opts.IDType})
+		if err != nil {
+			fail("", err)
+			return events
+		}
+		latest := latestByID(res.Positions, m.opts.IDType)
+		ids := make([]int, 0, len(latest))
+		for id := range latest {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			events = append(events, m.Observe(id, latest[id])...)
+		}
+		return events
+	}
+
+	for _, f := range m.fences {
+		// Query the zone grown by the margin, so that vessels just outside
+		// its edge are still reported and observe decides when they leave.
+		area := f.Area
+		if m.opts.Margin > 0 {
+			area = marginRegion{Region: f.Area, margin: m.opts.Margin}
+		}
+		positions, err := m.client.Location.VesselsInArea(ctx, area, m.opts.Area)
+		if err != nil {
+			fail(f.Name, err)
+			continue
+		}
+		events = append(events, m.observeArea(f, positions)...)
+	}
+	return events
+}
+
+// observeArea applies the result of a zone query grown by the margin:
+// every returned vessel is observed, so the margin decides whether a vessel
+// near the edge has left, and vessels inside the zone that were not
+// returned at all have left. Positions without an MMSI are skipped, since
+// they cannot be told apart.
+func (m *GeofenceMonitor) observeArea(f Geofence, positions []VesselPosition) []GeofenceEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var events []GeofenceEvent
+	present := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		id := Deref(p.Mmsi)
+		if id == 0 {
+			continue
+		}
+		present[id] = true
+		events = append(events, m.observe(f, id, p)...)
+	}
+
+	var gone []int
+	for key, st := range m.state {
+		if key.fence == f.Name && st.inside && !present[key.id] {
+			gone = append(gone, key.id)
+		}
+	}
+	sort.Ints(gone)
+	now := m.now()
+	for _, id := range gone {
+		st := m.state[fenceKey{f.Name, id}]
+		st.inside = false
+		events = append(events, GeofenceEvent{Type: GeofenceExit, Fence: f.Name, ID: id, Position: st.last, At: now, Since: st.since})
+	}
+	return events
+}
+
+// marginRegion is a region grown by margin meters beyond its edge.
+type marginRegion struct {
+	geo.Region
+	margin float64
+}
+
+// Bounds returns the region's bounds grown by the margin.
+func (r marginRegion) Bounds() (south, west, north, east float64) {
+	return r.grow(r.Region.Bounds())
+}
+
+// Contains reports whether pt lies inside the region or less than the
+// margin outside it.
+func (r marginRegion) Contains(pt geo.Point) bool {
+	return r.Region.Contains(pt) || r.Region.DistanceToEdge(pt) < r.margin
+}
+
+// IntersectsRect reports whether the box grown by the margin may overlap
+// the region.
+func (r marginRegion) IntersectsRect(south, west, north, east float64) bool {
+	return r.Region.IntersectsRect(r.grow(south, west, north, east))
+}
+
+// DistanceToEdge returns the distance in meters from pt to the grown edge.
+func (r marginRegion) DistanceToEdge(pt geo.Point) float64 {
+	d := r.Region.DistanceToEdge(pt)
+	if r.Region.Contains(pt) {
+		return d + r.margin
+	}
+	return math.Abs(d - r.margin)
+}
+
+// grow expands a box by the margin in every direction.
+func (r marginRegion) grow(south, west, north, east float64) (float64, float64, float64, float64) {
+	return geo.Corridor{Line: []geo.Point{{Lat: south, Lon: west}, {Lat: north, Lon: east}}, Buffer: r.margin}.Bounds()
+}
@@ -0,0 +1,231 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+func fencePos(ts string, lat, lon float64) VesselPosition {
+	return VesselPosition{Mmsi: Ptr(1), Timestamp: Ptr(ts), Latitude: Ptr(lat), Longitude: Ptr(lon)}
+}
+
+func TestGeofenceMonitor_EnterExitDwell(t *testing.T) {
+	m, err := NewGeofenceMonitor(nil, []Geofence{
+		{Name: "anchorage", Area: geo.Rectangle(0, 0, 1, 1), MaxDwell: 2 * time.Hour},
+		{Name: "port", Area: geo.Circle{Center: geo.Point{Lat: 0.5, Lon: 0.5}, Radius: 5_000}},
+	}, &GeofenceOptions{Margin: 1_000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	steps := []struct {
+		pos  VesselPosition
+		want []GeofenceEventType
+	}{
+		// Outside everything.
+		{fencePos("2025-01-01T00:00:00Z", -0.5, 0.5), nil},
+		// Just inside the edge, within the margin: not yet entered.
+		{fencePos("2025-01-01T00:10:00Z", 0.005, 0.5), nil},
+		{fencePos("2025-01-01T00:20:00Z", 0.2, 0.5), []GeofenceEventType{GeofenceEnter}},
+		// Stale report: ignored.
+		{fencePos("2025-01-01T00:15:00Z", -0.5, 0.5), nil},
+		// Into the port circle.
+		{fencePos("2025-01-01T01:00:00Z", 0.5, 0.5), []GeofenceEventType{GeofenceEnter}},
+		// Events follow zone order: the anchorage dwell, then the port exit.
+		{fencePos("2025-01-01T02:30:00Z", 0.2, 0.5), []GeofenceEventType{GeofenceDwell, GeofenceExit}},
+		// Jitter across the border within the margin: no flapping.
+		{fencePos("2025-01-01T02:40:00Z", -0.005, 0.5), nil},
+		{fencePos("2025-01-01T02:50:00Z", 0.005, 0.5), nil},
+		{fencePos("2025-01-01T03:00:00Z", -0.5, 0.5), []GeofenceEventType{GeofenceExit}},
+	}
+	for i, step := range steps {
+		events := m.Observe(1, step.pos)
+		if len(events) != len(step.want) {
+			t.Fatalf("step %d: expected %v, got %+v", i, step.want, events)
+		}
+		for j, ev := range events {
+			if ev.Type != step.want[j] {
+				t.Errorf("step %d: expected %s, got %s", i, step.want[j], ev.Type)
+			}
+		}
+	}
+
+	if got := m.Inside(1); len(got) != 0 {
+		t.Errorf("expected vessel outside all zones, got %v", got)
+	}
+}
+
+func TestGeofenceMonitor_InitialEnter(t *testing.T) {
+	m, err := NewGeofenceMonitor(nil, []Geofence{{Name: "zone", Area: geo.Rectangle(0, 0, 1, 1)}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	events := m.Observe(7, fencePos("2025-01-01T00:00:00Z", 0.5, 0.5))
+	if len(events) != 1 || events[0].Type != GeofenceEnter || !events[0].Initial {
+		t.Fatalf("expected initial enter, got %+v", events)
+	}
+	if !events[0].At.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected report timestamp, got %s", events[0].At)
+	}
+	events = m.Observe(7, fencePos("2025-01-01T03:00:00Z", 2, 2))
+	if len(events) != 1 || events[0].Type != GeofenceExit || events[0].Dwell() != 3*time.Hour {
+		t.Errorf("expected exit after 3h, got %+v", events)
+	}
+	if got := m.Inside(7); got != nil {
+		t.Errorf("expected no zones, got %v", got)
+	}
+}
+
+func TestNewGeofenceMonitor_Invalid(t *testing.T) {
+	for name, fences := range map[string][]Geofence{
+		"no name":   {{Area: geo.Rectangle(0, 0, 1, 1)}},
+		"duplicate": {{Name: "a", Area: geo.Rectangle(0, 0, 1, 1)}, {Name: "a", Area: geo.Rectangle(0, 0, 1, 1)}},
+		"no area":   {{Name: "a"}},
+		"bad area":  {{Name: "a", Area: geo.Circle{}}},
+	} {
+		if _, err := NewGeofenceMonitor(nil, fences, nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestGeofenceMonitor_RunAreaMode(t *testing.T) {
+	// The first zone query sees the vessel, the second does not.
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/location/vessels/bounding-box" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		polls++
+		vessels := []VesselPosition{}
+		if polls == 1 {
+			vessels = append(vessels, fencePos("2025-01-01T00:00:00Z", 0.5, 0.5))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(VesselsWithinLocationResponse{Vessels: &vessels})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, err := NewGeofenceMonitor(vc, []Geofence{{Name: "zone", Area: geo.Rectangle(0, 0, 0.8, 0.8)}}, &GeofenceOptions{
+		Interval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go m.Run(ctx) //nolint:errcheck
+
+	if ev := <-m.Events(); ev.Type != GeofenceEnter || ev.ID != 1 || ev.Fence != "zone" {
+		t.Errorf("expected enter, got %+v", ev)
+	}
+	if ev := <-m.Events(); ev.Type != GeofenceExit || ev.ID != 1 {
+		t.Errorf("expected exit once the vessel is no longer reported, got %+v", ev)
+	}
+	cancel()
+	for range m.Events() {
+	}
+}
+
+func TestGeofenceMonitor_AreaModeMargin(t *testing.T) {
+	// The server honours the bounding box, like the API.
+	var vessels []VesselPosition
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		num := func(k string) float64 {
+			v, _ := strconv.ParseFloat(q.Get(k), 64)
+			return v
+		}
+		inBox := []VesselPosition{}
+		for _, p := range vessels {
+			lat, lon := Deref(p.Latitude), Deref(p.Longitude)
+			if lat >= num("filter.latBottom") && lat <= num("filter.latTop") && lon >= num("filter.lonLeft") && lon <= num("filter.lonRight") {
+				inBox = append(inBox, p)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(VesselsWithinLocationResponse{Vessels: &inBox})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, err := NewGeofenceMonitor(vc, []Geofence{{Name: "zone", Area: geo.Rectangle(0, 0, 1, 1)}}, &GeofenceOptions{Margin: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	// A position without an MMSI is skipped.
+	vessels = []VesselPosition{
+		fencePos("2025-01-01T00:00:00Z", 0.5, 0.5),
+		{Timestamp: Ptr("2025-01-01T00:00:00Z"), Latitude: Ptr(0.5), Longitude: Ptr(0.6)},
+	}
+	if evs := m.poll(ctx); len(evs) != 1 || evs[0].Type != GeofenceEnter || evs[0].ID != 1 {
+		t.Fatalf("expected one enter for MMSI 1, got %+v", evs)
+	}
+	if got := m.Inside(0); len(got) != 0 {
+		t.Errorf("expected no state for a position without an MMSI, got %v", got)
+	}
+
+	// About 556 m outside the edge, within the margin: still inside.
+	vessels = []VesselPosition{fencePos("2025-01-01T00:10:00Z", 1.005, 0.5)}
+	if evs := m.poll(ctx); len(evs) != 0 {
+		t.Errorf("expected no exit within the margin, got %+v", evs)
+	}
+
+	// About 2.2 km outside, beyond the grown query: gone.
+	vessels = []VesselPosition{fencePos("2025-01-01T00:20:00Z", 1.02, 0.5)}
+	if evs := m.poll(ctx); len(evs) != 1 || evs[0].Type != GeofenceExit {
+		t.Errorf("expected exit beyond the margin, got %+v", evs)
+	}
+}
+
+func TestGeofenceMonitor_RunFleetMode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/vessels/positions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(VesselPositionsResponse{VesselPositions: &[]VesselPosition{
+			{Imo: Ptr(9811000), Latitude: Ptr(0.5), Longitude: Ptr(0.5), Timestamp: Ptr("2025-01-01T00:00:00Z")},
+		}})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, err := NewGeofenceMonitor(vc, []Geofence{{Name: "zone", Area: geo.Rectangle(0, 0, 1, 1)}}, &GeofenceOptions{
+		IDs:      []int{9811000},
+		Interval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Run(ctx) }()
+	if ev := <-m.Events(); ev.Type != GeofenceEnter || ev.ID != 9811000 {
+		t.Errorf("expected enter for IMO 9811000, got %+v", ev)
+	}
+	cancel()
+	for range m.Events() {
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package vesselapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// Locatable is the set of models that carry coordinates.
+type Locatable interface {
+	VesselPosition | Port | DGPSStation | LightAid | MODU | RadioBeacon
+}
+
+// LocatablePoint returns the coordinates of v, read from its Latitude and
+// Longitude fields when present and otherwise from its GeoJSON Location. It
+// reports false if v has no coordinates.
+func LocatablePoint[T Locatable](v T) (geo.Point, bool) {
+	switch v := any(v).(type) {
+	case VesselPosition:
+		if pt, ok := PositionPoint(v); ok {
+			return pt, true
+		}
+		return locationPoint(v.Location)
+	case Port:
+		return portPoint(v)
+	case DGPSStation:
+		return locationPoint(v.Location)
+	case LightAid:
+		return locationPoint(v.Location)
+	case MODU:
+		if v.Latitude != nil && v.Longitude != nil {
+			return geo.Point{Lat: *v.Latitude, Lon: *v.Longitude}, true
+		}
+		return locationPoint(v.Location)
+	case RadioBeacon:
+		return locationPoint(v.Location)
+	}
+	return geo.Point{}, false
+}
+
+// geoJSONFeature is a GeoJSON Feature.
+type geoJSONFeature struct {
+	Type       string           `json:"type"`
+	Geometry   *geoJSONGeometry `json:"geometry"`
+	Properties map[string]any   `json:"properties"`
+}
+
+// geoJSONGeometry is a GeoJSON Point or LineString.
+type geoJSONGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// GeoJSONWriter streams a GeoJSON FeatureCollection with one Point feature
+// per item. Every attribute of an item except its Location becomes a
+// property; items without coordinates are written with a null geometry.
+//
+// Nothing is written until the first call to Write or Close, and the
+// collection is only complete once Close has been called.
+type GeoJSONWriter[T Locatable] struct {
+	doc docWriter
+	n   int
+}
+
+// NewGeoJSONWriter returns a GeoJSONWriter that writes to w.
+func NewGeoJSONWriter[T Locatable](w io.Writer) *GeoJSONWriter[T] {
+	return &GeoJSONWriter[T]{doc: docWriter{
+		w:      w,
+		format: "GeoJSON",
+		header: `{"type":"FeatureCollection","features":[`,
+		footer: "]}\n",
+	}}
+}
+
+// Write appends v to the collection.
+func (gw *GeoJSONWriter[T]) Write(v T) error {
+	feature, err := locatableFeature(v)
+	if err != nil {
+		return err
+	}
+	return gw.writeFeature(feature)
+}
+
+// Count returns the number of features written.
+func (gw *GeoJSONWriter[T]) Count() int {
+	return gw.n
+}
+
+// Close ends the collection. It does not close the underlying writer.
+func (gw *GeoJSONWriter[T]) Close() error {
+	return gw.doc.close()
+}
+
+func (gw *GeoJSONWriter[T]) writeFeature(f geoJSONFeature) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("vesselapi: encoding GeoJSON feature: %w", err)
+	}
+	if gw.n > 0 {
+		data = append([]byte{','}, data...)
+	}
+	if err := gw.doc.element(data); err != nil {
+		return err
+	}
+	gw.n++
+	return nil
+}
+
+// WriteGeoJSON writes items to w as a GeoJSON FeatureCollection.
+func WriteGeoJSON[T Locatable](w io.Writer, items []T) error {
+	gw := NewGeoJSONWriter[T](w)
+	for _, v := range items {
+		if err := gw.Write(v); err != nil {
+			return err
+		}
+	}
+	return gw.Close()
+}
+
+// CopyGeoJSON drains it into w as a GeoJSON FeatureCollection, writing each
+// page as it is fetched, and returns the number of features written. If the
+// iterator fails the collection is left unterminated and its error is
+// returned.
+func CopyGeoJSON[T Locatable](w io.Writer, it *Iterator[T]) (int, error) {
+	gw := NewGeoJSONWriter[T](w)
+	n, err := copyIterator(it, gw.Write)
+	if err != nil {
+		return n, err
+	}
+	return n, gw.Close()
+}
+
+// TrackFeature returns the track as a GeoJSON LineString Feature with the
+// given properties, plus "start" and "end" timestamps and a "times" array
+// holding the time of every coordinate. A LineString needs two positions,
+// so a single-point track is a Point Feature and an empty track has a null
+// geometry.
+func TrackFeature(t Track, properties map[string]any) ([]byte, error) {
+	data, err := json.Marshal(trackFeature(t, properties))
+	if err != nil {
+		return nil, fmt.Errorf("vesselapi: encoding GeoJSON feature: %w", err)
+	}
+	return data, nil
+}
+
+// WriteTracksGeoJSON writes the tracks to w as a GeoJSON FeatureCollection
+// of LineStrings, as returned by TracksByVessel, with tracks too short for
+// a line written as TrackFeature does. Each feature's "id" property holds
+// its key; features are ordered by key.
+func WriteTracksGeoJSON(w io.Writer, tracks map[string]Track) error {
+	keys := make([]string, 0, len(tracks))
+	for k := range tracks {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	gw := NewGeoJSONWriter[VesselPosition](w)
+	for _, k := range keys {
+		if err := gw.writeFeature(trackFeature(tracks[k], map[string]any{"id": k})); err != nil {
+			return err
+		}
+	}
+	return gw.Close()
+}
+
+func trackFeature(t Track, properties map[string]any) geoJSONFeature {
+	props := make(map[string]any, len(properties)+3)
+	for k, v := range properties {
+		props[k] = v
+	}
+	coords := make([][2]float64, len(t))
+	times := make([]string, len(t))
+	for i, p := range t {
+		coords[i] = [2]float64{p.Point.Lon, p.Point.Lat}
+		times[i] = p.Time.UTC().Format(time.RFC3339)
+	}
+	props["times"] = times
+	if len(t) > 0 {
+		props["start"] = times[0]
+		props["end"] = times[len(times)-1]
+	}
+	f := geoJSONFeature{Type: "Feature", Properties: props}
+	switch len(coords) {
+	case 0:
+	case 1:
+		f.Geometry = &geoJSONGeometry{Type: "Point", Coordinates: coords[0]}
+	default:
+		f.Geometry = &geoJSONGeometry{Type: "LineString", Coordinates: coords}
+	}
+	return f
+}
+
+// locatableFeature converts v to a Point feature.
+func locatableFeature[T Locatable](v T) (geoJSONFeature, error) {
+	props, err := featureProperties(v)
+	if err != nil {
+		return geoJSONFeature{}, err
+	}
+	f := geoJSONFeature{Type: "Feature", Properties: props}
+	if pt, ok := LocatablePoint(v); ok {
+		f.Geometry = &geoJSONGeometry{Type: "Point", Coordinates: [2]float64{pt.Lon, pt.Lat}}
+	}
+	return f, nil
+}
+
+// featureProperties returns the JSON attributes of v other than its
+// location. Numbers keep their exact JSON representation.
+func featureProperties(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("vesselapi: encoding GeoJSON properties: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	props := make(map[string]any)
+	if err := dec.Decode(&props); err != nil {
+		return nil, fmt.Errorf("vesselapi: encoding GeoJSON properties: %w", err)
+	}
+	delete(props, "location")
+	return props, nil
+}
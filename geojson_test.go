@@ -0,0 +1,196 @@
+package vesselapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// featureCollection is a decoded GeoJSON FeatureCollection.
+type featureCollection struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Type     string `json:"type"`
+		Geometry *struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	} `json:"features"`
+}
+
+func decodeFeatureCollection(t *testing.T, data []byte) featureCollection {
+	t.Helper()
+	var fc featureCollection
+	if err := json.Unmarshal(data, &fc); err != nil {
+		t.Fatalf("invalid GeoJSON %s: %v", data, err)
+	}
+	if fc.Type != "FeatureCollection" {
+		t.Fatalf("expected a FeatureCollection, got %q", fc.Type)
+	}
+	return fc
+}
+
+func TestWriteGeoJSON_Positions(t *testing.T) {
+	positions := []VesselPosition{
+		{Mmsi: Ptr(353136000), VesselName: Ptr("EVER GIVEN"), Latitude: Ptr(51.95), Longitude: Ptr(4.05), Sog: Ptr(float32(12.5))},
+		{Mmsi: Ptr(244000000)},
+	}
+	var buf bytes.Buffer
+	if err := WriteGeoJSON(&buf, positions); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fc := decodeFeatureCollection(t, buf.Bytes())
+	if len(fc.Features) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(fc.Features))
+	}
+
+	f := fc.Features[0]
+	if f.Type != "Feature" || f.Geometry == nil || f.Geometry.Type != "Point" {
+		t.Fatalf("expected a Point feature, got %+v", f)
+	}
+	if got := string(f.Geometry.Coordinates); got != "[4.05,51.95]" {
+		t.Errorf("expected coordinates [4.05,51.95], got %s", got)
+	}
+	if got := string(f.Properties["mmsi"]); got != "353136000" {
+		t.Errorf("expected mmsi 353136000, got %s", got)
+	}
+	if got := string(f.Properties["vessel_name"]); got != `"EVER GIVEN"` {
+		t.Errorf("expected vessel_name, got %s", got)
+	}
+	if got := string(f.Properties["sog"]); got != "12.5" {
+		t.Errorf("expected sog 12.5, got %s", got)
+	}
+
+	if fc.Features[1].Geometry != nil {
+		t.Errorf("expected a null geometry for a position without coordinates")
+	}
+}
+
+func TestWriteGeoJSON_LocationField(t *testing.T) {
+	aids := []LightAid{{
+		Name: Ptr("Maasvlakte"),
+		Location: &GithubComVesselapiCommonVesselDataContractsTypesGeoJSON{
+			Type:        Ptr("Point"),
+			Coordinates: &[]float32{4.1, 51.9},
+		},
+	}}
+	var buf bytes.Buffer
+	if err := WriteGeoJSON(&buf, aids); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fc := decodeFeatureCollection(t, buf.Bytes())
+	f := fc.Features[0]
+	if got := string(f.Geometry.Coordinates); got != "[4.1,51.9]" {
+		t.Errorf("expected float32 coordinates to round-trip as [4.1,51.9], got %s", got)
+	}
+	if _, ok := f.Properties["location"]; ok {
+		t.Errorf("expected location to be moved to the geometry, got properties %v", f.Properties)
+	}
+	if got := string(f.Properties["name"]); got != `"Maasvlakte"` {
+		t.Errorf("expected name property, got %s", got)
+	}
+}
+
+func TestWriteGeoJSON_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteGeoJSON[Port](&buf, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("unexpected output %s", got)
+	}
+}
+
+func TestGeoJSONWriter_WriteAfterClose(t *testing.T) {
+	var buf bytes.Buffer
+	gw := NewGeoJSONWriter[Port](&buf)
+	if err := gw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := gw.Write(Port{}); err == nil {
+		t.Error("expected an error writing to a closed writer")
+	}
+}
+
+func TestCopyGeoJSON(t *testing.T) {
+	pages := [][]Port{
+		{{UnloCode: Ptr("NLRTM"), Latitude: Ptr(51.95), Longitude: Ptr(4.05)}},
+		{{UnloCode: Ptr("BEANR"), Latitude: Ptr(51.25), Longitude: Ptr(4.4)}},
+	}
+	it := newIterator(func() ([]Port, *string, error) {
+		if len(pages) == 0 {
+			return nil, nil, nil
+		}
+		page := pages[0]
+		pages = pages[1:]
+		return page, Ptr("next"), nil
+	})
+	var buf bytes.Buffer
+	n, err := CopyGeoJSON(&buf, it)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 features, got %d", n)
+	}
+	if fc := decodeFeatureCollection(t, buf.Bytes()); len(fc.Features) != 2 {
+		t.Errorf("expected 2 features, got %d", len(fc.Features))
+	}
+
+	boom := errors.New("boom")
+	failing := newIterator(func() ([]Port, *string, error) { return nil, nil, boom })
+	if _, err := CopyGeoJSON(&bytes.Buffer{}, failing); !errors.Is(err, boom) {
+		t.Errorf("expected the iterator error, got %v", err)
+	}
+}
+
+func TestWriteTracksGeoJSON(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracks := map[string]Track{
+		"mmsi:2": {{Point: geo.Point{Lat: 1, Lon: 2}, Time: start}, {Point: geo.Point{Lat: 1.5, Lon: 2.5}, Time: start.Add(time.Hour)}},
+		"mmsi:1": {{Point: geo.Point{Lat: 0, Lon: 0}, Time: start}},
+	}
+	var buf bytes.Buffer
+	if err := WriteTracksGeoJSON(&buf, tracks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fc := decodeFeatureCollection(t, buf.Bytes())
+	if len(fc.Features) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(fc.Features))
+	}
+	f := fc.Features[1]
+	if string(f.Properties["id"]) != `"mmsi:2"` || f.Geometry.Type != "LineString" {
+		t.Fatalf("expected the mmsi:2 LineString second, got %+v", f)
+	}
+	if got := string(f.Geometry.Coordinates); got != "[[2,1],[2.5,1.5]]" {
+		t.Errorf("unexpected coordinates %s", got)
+	}
+	if got := string(f.Properties["end"]); got != `"2025-01-01T01:00:00Z"` {
+		t.Errorf("expected end 2025-01-01T01:00:00Z, got %s", got)
+	}
+
+	single, err := TrackFeature(tracks["mmsi:1"], map[string]any{"name": "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(single, []byte(`"geometry":{"type":"Point","coordinates":[0,0]}`)) || !bytes.Contains(single, []byte(`"name":"x"`)) {
+		t.Errorf("expected a Point feature for a single-point track, got %s", single)
+	}
+	if f := fc.Features[0]; f.Geometry.Type != "Point" || string(f.Geometry.Coordinates) != "[0,0]" {
+		t.Errorf("expected the mmsi:1 Point first, got %+v", f)
+	}
+
+	empty, err := TrackFeature(nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(empty, []byte(`"geometry":null`)) {
+		t.Errorf("expected a null geometry for an empty track, got %s", empty)
+	}
+}
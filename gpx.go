@@ -0,0 +1,123 @@
+package vesselapi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+)
+
+// GPXWriter streams a GPX 1.1 document for chartplotters and navigation
+// software. GPX requires waypoints to come before tracks, so every
+// waypoint must be written before the first track. The document is
+// complete once Close has been called.
+type GPXWriter struct {
+	doc    docWriter
+	inTrks bool
+}
+
+// NewGPXWriter returns a GPXWriter that writes to w.
+func NewGPXWriter(w io.Writer) *GPXWriter {
+	return &GPXWriter{doc: docWriter{
+		w:      w,
+		format: "GPX",
+		header: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+			`<gpx version="1.1" creator="vesselapi-go" xmlns="http://www.topografix.com/GPX/1/1">` + "\n",
+		footer: "</gpx>\n",
+	}}
+}
+
+// Close ends the document. It does not close the underlying writer.
+func (gw *GPXWriter) Close() error {
+	return gw.doc.close()
+}
+
+// WriteWaypoint adds the port as a waypoint named after the port, with its
+// UN/LOCODE as the description. Ports without coordinates are skipped.
+func (gw *GPXWriter) WriteWaypoint(p Port) error {
+	if gw.inTrks {
+		return errors.New("vesselapi: GPX waypoints must be written before tracks")
+	}
+	pt, ok := portPoint(p)
+	if !ok {
+		return nil
+	}
+	var b bytes.Buffer
+	b.WriteString(`<wpt lat="` + formatCoord(pt.Lat) + `" lon="` + formatCoord(pt.Lon) + `">`)
+	b.WriteString("<name>" + xmlEscape(Deref(p.Name)) + "</name>")
+	if p.UnloCode != nil {
+		b.WriteString("<desc>" + xmlEscape(*p.UnloCode) + "</desc>")
+	}
+	b.WriteString("<sym>Anchor</sym><type>Port</type></wpt>\n")
+	return gw.doc.element(b.Bytes())
+}
+
+// CopyWaypoints drains it into the document as waypoints, writing each page
+// as it is fetched, and returns the number of ports read.
+func (gw *GPXWriter) CopyWaypoints(it *Iterator[Port]) (int, error) {
+	return copyIterator(it, gw.WriteWaypoint)
+}
+
+// WriteTrack adds the track with the given name.
+func (gw *GPXWriter) WriteTrack(name string, t Track) error {
+	var b bytes.Buffer
+	for _, p := range t {
+		writeTrackPoint(&b, p)
+	}
+	if err := gw.beginTrack(name); err != nil {
+		return err
+	}
+	if err := gw.doc.element(b.Bytes()); err != nil {
+		return err
+	}
+	return gw.doc.element([]byte("</trkseg></trk>\n"))
+}
+
+// CopyTrack drains it into the document as a single track with the given
+// name, writing each page as it is fetched, and returns the number of
+// points written. Positions are written in the order the iterator yields
+// them; those without coordinates or a usable timestamp, or flagged as
+// SuspectedGlitch, are skipped. Use NewTrack and WriteTrack for a fully
+// cleaned track.
+//
+// If the iterator fails the document is left unterminated and its error is
+// returned.
+func (gw *GPXWriter) CopyTrack(name string, it *Iterator[VesselPosition]) (int, error) {
+	if err := gw.beginTrack(name); err != nil {
+		return 0, err
+	}
+	n := 0
+	var b bytes.Buffer
+	for it.Next() {
+		p := it.Value()
+		pt, ok := PositionPoint(p)
+		if !ok || Deref(p.SuspectedGlitch) {
+			continue
+		}
+		at, err := parseTimestamp(Deref(p.Timestamp))
+		if err != nil {
+			continue
+		}
+		b.Reset()
+		writeTrackPoint(&b, TrackPoint{Point: pt, Time: at})
+		if err := gw.doc.element(b.Bytes()); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := it.Err(); err != nil {
+		return n, err
+	}
+	return n, gw.doc.element([]byte("</trkseg></trk>\n"))
+}
+
+// beginTrack opens a track and its single segment.
+func (gw *GPXWriter) beginTrack(name string) error {
+	gw.inTrks = true
+	return gw.doc.element([]byte("<trk><name>" + xmlEscape(name) + "</name><trkseg>\n"))
+}
+
+func writeTrackPoint(b *bytes.Buffer, p TrackPoint) {
+	b.WriteString(`<trkpt lat="` + formatCoord(p.Point.Lat) + `" lon="` + formatCoord(p.Point.Lon) + `">`)
+	b.WriteString("<time>" + p.Time.UTC().Format(time.RFC3339) + "</time></trkpt>\n")
+}
@@ -0,0 +1,110 @@
+package vesselapi
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// gpxDoc is a decoded GPX document.
+type gpxDoc struct {
+	Waypoints []struct {
+		Lat  string `xml:"lat,attr"`
+		Lon  string `xml:"lon,attr"`
+		Name string `xml:"name"`
+		Desc string `xml:"desc"`
+	} `xml:"wpt"`
+	Tracks []struct {
+		Name   string `xml:"name"`
+		Points []struct {
+			Lat  string `xml:"lat,attr"`
+			Lon  string `xml:"lon,attr"`
+			Time string `xml:"time"`
+		} `xml:"trkseg>trkpt"`
+	} `xml:"trk"`
+}
+
+func TestGPXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	gw := NewGPXWriter(&buf)
+
+	ports := newIterator(func() ([]Port, *string, error) {
+		return []Port{
+			{Name: Ptr("Rotterdam"), UnloCode: Ptr("NLRTM"), Latitude: Ptr(51.95), Longitude: Ptr(4.05)},
+			{Name: Ptr("Nowhere")}, // no coordinates
+		}, nil, nil
+	})
+	if _, err := gw.CopyWaypoints(ports); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	glitch := fencePos("2025-01-01T00:30:00Z", 10, 10)
+	glitch.SuspectedGlitch = Ptr(true)
+	positions := newIterator(func() ([]VesselPosition, *string, error) {
+		return []VesselPosition{
+			fencePos("2025-01-01T00:00:00Z", 51, 3),
+			glitch,
+			fencePos("2025-01-01T01:00:00Z", 51.5, 3.5),
+		}, nil, nil
+	})
+	n, err := gw.CopyTrack("streamed", positions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 track points, got %d", n)
+	}
+
+	start := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	if err := gw.WriteTrack("built", Track{{Point: geo.Point{Lat: 1, Lon: 2}, Time: start}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := gw.WriteWaypoint(Port{Latitude: Ptr(0.0), Longitude: Ptr(0.0)}); err == nil {
+		t.Error("expected an error writing a waypoint after a track")
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc gpxDoc
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid GPX %s: %v", buf.Bytes(), err)
+	}
+	if len(doc.Waypoints) != 1 || doc.Waypoints[0].Name != "Rotterdam" || doc.Waypoints[0].Desc != "NLRTM" {
+		t.Errorf("unexpected waypoints %+v", doc.Waypoints)
+	}
+	if doc.Waypoints[0].Lat != "51.95" || doc.Waypoints[0].Lon != "4.05" {
+		t.Errorf("unexpected waypoint coordinates %+v", doc.Waypoints[0])
+	}
+	if len(doc.Tracks) != 2 {
+		t.Fatalf("expected 2 tracks, got %d", len(doc.Tracks))
+	}
+	streamed := doc.Tracks[0]
+	if streamed.Name != "streamed" || len(streamed.Points) != 2 || streamed.Points[1].Time != "2025-01-01T01:00:00Z" {
+		t.Errorf("unexpected streamed track %+v", streamed)
+	}
+	if built := doc.Tracks[1]; built.Name != "built" || len(built.Points) != 1 || built.Points[0].Lon != "2" {
+		t.Errorf("unexpected built track %+v", built)
+	}
+}
+
+func TestGPXWriter_WriteError(t *testing.T) {
+	gw := NewGPXWriter(failingWriter{})
+	err := gw.WriteTrack("x", nil)
+	if err == nil {
+		t.Fatal("expected a write error")
+	}
+	if err2 := gw.Close(); !errors.Is(err2, errFailingWriter) {
+		t.Errorf("expected Close to return the first write error, got %v", err2)
+	}
+}
+
+var errFailingWriter = errors.New("disk full")
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errFailingWriter }
@@ -0,0 +1,146 @@
+package vesselapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// kmlStyles are the shared styles every KML document declares, referenced
+// by placemarks as "#<id>".
+const kmlStyles = `<Style id="vessel"><IconStyle><Icon><href>https://maps.google.com/mapfiles/kml/shapes/ferry.png</href></Icon></IconStyle></Style>
+<Style id="port"><IconStyle><Icon><href>https://maps.google.com/mapfiles/kml/shapes/marina.png</href></Icon></IconStyle></Style>
+<Style id="lightaid"><IconStyle><color>ff00ffff</color><Icon><href>https://maps.google.com/mapfiles/kml/shapes/star.png</href></Icon></IconStyle></Style>
+<Style id="radiobeacon"><IconStyle><Icon><href>https://maps.google.com/mapfiles/kml/shapes/electronics.png</href></Icon></IconStyle></Style>
+<Style id="dgps"><IconStyle><Icon><href>https://maps.google.com/mapfiles/kml/shapes/target.png</href></Icon></IconStyle></Style>
+<Style id="modu"><IconStyle><color>ff0000ff</color><Icon><href>https://maps.google.com/mapfiles/kml/shapes/square.png</href></Icon></IconStyle></Style>
+<Style id="track"><LineStyle><color>ffff7f00</color><width>2</width></LineStyle></Style>
+`
+
+// KMLWriter streams a KML document for Google Earth and similar viewers.
+// Placemarks are added with WriteKMLPlacemark or CopyKML and vessel tracks
+// with WriteTrack, in any mix; the document is complete once Close has been
+// called.
+type KMLWriter struct {
+	doc docWriter
+}
+
+// NewKMLWriter returns a KMLWriter that writes a document with the given
+// name to w.
+func NewKMLWriter(w io.Writer, name string) *KMLWriter {
+	return &KMLWriter{doc: docWriter{
+		w:      w,
+		format: "KML",
+		header: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+			`<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">` + "\n" +
+			"<Document>\n<name>" + xmlEscape(name) + "</name>\n" + kmlStyles,
+		footer: "</Document>\n</kml>\n",
+	}}
+}
+
+// Close ends the document. It does not close the underlying writer.
+func (kw *KMLWriter) Close() error {
+	return kw.doc.close()
+}
+
+// WriteTrack adds the track as a gx:Track placemark with a time for every
+// point, so viewers with a time slider replay the vessel's movement. An
+// empty track is written without a geometry.
+func (kw *KMLWriter) WriteTrack(name string, t Track) error {
+	var b bytes.Buffer
+	b.WriteString("<Placemark><name>" + xmlEscape(name) + "</name><styleUrl>#track</styleUrl>")
+	if len(t) > 0 {
+		b.WriteString("<gx:Track><altitudeMode>clampToGround</altitudeMode>")
+		for _, p := range t {
+			b.WriteString("<when>" + p.Time.UTC().Format(time.RFC3339) + "</when>")
+		}
+		for _, p := range t {
+			b.WriteString("<gx:coord>" + formatCoord(p.Point.Lon) + " " + formatCoord(p.Point.Lat) + " 0</gx:coord>")
+		}
+		b.WriteString("</gx:Track>")
+	}
+	b.WriteString("</Placemark>\n")
+	return kw.doc.element(b.Bytes())
+}
+
+// WriteKMLPlacemark adds v to the document as a placemark styled by its
+// type, with every attribute as extended data. Vessel positions carry
+// their timestamp. Items without coordinates are written without a
+// geometry.
+func WriteKMLPlacemark[T Locatable](kw *KMLWriter, v T) error {
+	props, err := featureProperties(v)
+	if err != nil {
+		return err
+	}
+	name, style, when := kmlPlacemarkInfo(v)
+
+	var b bytes.Buffer
+	b.WriteString("<Placemark><name>" + xmlEscape(name) + "</name><styleUrl>#" + style + "</styleUrl>")
+	if when != "" {
+		b.WriteString("<TimeStamp><when>" + when + "</when></TimeStamp>")
+	}
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b.WriteString("<ExtendedData>")
+	for _, k := range keys {
+		b.WriteString(`<Data name="` + xmlEscape(k) + `"><value>` + xmlEscape(propertyText(props[k])) + "</value></Data>")
+	}
+	b.WriteString("</ExtendedData>")
+	if pt, ok := LocatablePoint(v); ok {
+		b.WriteString("<Point><coordinates>" + formatCoord(pt.Lon) + "," + formatCoord(pt.Lat) + "</coordinates></Point>")
+	}
+	b.WriteString("</Placemark>\n")
+	return kw.doc.element(b.Bytes())
+}
+
+// CopyKML drains it into kw as placemarks, writing each page as it is
+// fetched, and returns the number written. It does not close kw.
+func CopyKML[T Locatable](kw *KMLWriter, it *Iterator[T]) (int, error) {
+	return copyIterator(it, func(v T) error { return WriteKMLPlacemark(kw, v) })
+}
+
+// kmlPlacemarkInfo returns the placemark name, style and timestamp for v.
+func kmlPlacemarkInfo(v any) (name, style, when string) {
+	switch v := v.(type) {
+	case VesselPosition:
+		name = Deref(v.VesselName)
+		if name == "" && v.Mmsi != nil {
+			name = strconv.Itoa(*v.Mmsi)
+		}
+		if ts, err := parseTimestamp(Deref(v.Timestamp)); err == nil {
+			when = ts.Format(time.RFC3339)
+		}
+		return name, "vessel", when
+	case Port:
+		return Deref(v.Name), "port", ""
+	case DGPSStation:
+		return Deref(v.Name), "dgps", ""
+	case LightAid:
+		return Deref(v.Name), "lightaid", ""
+	case MODU:
+		return Deref(v.Name), "modu", ""
+	case RadioBeacon:
+		return Deref(v.Name), "radiobeacon", ""
+	}
+	return "", "", ""
+}
+
+// propertyText formats a value decoded by featureProperties as text.
+func propertyText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,131 @@
+package vesselapi
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// kmlDoc is a decoded KML document.
+type kmlDoc struct {
+	Document struct {
+		Name   string `xml:"name"`
+		Styles []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"Style"`
+		Placemarks []struct {
+			Name     string `xml:"name"`
+			StyleURL string `xml:"styleUrl"`
+			When     string `xml:"TimeStamp>when"`
+			Data     []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:"value"`
+			} `xml:"ExtendedData>Data"`
+			Point string `xml:"Point>coordinates"`
+			Track *struct {
+				When  []string `xml:"when"`
+				Coord []string `xml:"http://www.google.com/kml/ext/2.2 coord"`
+			} `xml:"http://www.google.com/kml/ext/2.2 Track"`
+		} `xml:"Placemark"`
+	} `xml:"Document"`
+}
+
+func decodeKML(t *testing.T, data []byte) kmlDoc {
+	t.Helper()
+	var doc kmlDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid KML %s: %v", data, err)
+	}
+	return doc
+}
+
+func TestKMLWriter(t *testing.T) {
+	var buf bytes.Buffer
+	kw := NewKMLWriter(&buf, "Ops <North Sea>")
+
+	ports := []Port{{Name: Ptr("Rotterdam"), UnloCode: Ptr("NLRTM"), Latitude: Ptr(51.95), Longitude: Ptr(4.05)}}
+	it := newIterator(func() ([]Port, *string, error) {
+		page := ports
+		ports = nil
+		return page, nil, nil
+	})
+	if n, err := CopyKML(kw, it); err != nil || n != 1 {
+		t.Fatalf("expected 1 placemark, got %d, %v", n, err)
+	}
+	if err := WriteKMLPlacemark(kw, VesselPosition{
+		Mmsi: Ptr(244000000), Timestamp: Ptr("2025-01-01T12:00:00Z"),
+		Latitude: Ptr(52.0), Longitude: Ptr(4.0),
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	track := Track{
+		{Point: geo.Point{Lat: 51, Lon: 3}, Time: start},
+		{Point: geo.Point{Lat: 51.5, Lon: 3.5}, Time: start.Add(time.Hour)},
+	}
+	if err := kw.WriteTrack("Voyage & back", track); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := kw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	doc := decodeKML(t, buf.Bytes())
+	if doc.Document.Name != "Ops <North Sea>" {
+		t.Errorf("expected escaped document name, got %q", doc.Document.Name)
+	}
+	if len(doc.Document.Styles) == 0 {
+		t.Errorf("expected styles to be declared")
+	}
+	pms := doc.Document.Placemarks
+	if len(pms) != 3 {
+		t.Fatalf("expected 3 placemarks, got %d", len(pms))
+	}
+
+	port := pms[0]
+	if port.Name != "Rotterdam" || port.StyleURL != "#port" || port.Point != "4.05,51.95" {
+		t.Errorf("unexpected port placemark %+v", port)
+	}
+	var unlocode string
+	for _, d := range port.Data {
+		if d.Name == "unlo_code" {
+			unlocode = d.Value
+		}
+	}
+	if unlocode != "NLRTM" {
+		t.Errorf("expected unlo_code extended data, got %+v", port.Data)
+	}
+
+	vessel := pms[1]
+	if vessel.Name != "244000000" || vessel.StyleURL != "#vessel" || vessel.When != "2025-01-01T12:00:00Z" {
+		t.Errorf("unexpected vessel placemark %+v", vessel)
+	}
+
+	line := pms[2]
+	if line.Name != "Voyage & back" || line.StyleURL != "#track" || line.Track == nil {
+		t.Fatalf("unexpected track placemark %+v", line)
+	}
+	if got := strings.Join(line.Track.When, " "); got != "2025-01-01T00:00:00Z 2025-01-01T01:00:00Z" {
+		t.Errorf("expected a time per point, got %q", got)
+	}
+	if got := strings.Join(line.Track.Coord, ","); got != "3 51 0,3.5 51.5 0" {
+		t.Errorf("expected a coordinate per point, got %q", got)
+	}
+}
+
+func TestKMLWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewKMLWriter(&buf, "empty").Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc := decodeKML(t, buf.Bytes()); len(doc.Document.Placemarks) != 0 {
+		t.Errorf("expected no placemarks")
+	}
+	if !strings.HasSuffix(buf.String(), "</kml>\n") {
+		t.Errorf("expected a closed document, got %s", buf.String())
+	}
+}
@@ -0,0 +1,118 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migrations are the schema changes, applied in order. Each is applied
+// once, in its own transaction, and recorded in schema_migrations by its
+// 1-based position. Append new migrations; never edit applied ones.
+var migrations = []string{
+	// 1: initial schema. Every record is stored whole as JSON in data,
+	// next to the columns queries filter and sort on.
+	`CREATE TABLE sync_state (
+		resource  TEXT PRIMARY KEY,
+		watermark TEXT,
+		synced_at TEXT NOT NULL
+	);
+
+	CREATE TABLE ports (
+		unlo_code    TEXT PRIMARY KEY,
+		name         TEXT,
+		country_code TEXT,
+		type         TEXT,
+		size         TEXT,
+		region_name  TEXT,
+		harbor_size  TEXT,
+		harbor_use   TEXT,
+		latitude     REAL,
+		longitude    REAL,
+		data         TEXT NOT NULL,
+		synced_at    TEXT NOT NULL
+	);
+	CREATE INDEX ports_name ON ports (name COLLATE NOCASE);
+
+	CREATE TABLE aids (
+		kind      TEXT NOT NULL,
+		id        TEXT NOT NULL,
+		name      TEXT,
+		latitude  REAL,
+		longitude REAL,
+		data      TEXT NOT NULL,
+		synced_at TEXT NOT NULL,
+		PRIMARY KEY (kind, id)
+	);
+	CREATE INDEX aids_name ON aids (kind, name COLLATE NOCASE);
+
+	CREATE TABLE port_events (
+		id          TEXT PRIMARY KEY,
+		timestamp   TEXT NOT NULL,
+		event       TEXT,
+		unlo_code   TEXT,
+		port_name   TEXT,
+		country     TEXT,
+		vessel_imo  INTEGER,
+		vessel_mmsi INTEGER,
+		vessel_name TEXT,
+		data        TEXT NOT NULL
+	);
+	CREATE INDEX port_events_timestamp ON port_events (timestamp);
+	CREATE INDEX port_events_port ON port_events (unlo_code, timestamp);
+	CREATE INDEX port_events_vessel ON port_events (vessel_imo, timestamp);
+
+	CREATE TABLE navtex (
+		id         TEXT PRIMARY KEY,
+		timestamp  TEXT NOT NULL,
+		metarea_id TEXT,
+		data       TEXT NOT NULL
+	);
+	CREATE INDEX navtex_timestamp ON navtex (timestamp);`,
+
+	// 2: look up port events by MMSI as well as by IMO number.
+	`CREATE INDEX port_events_vessel_mmsi ON port_events (vessel_mmsi, timestamp);`,
+}
+
+// migrate applies the migrations db has not seen yet.
+func migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+	)`); err != nil {
+		return fmt.Errorf("mirror: creating schema_migrations: %w", err)
+	}
+	var current int
+	if err := db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return fmt.Errorf("mirror: reading schema version: %w", err)
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("mirror: database schema version %d is newer than this package supports (%d)", current, len(migrations))
+	}
+	for v := current + 1; v <= len(migrations); v++ {
+		if err := withTx(ctx, db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migrations[v-1]); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", v)
+			return err
+		}); err != nil {
+			return fmt.Errorf("mirror: applying migration %d: %w", v, err)
+		}
+	}
+	return nil
+}
+
+// withTx runs fn in a transaction, committing if it succeeds and rolling
+// back otherwise.
+func withTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback() //nolint:errcheck
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,177 @@
+// Package mirror keeps an offline copy of Vessel API reference data and
+// recent history in a local SQLite database.
+//
+// A Mirror syncs ports, aids to navigation (DGPS stations, light aids,
+// radio beacons and MODUs), port events and NAVTEX messages. Reference
+// data is refreshed in full; port events and NAVTEX messages are fetched
+// incrementally from a per-resource time watermark. The query services
+// (Ports, Search, PortEvents and Navtex) have the same method signatures as
+// their online counterparts on vesselapi.VesselClient, so code can switch
+// between the API and the mirror.
+//
+// The package is a separate module so that the core SDK does not depend on
+// a SQLite driver. It uses modernc.org/sqlite, which needs no cgo.
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+const (
+	defaultHistory = 30 * 24 * time.Hour
+	defaultOverlap = time.Hour
+)
+
+// timeLayout is the format timestamps are stored in. It has a fixed width,
+// so stored timestamps sort correctly as text.
+const timeLayout = "2006-01-02T15:04:05.000Z"
+
+// Options configures a Mirror. A nil *Options uses the defaults.
+type Options struct {
+	// Area is the region whose aids to navigation are mirrored. The API
+	// only lists aids by area, so they are not synced when Area is nil.
+	Area geo.Region
+
+	// AreaOptions configures the area queries used to sync aids.
+	AreaOptions *vesselapi.AreaOptions
+
+	// History is how far back the first sync of port events and NAVTEX
+	// messages reaches. Defaults to 30 days.
+	History time.Duration
+
+	// Overlap is how far before the watermark each incremental sync
+	// starts, to pick up records the API published late. Defaults to one
+	// hour.
+	Overlap time.Duration
+}
+
+// Mirror is a local SQLite copy of Vessel API data. Create one with Open,
+// fill it with Sync and query it through its services.
+type Mirror struct {
+	db     *sql.DB
+	client *vesselapi.VesselClient
+	opts   Options
+	now    func() time.Time
+
+	// Ports looks up mirrored ports.
+	Ports *PortsService
+
+	// PortEvents lists mirrored port events.
+	PortEvents *PortEventsService
+
+	// Search searches mirrored ports and aids to navigation.
+	Search *SearchService
+
+	// Navtex lists mirrored NAVTEX messages.
+	Navtex *NavtexService
+}
+
+// Open opens or creates the mirror database at path and applies any
+// pending schema migrations. The client is used by the Sync methods; it
+// may be nil for a read-only mirror.
+func Open(path string, client *vesselapi.VesselClient, opts *Options) (*Mirror, error) {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.History <= 0 {
+		o.History = defaultHistory
+	}
+	if o.Overlap <= 0 {
+		o.Overlap = defaultOverlap
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("mirror: %w", err)
+	}
+	// SQLite allows one writer at a time; a single connection also keeps
+	// in-memory databases shared.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA foreign_keys = ON; PRAGMA busy_timeout = 5000"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("mirror: %w", err)
+	}
+	if err := migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	m := &Mirror{db: db, client: client, opts: o, now: time.Now}
+	m.Ports = &PortsService{m: m}
+	m.PortEvents = &PortEventsService{m: m}
+	m.Search = &SearchService{m: m}
+	m.Navtex = &NavtexService{m: m}
+	return m, nil
+}
+
+// DB returns the underlying database, for queries the services do not
+// cover.
+func (m *Mirror) DB() *sql.DB {
+	return m.db
+}
+
+// Close closes the database.
+func (m *Mirror) Close() error {
+	return m.db.Close()
+}
+
+// Resource identifies a mirrored data set.
+type Resource string
+
+const (
+	ResourcePorts        Resource = "ports"
+	ResourceDGPS         Resource = "dgps"
+	ResourceLightAids    Resource = "lightaids"
+	ResourceRadioBeacons Resource = "radiobeacons"
+	ResourceMODUs        Resource = "modus"
+	ResourcePortEvents   Resource = "portevents"
+	ResourceNavtex       Resource = "navtex"
+)
+
+// LastSynced returns when the resource was last synced successfully, or
+// the zero time if it never was.
+func (m *Mirror) LastSynced(ctx context.Context, r Resource) (time.Time, error) {
+	var at string
+	err := m.db.QueryRowContext(ctx, "SELECT synced_at FROM sync_state WHERE resource = ?", string(r)).Scan(&at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("mirror: %w", err)
+	}
+	return time.Parse(timeLayout, at)
+}
+
+// formatTime returns t in the stored timestamp format.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// timestampLayouts are the formats the API uses for timestamp fields, most
+// specific first.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+}
+
+// parseTimestamp parses an API timestamp string and returns it in UTC.
+// Timestamps without a zone offset are interpreted as UTC.
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("mirror: unrecognized timestamp %q", s)
+}
@@ -0,0 +1,305 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// fakeAPI serves the endpoints the mirror syncs from.
+type fakeAPI struct {
+	mu        sync.Mutex
+	ports     []vesselapi.Port
+	events    []vesselapi.PortEvent
+	navtex    []vesselapi.Navtex
+	eventFrom []string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	var body any
+	switch r.URL.Path {
+	case "/search/ports":
+		body = vesselapi.FindPortsResponse{Ports: &f.ports}
+	case "/location/dgps/bounding-box":
+		body = vesselapi.DGPSStationsWithinLocationResponse{DgpsStations: &[]vesselapi.DGPSStation{
+			{Name: vesselapi.Ptr("Hoek van Holland"), StationId: vesselapi.Ptr("0421"), Location: geoJSON(4.1, 52.0)},
+		}}
+	case "/location/lightaids/bounding-box":
+		body = vesselapi.LightAidsWithinLocationResponse{LightAids: &[]vesselapi.LightAid{
+			{Name: vesselapi.Ptr("Maasvlakte"), VolumeNumber: vesselapi.Ptr("192"), FeatureNumber: vesselapi.Ptr("1234"), Location: geoJSON(4.0, 51.97)},
+			{Name: vesselapi.Ptr("Outside"), FeatureNumber: vesselapi.Ptr("9"), Location: geoJSON(10, 10)},
+		}}
+	case "/location/radiobeacons/bounding-box":
+		body = vesselapi.RadioBeaconsWithinLocationResponse{}
+	case "/location/modu/bounding-box":
+		body = vesselapi.MODUsWithinLocationResponse{}
+	case "/portevents":
+		f.eventFrom = append(f.eventFrom, r.URL.Query().Get("time.from"))
+		body = vesselapi.PortEventsResponse{PortEvents: &f.events}
+	case "/navtex":
+		body = vesselapi.NavtexMessagesResponse{NavtexMessages: &f.navtex}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(body) //nolint:errcheck
+}
+
+func geoJSON(lon, lat float32) *vesselapi.GithubComVesselapiCommonVesselDataContractsTypesGeoJSON {
+	return &vesselapi.GithubComVesselapiCommonVesselDataContractsTypesGeoJSON{Coordinates: &[]float32{lon, lat}}
+}
+
+func port(unlo, name, country string) vesselapi.Port {
+	return vesselapi.Port{
+		UnloCode: vesselapi.Ptr(unlo),
+		Name:     vesselapi.Ptr(name),
+		Country:  &vesselapi.GithubComVesselapiCommonVesselDataContractsTypesPortCountry{Code: vesselapi.Ptr(country)},
+		Type:     vesselapi.Ptr("Coastal Natural"),
+	}
+}
+
+func event(ts, kind, unlo, vessel string) vesselapi.PortEvent {
+	return vesselapi.PortEvent{
+		Timestamp: vesselapi.Ptr(ts),
+		Event:     vesselapi.Ptr(kind),
+		Port:      &vesselapi.GithubComVesselapiCommonVesselDataContractsTypesPortReference{UnloCode: vesselapi.Ptr(unlo), Country: vesselapi.Ptr(unlo[:2])},
+		Vessel:    &vesselapi.GithubComVesselapiCommonVesselDataContractsTypesVesselReference{Name: vesselapi.Ptr(vessel), Imo: vesselapi.Ptr(9811000)},
+	}
+}
+
+var syncNow = time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func openTest(t *testing.T, api *fakeAPI, opts *Options) *Mirror {
+	t.Helper()
+	ts := httptest.NewServer(api)
+	t.Cleanup(ts.Close)
+	vc, err := vesselapi.NewVesselClient("test-key", vesselapi.WithVesselBaseURL(ts.URL), vesselapi.WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, err := Open(filepath.Join(t.TempDir(), "mirror.db"), vc, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	m.now = func() time.Time { return syncNow }
+	return m
+}
+
+func TestOpen_MigratesOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mirror.db")
+	for i := 0; i < 2; i++ {
+		m, err := Open(path, nil, nil)
+		if err != nil {
+			t.Fatalf("open %d: unexpected error: %v", i, err)
+		}
+		var n int
+		if err := m.DB().QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&n); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != len(migrations) {
+			t.Errorf("expected %d applied migrations, got %d", len(migrations), n)
+		}
+		m.Close()
+	}
+
+	m, _ := Open(path, nil, nil)
+	m.DB().Exec("INSERT INTO schema_migrations (version) VALUES (99)") //nolint:errcheck
+	m.Close()
+	if _, err := Open(path, nil, nil); err == nil {
+		t.Error("expected an error for a newer schema version")
+	}
+}
+
+func TestSync(t *testing.T) {
+	api := &fakeAPI{
+		ports: []vesselapi.Port{port("NLRTM", "Rotterdam", "NL"), port("BEANR", "Antwerp", "BE"), {Name: vesselapi.Ptr("No code")}},
+		events: []vesselapi.PortEvent{
+			event("2025-03-09T08:00:00Z", "arrival", "NLRTM", "EVER GIVEN"),
+			event("2025-03-09T20:00:00Z", "departure", "NLRTM", "EVER GIVEN"),
+			event("not a time", "arrival", "NLRTM", "EVER GIVEN"),
+		},
+		navtex: []vesselapi.Navtex{{Timestamp: vesselapi.Ptr("2025-03-09T10:00:00Z"), Label: vesselapi.Ptr("PA12"), RawContent: vesselapi.Ptr("GALE WARNING")}},
+	}
+	m := openTest(t, api, &Options{Area: geo.Rectangle(51, 3, 53, 5)})
+	ctx := context.Background()
+
+	got, err := m.Sync(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := SyncResult{Ports: 2, DGPS: 1, LightAids: 1, PortEvents: 2, Navtex: 1}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if at, _ := m.LastSynced(ctx, ResourcePorts); !at.Equal(syncNow) {
+		t.Errorf("expected ports synced at %v, got %v", syncNow, at)
+	}
+	if api.eventFrom[0] != "2025-02-08T12:00:00Z" {
+		t.Errorf("expected the first sync to reach back 30 days, got %s", api.eventFrom[0])
+	}
+
+	// A second sync starts an hour before the watermark, replaces the
+	// overlapping events and drops ports no longer listed.
+	syncNow = syncNow.Add(6 * time.Hour)
+	defer func() { syncNow = syncNow.Add(-6 * time.Hour) }()
+	api.ports = api.ports[:1]
+	api.events = append(api.events, event("2025-03-10T14:00:00Z", "arrival", "BEANR", "MSC OSCAR"))
+	if got, err = m.Sync(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Ports != 1 || got.PortEvents != 3 {
+		t.Errorf("unexpected second sync %+v", got)
+	}
+	if api.eventFrom[1] != "2025-03-10T11:00:00Z" {
+		t.Errorf("expected the incremental sync from 2025-03-10T11:00:00Z, got %s", api.eventFrom[1])
+	}
+	var n int
+	m.DB().QueryRow("SELECT COUNT(*) FROM port_events").Scan(&n) //nolint:errcheck
+	if n != 3 {
+		t.Errorf("expected 3 stored events, got %d", n)
+	}
+	if _, err := m.Ports.Get(ctx, "BEANR"); err == nil {
+		t.Error("expected BEANR to be removed")
+	}
+}
+
+func TestSync_NoClient(t *testing.T) {
+	m, err := Open(filepath.Join(t.TempDir(), "mirror.db"), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.Close()
+	if _, err := m.Sync(context.Background()); err == nil {
+		t.Error("expected an error without a client")
+	}
+}
+
+func TestQueries(t *testing.T) {
+	api := &fakeAPI{
+		ports: []vesselapi.Port{port("NLRTM", "Rotterdam", "NL"), port("NLAMS", "Amsterdam", "NL"), port("BEANR", "Antwerp", "BE")},
+		events: []vesselapi.PortEvent{
+			event("2025-03-09T08:00:00Z", "arrival", "NLRTM", "EVER GIVEN"),
+			event("2025-03-09T20:00:00Z", "departure", "NLRTM", "EVER GIVEN"),
+			event("2025-03-10T02:00:00Z", "arrival", "BEANR", "MSC OSCAR"),
+		},
+		navtex: []vesselapi.Navtex{
+			{Timestamp: vesselapi.Ptr("2025-03-09T10:00:00Z"), Label: vesselapi.Ptr("PA12")},
+			{Timestamp: vesselapi.Ptr("2025-03-10T10:00:00Z"), Label: vesselapi.Ptr("PA13")},
+		},
+	}
+	api.events[2].Vessel.Imo = vesselapi.Ptr(9839131)
+	api.events[2].Vessel.Mmsi = vesselapi.Ptr(255806000)
+	m := openTest(t, api, &Options{Area: geo.Rectangle(51, 3, 53, 5)})
+	ctx := context.Background()
+	if _, err := m.Sync(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, err := m.Ports.Get(ctx, "nlrtm")
+	if err != nil || vesselapi.Deref(p.Port.Name) != "Rotterdam" {
+		t.Errorf("expected Rotterdam, got %+v (%v)", p, err)
+	}
+	var apiErr *vesselapi.APIError
+	if _, err := m.Ports.Get(ctx, "XXXXX"); !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("expected a not found APIError, got %v", err)
+	}
+
+	ports, err := m.Search.Ports(ctx, &vesselapi.GetSearchPortsParams{FilterCountry: vesselapi.Ptr("nl"), PaginationLimit: vesselapi.Ptr(1)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*ports.Ports) != 1 || vesselapi.Deref((*ports.Ports)[0].UnloCode) != "NLAMS" || vesselapi.Deref(ports.NextToken) != "1" {
+		t.Errorf("unexpected first page %+v", ports)
+	}
+	ports, _ = m.Search.Ports(ctx, &vesselapi.GetSearchPortsParams{FilterCountry: vesselapi.Ptr("NL"), PaginationNextToken: ports.NextToken})
+	if len(*ports.Ports) != 1 || vesselapi.Deref((*ports.Ports)[0].UnloCode) != "NLRTM" || ports.NextToken != nil {
+		t.Errorf("unexpected last page %+v", ports)
+	}
+	ports, _ = m.Search.Ports(ctx, &vesselapi.GetSearchPortsParams{FilterName: vesselapi.Ptr("TWER")})
+	if len(*ports.Ports) != 1 || vesselapi.Deref((*ports.Ports)[0].UnloCode) != "BEANR" {
+		t.Errorf("expected Antwerp by name, got %+v", ports)
+	}
+
+	lights, err := m.Search.LightAids(ctx, &vesselapi.GetSearchLightaidsParams{FilterName: "maas"})
+	if err != nil || len(*lights.LightAids) != 1 {
+		t.Errorf("expected one light aid, got %+v (%v)", lights, err)
+	}
+	dgps, _ := m.Search.DGPS(ctx, nil)
+	if len(*dgps.DgpsStations) != 1 {
+		t.Errorf("expected one DGPS station, got %+v", dgps)
+	}
+
+	events, err := m.PortEvents.List(ctx, &vesselapi.GetPorteventsParams{
+		TimeFrom:       vesselapi.Ptr("2025-03-09T12:00:00Z"),
+		FilterUnlocode: vesselapi.Ptr("NLRTM"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*events.PortEvents) != 1 || vesselapi.Deref((*events.PortEvents)[0].Event) != "departure" {
+		t.Errorf("expected the Rotterdam departure, got %+v", events)
+	}
+	events, _ = m.PortEvents.List(ctx, &vesselapi.GetPorteventsParams{FilterVesselName: vesselapi.Ptr("oscar")})
+	if len(*events.PortEvents) != 1 {
+		t.Errorf("expected one MSC OSCAR event, got %+v", events)
+	}
+	events, _ = m.PortEvents.ByPort(ctx, "NLRTM", nil)
+	if len(*events.PortEvents) != 2 || vesselapi.Deref((*events.PortEvents)[0].Event) != "arrival" {
+		t.Errorf("expected two Rotterdam events oldest first, got %+v", events)
+	}
+	if _, err := m.PortEvents.List(ctx, &vesselapi.GetPorteventsParams{TimeTo: vesselapi.Ptr("yesterday")}); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+	events, err = m.PortEvents.List(ctx, &vesselapi.GetPorteventsParams{TimeFrom: vesselapi.Ptr("2025-03-09 12:00:00")})
+	if err != nil || len(*events.PortEvents) != 2 {
+		t.Errorf("expected two events from a time without a zone, got %+v (%v)", events, err)
+	}
+
+	events, _ = m.PortEvents.ByPorts(ctx, &vesselapi.GetPorteventsPortsParams{FilterPortName: ""})
+	if len(*events.PortEvents) != 3 {
+		t.Errorf("expected every event without a port filter, got %+v", events)
+	}
+	events, _ = m.PortEvents.ByVessels(ctx, &vesselapi.GetPorteventsVesselsParams{FilterVesselName: "ever"})
+	if len(*events.PortEvents) != 2 {
+		t.Errorf("expected two EVER GIVEN events, got %+v", events)
+	}
+	events, err = m.PortEvents.ByVessel(ctx, "9811000", &vesselapi.GetPorteventsVesselIdParams{
+		FilterSortOrder: vesselapi.Ptr(vesselapi.Desc),
+		FilterEventType: vesselapi.Ptr(vesselapi.All),
+	})
+	if err != nil || len(*events.PortEvents) != 2 || vesselapi.Deref((*events.PortEvents)[0].Event) != "departure" {
+		t.Errorf("expected two EVER GIVEN events newest first, got %+v (%v)", events, err)
+	}
+	events, _ = m.PortEvents.ByVessel(ctx, "9811000", &vesselapi.GetPorteventsVesselIdParams{FilterEventType: vesselapi.Ptr(vesselapi.Arrival)})
+	if len(*events.PortEvents) != 1 || vesselapi.Deref((*events.PortEvents)[0].Event) != "arrival" {
+		t.Errorf("expected the EVER GIVEN arrival, got %+v", events)
+	}
+	last, err := m.PortEvents.LastByVessel(ctx, "255806000", &vesselapi.GetPorteventsVesselIdLastParams{FilterIdType: vesselapi.GetPorteventsVesselIdLastParamsFilterIdTypeMmsi})
+	if err != nil || vesselapi.Deref(last.PortEvent.Port.UnloCode) != "BEANR" {
+		t.Errorf("expected the MSC OSCAR arrival by MMSI, got %+v (%v)", last, err)
+	}
+	if _, err := m.PortEvents.LastByVessel(ctx, "1234567", nil); !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("expected a not found APIError, got %v", err)
+	}
+	if _, err := m.PortEvents.ByVessel(ctx, "EVER GIVEN", nil); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a bad request APIError, got %v", err)
+	}
+
+	msgs, _ := m.Navtex.List(ctx, &vesselapi.GetNavtexParams{TimeTo: vesselapi.Ptr("2025-03-10T00:00:00Z")})
+	if len(*msgs.NavtexMessages) != 1 || vesselapi.Deref((*msgs.NavtexMessages)[0].Label) != "PA12" {
+		t.Errorf("expected PA12, got %+v", msgs)
+	}
+}
@@ -0,0 +1,380 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+)
+
+// defaultPageSize is the page size used when a query sets no
+// PaginationLimit, matching the API's maximum.
+const defaultPageSize = 50
+
+// PortsService looks up mirrored ports, like vesselapi.PortsService.
+type PortsService struct {
+	m *Mirror
+}
+
+// Get retrieves a mirrored port by its UN/LOCODE. A port that is not in the
+// mirror is reported as a 404 *vesselapi.APIError, as the API does.
+func (s *PortsService) Get(ctx context.Context, unlocode string) (*vesselapi.PortResponse, error) {
+	ports, _, err := page[vesselapi.Port](ctx, s.m, "SELECT data FROM ports WHERE unlo_code = ?",
+		[]any{strings.ToUpper(unlocode)}, vesselapi.Ptr(1), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(ports) == 0 {
+		return nil, &vesselapi.APIError{StatusCode: http.StatusNotFound, Message: "port " + unlocode + " not found in mirror"}
+	}
+	return &vesselapi.PortResponse{Port: &ports[0]}, nil
+}
+
+// SearchService searches mirrored ports and aids to navigation, like
+// vesselapi.SearchService. Name filters match case-insensitive substrings;
+// the other filters match exactly.
+type SearchService struct {
+	m *Mirror
+}
+
+// Ports searches mirrored ports.
+func (s *SearchService) Ports(ctx context.Context, params *vesselapi.GetSearchPortsParams) (*vesselapi.FindPortsResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetSearchPortsParams{}
+	}
+	var w where
+	w.like("name", params.FilterName)
+	w.eq("country_code", params.FilterCountry)
+	w.eq("type", params.FilterType)
+	w.eq("size", params.FilterSize)
+	w.eq("region_name", params.FilterRegion)
+	w.eq("harbor_size", params.FilterHarborSize)
+	w.eq("harbor_use", params.FilterHarborUse)
+	ports, next, err := page[vesselapi.Port](ctx, s.m, "SELECT data FROM ports"+w.String()+" ORDER BY unlo_code",
+		w.args, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.FindPortsResponse{Ports: &ports, NextToken: next}, nil
+}
+
+// DGPS searches mirrored DGPS stations by name.
+func (s *SearchService) DGPS(ctx context.Context, params *vesselapi.GetSearchDgpsParams) (*vesselapi.FindDGPSStationsResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetSearchDgpsParams{}
+	}
+	aids, next, err := searchAids[vesselapi.DGPSStation](ctx, s.m, ResourceDGPS, params.FilterName, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.FindDGPSStationsResponse{DgpsStations: &aids, NextToken: next}, nil
+}
+
+// LightAids searches mirrored light aids by name.
+func (s *SearchService) LightAids(ctx context.Context, params *vesselapi.GetSearchLightaidsParams) (*vesselapi.FindLightAidsResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetSearchLightaidsParams{}
+	}
+	aids, next, err := searchAids[vesselapi.LightAid](ctx, s.m, ResourceLightAids, params.FilterName, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.FindLightAidsResponse{LightAids: &aids, NextToken: next}, nil
+}
+
+// MODUs searches mirrored MODUs by name.
+func (s *SearchService) MODUs(ctx context.Context, params *vesselapi.GetSearchModusParams) (*vesselapi.FindMODUsResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetSearchModusParams{}
+	}
+	aids, next, err := searchAids[vesselapi.MODU](ctx, s.m, ResourceMODUs, params.FilterName, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.FindMODUsResponse{Modus: &aids, NextToken: next}, nil
+}
+
+// RadioBeacons searches mirrored radio beacons by name.
+func (s *SearchService) RadioBeacons(ctx context.Context, params *vesselapi.GetSearchRadiobeaconsParams) (*vesselapi.FindRadioBeaconsResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetSearchRadiobeaconsParams{}
+	}
+	aids, next, err := searchAids[vesselapi.RadioBeacon](ctx, s.m, ResourceRadioBeacons, params.FilterName, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.FindRadioBeaconsResponse{RadioBeacons: &aids, NextToken: next}, nil
+}
+
+func searchAids[T any](ctx context.Context, m *Mirror, kind Resource, name string, limit *int, token *string) ([]T, *string, error) {
+	w := where{conds: []string{"kind = ?"}, args: []any{string(kind)}}
+	if name != "" {
+		w.like("name", &name)
+	}
+	return page[T](ctx, m, "SELECT data FROM aids"+w.String()+" ORDER BY name, id", w.args, limit, token)
+}
+
+// PortEventsService lists mirrored port events, like
+// vesselapi.PortEventsService. Events are returned oldest first.
+type PortEventsService struct {
+	m *Mirror
+}
+
+// List retrieves mirrored port events, optionally within a time range and
+// filtered by country, port, vessel name or event type.
+func (s *PortEventsService) List(ctx context.Context, params *vesselapi.GetPorteventsParams) (*vesselapi.PortEventsResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetPorteventsParams{}
+	}
+	var w where
+	if err := w.timeRange(params.TimeFrom, params.TimeTo); err != nil {
+		return nil, err
+	}
+	w.eq("country", params.FilterCountry)
+	w.eq("unlo_code", params.FilterUnlocode)
+	w.eq("event", params.FilterEventType)
+	w.like("vessel_name", params.FilterVesselName)
+	w.like("port_name", params.FilterPortName)
+	events, next, err := page[vesselapi.PortEvent](ctx, s.m, "SELECT data FROM port_events"+w.String()+" ORDER BY timestamp, id",
+		w.args, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.PortEventsResponse{PortEvents: &events, NextToken: next}, nil
+}
+
+// ByPort retrieves the mirrored port events for a port by its UN/LOCODE.
+func (s *PortEventsService) ByPort(ctx context.Context, unlocode string, params *vesselapi.GetPorteventsPortUnlocodeParams) (*vesselapi.PortEventsResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetPorteventsPortUnlocodeParams{}
+	}
+	events, next, err := page[vesselapi.PortEvent](ctx, s.m, "SELECT data FROM port_events WHERE unlo_code = ? ORDER BY timestamp, id",
+		[]any{strings.ToUpper(unlocode)}, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.PortEventsResponse{PortEvents: &events, NextToken: next}, nil
+}
+
+// ByPorts retrieves the mirrored port events for ports whose name contains
+// params.FilterPortName.
+func (s *PortEventsService) ByPorts(ctx context.Context, params *vesselapi.GetPorteventsPortsParams) (*vesselapi.PortEventsResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetPorteventsPortsParams{}
+	}
+	var w where
+	w.like("port_name", &params.FilterPortName)
+	events, next, err := page[vesselapi.PortEvent](ctx, s.m, "SELECT data FROM port_events"+w.String()+" ORDER BY timestamp, id",
+		w.args, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.PortEventsResponse{PortEvents: &events, NextToken: next}, nil
+}
+
+// ByVessel retrieves the mirrored port events for a vessel by IMO number,
+// or by MMSI if params.FilterIdType is mmsi, optionally within a time range
+// and filtered by event type. Events are returned oldest first unless
+// params.FilterSortOrder is desc.
+func (s *PortEventsService) ByVessel(ctx context.Context, id string, params *vesselapi.GetPorteventsVesselIdParams) (*vesselapi.PortEventsResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetPorteventsVesselIdParams{}
+	}
+	var w where
+	if err := w.vessel(id, string(params.FilterIdType)); err != nil {
+		return nil, err
+	}
+	if err := w.timeRange(params.TimeFrom, params.TimeTo); err != nil {
+		return nil, err
+	}
+	if t := params.FilterEventType; t != nil && *t != vesselapi.All {
+		w.eq("event", (*string)(t))
+	}
+	order := " ORDER BY timestamp, id"
+	if params.FilterSortOrder != nil && *params.FilterSortOrder == vesselapi.Desc {
+		order = " ORDER BY timestamp DESC, id DESC"
+	}
+	events, next, err := page[vesselapi.PortEvent](ctx, s.m, "SELECT data FROM port_events"+w.String()+order,
+		w.args, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.PortEventsResponse{PortEvents: &events, NextToken: next}, nil
+}
+
+// LastByVessel retrieves the latest mirrored port event for a vessel by IMO
+// number, or by MMSI if params.FilterIdType is mmsi. A vessel with no
+// mirrored events is reported as a 404 *vesselapi.APIError, as the API
+// does.
+func (s *PortEventsService) LastByVessel(ctx context.Context, id string, params *vesselapi.GetPorteventsVesselIdLastParams) (*vesselapi.PortEventResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetPorteventsVesselIdLastParams{}
+	}
+	var w where
+	if err := w.vessel(id, string(params.FilterIdType)); err != nil {
+		return nil, err
+	}
+	events, _, err := page[vesselapi.PortEvent](ctx, s.m, "SELECT data FROM port_events"+w.String()+" ORDER BY timestamp DESC, id DESC",
+		w.args, vesselapi.Ptr(1), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, &vesselapi.APIError{StatusCode: http.StatusNotFound, Message: "no port events for vessel " + id + " in mirror"}
+	}
+	return &vesselapi.PortEventResponse{PortEvent: &events[0]}, nil
+}
+
+// ByVessels retrieves the mirrored port events for vessels whose name
+// contains params.FilterVesselName.
+func (s *PortEventsService) ByVessels(ctx context.Context, params *vesselapi.GetPorteventsVesselsParams) (*vesselapi.PortEventsResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetPorteventsVesselsParams{}
+	}
+	var w where
+	w.like("vessel_name", &params.FilterVesselName)
+	events, next, err := page[vesselapi.PortEvent](ctx, s.m, "SELECT data FROM port_events"+w.String()+" ORDER BY timestamp, id",
+		w.args, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.PortEventsResponse{PortEvents: &events, NextToken: next}, nil
+}
+
+// NavtexService lists mirrored NAVTEX messages, like
+// vesselapi.NavtexService. Messages are returned oldest first.
+type NavtexService struct {
+	m *Mirror
+}
+
+// List retrieves mirrored NAVTEX messages, optionally within a time range.
+func (s *NavtexService) List(ctx context.Context, params *vesselapi.GetNavtexParams) (*vesselapi.NavtexMessagesResponse, error) {
+	if params == nil {
+		params = &vesselapi.GetNavtexParams{}
+	}
+	var w where
+	if err := w.timeRange(params.TimeFrom, params.TimeTo); err != nil {
+		return nil, err
+	}
+	msgs, next, err := page[vesselapi.Navtex](ctx, s.m, "SELECT data FROM navtex"+w.String()+" ORDER BY timestamp, id",
+		w.args, params.PaginationLimit, params.PaginationNextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &vesselapi.NavtexMessagesResponse{NavtexMessages: &msgs, NextToken: next}, nil
+}
+
+// where builds the WHERE clause of a query from the filters that are set.
+type where struct {
+	conds []string
+	args  []any
+}
+
+func (w *where) eq(col string, v *string) {
+	if v != nil && *v != "" {
+		w.conds = append(w.conds, col+" = ? COLLATE NOCASE")
+		w.args = append(w.args, *v)
+	}
+}
+
+func (w *where) like(col string, v *string) {
+	if v != nil && *v != "" {
+		w.conds = append(w.conds, col+" LIKE ? ESCAPE '\\'")
+		w.args = append(w.args, "%"+likeEscaper.Replace(*v)+"%")
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// timeRange restricts the timestamp column to [from, to], either of which
+// may be unset.
+func (w *where) timeRange(from, to *string) error {
+	for _, b := range []struct {
+		v  *string
+		op string
+	}{{from, ">="}, {to, "<="}} {
+		if b.v == nil || *b.v == "" {
+			continue
+		}
+		t, err := parseTimestamp(*b.v)
+		if err != nil {
+			return err
+		}
+		w.conds = append(w.conds, "timestamp "+b.op+" ?")
+		w.args = append(w.args, formatTime(t))
+	}
+	return nil
+}
+
+// vessel restricts the results to the vessel with the given IMO number, or
+// MMSI if idType is "mmsi". An id that is not a number is reported as a 400
+// *vesselapi.APIError, as the API does.
+func (w *where) vessel(id, idType string) error {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return &vesselapi.APIError{StatusCode: http.StatusBadRequest, Message: "invalid vessel id " + strconv.Quote(id)}
+	}
+	col := "vessel_imo"
+	if idType == "mmsi" {
+		col = "vessel_mmsi"
+	}
+	w.conds = append(w.conds, col+" = ?")
+	w.args = append(w.args, n)
+	return nil
+}
+
+func (w *where) String() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conds, " AND ")
+}
+
+// page runs query, which selects the data column, and decodes one page of
+// results. The next-page token is the offset of the following page, or nil
+// on the last page.
+func page[T any](ctx context.Context, m *Mirror, query string, args []any, limit *int, token *string) ([]T, *string, error) {
+	n := vesselapi.Deref(limit)
+	if n <= 0 {
+		n = defaultPageSize
+	}
+	offset := 0
+	if token != nil && *token != "" {
+		o, err := strconv.Atoi(*token)
+		if err != nil || o < 0 {
+			return nil, nil, fmt.Errorf("mirror: invalid pagination token %q", *token)
+		}
+		offset = o
+	}
+	// Fetch one extra row to learn whether there is a next page.
+	rows, err := m.db.QueryContext(ctx, query+" LIMIT ? OFFSET ?", append(args, n+1, offset)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("mirror: %w", err)
+	}
+	defer rows.Close()
+	out := []T{}
+	var next *string
+	for rows.Next() {
+		if len(out) == n {
+			next = vesselapi.Ptr(strconv.Itoa(offset + n))
+			break
+		}
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, nil, fmt.Errorf("mirror: %w", err)
+		}
+		var v T
+		if err := json.Unmarshal([]byte(data), &v); err != nil {
+			return nil, nil, fmt.Errorf("mirror: decoding stored record: %w", err)
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("mirror: %w", err)
+	}
+	return out, next, nil
+}
@@ -0,0 +1,402 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// syncBatch is the number of incrementally synced records written per
+// transaction.
+const syncBatch = 500
+
+// SyncResult counts the records fetched by Sync, per resource.
+type SyncResult struct {
+	Ports        int
+	DGPS         int
+	LightAids    int
+	RadioBeacons int
+	MODUs        int
+	PortEvents   int
+	Navtex       int
+}
+
+// Sync syncs every resource: ports, the aids to navigation in
+// Options.Area if it is set, and then port events and NAVTEX messages
+// since their watermarks. It stops at the first error; resources synced
+// before it keep their new data.
+func (m *Mirror) Sync(ctx context.Context) (SyncResult, error) {
+	var r SyncResult
+	var err error
+	if r.Ports, err = m.SyncPorts(ctx); err != nil {
+		return r, err
+	}
+	if m.opts.Area != nil {
+		if r.DGPS, err = m.SyncDGPS(ctx); err != nil {
+			return r, err
+		}
+		if r.LightAids, err = m.SyncLightAids(ctx); err != nil {
+			return r, err
+		}
+		if r.RadioBeacons, err = m.SyncRadioBeacons(ctx); err != nil {
+			return r, err
+		}
+		if r.MODUs, err = m.SyncMODUs(ctx); err != nil {
+			return r, err
+		}
+	}
+	if r.PortEvents, err = m.SyncPortEvents(ctx); err != nil {
+		return r, err
+	}
+	if r.Navtex, err = m.SyncNavtex(ctx); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// SyncPorts replaces the mirrored ports with every port from
+// SearchService.AllPorts and returns the number stored. Ports without a
+// UN/LOCODE are skipped.
+func (m *Mirror) SyncPorts(ctx context.Context) (int, error) {
+	if err := m.checkClient(); err != nil {
+		return 0, err
+	}
+	ports, err := m.client.Search.AllPorts(ctx, nil).Collect()
+	if err != nil {
+		return 0, err
+	}
+	now := formatTime(m.now())
+	n := 0
+	err = withTx(ctx, m.db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO ports
+			(unlo_code, name, country_code, type, size, region_name, harbor_size, harbor_use, latitude, longitude, data, synced_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, p := range ports {
+			if p.UnloCode == nil || *p.UnloCode == "" {
+				continue
+			}
+			data, err := json.Marshal(p)
+			if err != nil {
+				return err
+			}
+			var country *string
+			if p.Country != nil {
+				country = p.Country.Code
+			}
+			lat, lon := point(vesselapi.LocatablePoint(p))
+			if _, err := stmt.ExecContext(ctx, *p.UnloCode, p.Name, country, p.Type, p.Size, p.RegionName,
+				p.HarborSize, p.HarborUse, lat, lon, string(data), now); err != nil {
+				return err
+			}
+			n++
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM ports WHERE synced_at <> ?", now); err != nil {
+			return err
+		}
+		return setSyncState(ctx, tx, ResourcePorts, "", now)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("mirror: syncing ports: %w", err)
+	}
+	return n, nil
+}
+
+// SyncDGPS replaces the mirrored DGPS stations with those in Options.Area
+// and returns the number stored.
+func (m *Mirror) SyncDGPS(ctx context.Context) (int, error) {
+	return syncAids(ctx, m, ResourceDGPS, func(ctx context.Context, area geo.Region, opts *vesselapi.AreaOptions) ([]vesselapi.DGPSStation, error) {
+		return m.client.Location.DGPSInArea(ctx, area, opts)
+	}, func(v vesselapi.DGPSStation) (string, *string) {
+		if v.StationId != nil && *v.StationId != "" {
+			return *v.StationId, v.Name
+		}
+		return featureID(v.VolumeNumber, intString(v.FeatureNumber), v.Name), v.Name
+	})
+}
+
+// SyncLightAids replaces the mirrored light aids with those in
+// Options.Area and returns the number stored.
+func (m *Mirror) SyncLightAids(ctx context.Context) (int, error) {
+	return syncAids(ctx, m, ResourceLightAids, func(ctx context.Context, area geo.Region, opts *vesselapi.AreaOptions) ([]vesselapi.LightAid, error) {
+		return m.client.Location.LightAidsInArea(ctx, area, opts)
+	}, func(v vesselapi.LightAid) (string, *string) {
+		return featureID(v.VolumeNumber, vesselapi.Deref(v.FeatureNumber), v.Name), v.Name
+	})
+}
+
+// SyncRadioBeacons replaces the mirrored radio beacons with those in
+// Options.Area and returns the number stored.
+func (m *Mirror) SyncRadioBeacons(ctx context.Context) (int, error) {
+	return syncAids(ctx, m, ResourceRadioBeacons, func(ctx context.Context, area geo.Region, opts *vesselapi.AreaOptions) ([]vesselapi.RadioBeacon, error) {
+		return m.client.Location.RadioBeaconsInArea(ctx, area, opts)
+	}, func(v vesselapi.RadioBeacon) (string, *string) {
+		return featureID(v.VolumeNumber, intString(v.FeatureNumber), v.Name), v.Name
+	})
+}
+
+// SyncMODUs replaces the mirrored MODUs with those in Options.Area and
+// returns the number stored.
+func (m *Mirror) SyncMODUs(ctx context.Context) (int, error) {
+	return syncAids(ctx, m, ResourceMODUs, func(ctx context.Context, area geo.Region, opts *vesselapi.AreaOptions) ([]vesselapi.MODU, error) {
+		return m.client.Location.MODUsInArea(ctx, area, opts)
+	}, func(v vesselapi.MODU) (string, *string) {
+		return vesselapi.Deref(v.Name), v.Name
+	})
+}
+
+// syncAids replaces the mirrored aids of one kind with those fetch returns
+// for the configured area. key returns an aid's ID, or "" to skip it, and
+// its name.
+func syncAids[T vesselapi.Locatable](ctx context.Context, m *Mirror, kind Resource,
+	fetch func(context.Context, geo.Region, *vesselapi.AreaOptions) ([]T, error),
+	key func(T) (string, *string),
+) (int, error) {
+	if err := m.checkClient(); err != nil {
+		return 0, err
+	}
+	if m.opts.Area == nil {
+		return 0, errors.New("mirror: no Area configured for aids to navigation")
+	}
+	aids, err := fetch(ctx, m.opts.Area, m.opts.AreaOptions)
+	if err != nil {
+		return 0, err
+	}
+	now := formatTime(m.now())
+	n := 0
+	err = withTx(ctx, m.db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO aids
+			(kind, id, name, latitude, longitude, data, synced_at) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, a := range aids {
+			id, name := key(a)
+			if id == "" {
+				continue
+			}
+			data, err := json.Marshal(a)
+			if err != nil {
+				return err
+			}
+			lat, lon := point(vesselapi.LocatablePoint(a))
+			if _, err := stmt.ExecContext(ctx, string(kind), id, name, lat, lon, string(data), now); err != nil {
+				return err
+			}
+			n++
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM aids WHERE kind = ? AND synced_at <> ?", string(kind), now); err != nil {
+			return err
+		}
+		return setSyncState(ctx, tx, kind, "", now)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("mirror: syncing %s: %w", kind, err)
+	}
+	return n, nil
+}
+
+// SyncPortEvents fetches the port events published since the last sync,
+// less Options.Overlap, or within Options.History on the first sync, and
+// returns the number fetched. Events already mirrored are replaced, so
+// overlapping syncs do not create duplicates. Events without a usable
+// timestamp are skipped.
+func (m *Mirror) SyncPortEvents(ctx context.Context) (int, error) {
+	return syncIncremental(ctx, m, ResourcePortEvents, func(from, to string) *vesselapi.Iterator[vesselapi.PortEvent] {
+		return m.client.PortEvents.ListAll(ctx, &vesselapi.GetPorteventsParams{TimeFrom: &from, TimeTo: &to})
+	}, `INSERT OR REPLACE INTO port_events
+		(id, timestamp, event, unlo_code, port_name, country, vessel_imo, vessel_mmsi, vessel_name, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		func(e vesselapi.PortEvent) ([]any, bool) {
+			at, err := parseTimestamp(vesselapi.Deref(e.Timestamp))
+			if err != nil {
+				return nil, false
+			}
+			ts := formatTime(at)
+			data, err := json.Marshal(e)
+			if err != nil {
+				return nil, false
+			}
+			var unlo, portName, country, vesselName *string
+			var imo, mmsi *int
+			portKey, vesselKey := "", ""
+			if e.Port != nil {
+				unlo, portName, country = e.Port.UnloCode, e.Port.Name, e.Port.Country
+				portKey = vesselapi.Deref(unlo) + "/" + vesselapi.Deref(portName)
+			}
+			if e.Vessel != nil {
+				imo, mmsi, vesselName = e.Vessel.Imo, e.Vessel.Mmsi, e.Vessel.Name
+				vesselKey = intString(imo) + "/" + intString(mmsi) + "/" + vesselapi.Deref(vesselName)
+			}
+			id := hashID(ts, vesselapi.Deref(e.Event), vesselKey, portKey)
+			return []any{id, ts, e.Event, unlo, portName, country, imo, mmsi, vesselName, string(data)}, true
+		})
+}
+
+// SyncNavtex fetches the NAVTEX messages published since the last sync,
+// less Options.Overlap, or within Options.History on the first sync, and
+// returns the number fetched. Messages without a usable timestamp are
+// skipped.
+func (m *Mirror) SyncNavtex(ctx context.Context) (int, error) {
+	return syncIncremental(ctx, m, ResourceNavtex, func(from, to string) *vesselapi.Iterator[vesselapi.Navtex] {
+		return m.client.Navtex.ListAll(ctx, &vesselapi.GetNavtexParams{TimeFrom: &from, TimeTo: &to})
+	}, `INSERT OR REPLACE INTO navtex (id, timestamp, metarea_id, data) VALUES (?, ?, ?, ?)`,
+		func(n vesselapi.Navtex) ([]any, bool) {
+			at, err := parseTimestamp(vesselapi.Deref(n.Timestamp))
+			if err != nil {
+				return nil, false
+			}
+			ts := formatTime(at)
+			data, err := json.Marshal(n)
+			if err != nil {
+				return nil, false
+			}
+			id := hashID(ts, vesselapi.Deref(n.Label), vesselapi.Deref(n.WmoHeader), vesselapi.Deref(n.RawContent))
+			return []any{id, ts, n.MetareaId, string(data)}, true
+		})
+}
+
+// syncIncremental fetches the records of a time-stamped resource between
+// its watermark, less the overlap, and now, and writes them with insert in
+// batches. args returns the insert arguments for a record, or false to
+// skip it. The watermark only advances once every record is written.
+func syncIncremental[T any](ctx context.Context, m *Mirror, r Resource,
+	fetch func(from, to string) *vesselapi.Iterator[T], insert string, args func(T) ([]any, bool),
+) (int, error) {
+	if err := m.checkClient(); err != nil {
+		return 0, err
+	}
+	now := m.now().UTC()
+	from := now.Add(-m.opts.History)
+	var watermark sql.NullString
+	err := m.db.QueryRowContext(ctx, "SELECT watermark FROM sync_state WHERE resource = ?", string(r)).Scan(&watermark)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("mirror: reading %s watermark: %w", r, err)
+	}
+	if watermark.Valid {
+		if w, err := time.Parse(timeLayout, watermark.String); err == nil {
+			from = w.Add(-m.opts.Overlap)
+		}
+	}
+
+	it := fetch(from.Format(time.RFC3339), now.Format(time.RFC3339))
+	n := 0
+	batch := make([][]any, 0, syncBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := withTx(ctx, m.db, func(tx *sql.Tx) error {
+			stmt, err := tx.PrepareContext(ctx, insert)
+			if err != nil {
+				return err
+			}
+			defer stmt.Close()
+			for _, a := range batch {
+				if _, err := stmt.ExecContext(ctx, a...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("mirror: syncing %s: %w", r, err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for it.Next() {
+		a, ok := args(it.Value())
+		if !ok {
+			continue
+		}
+		batch = append(batch, a)
+		n++
+		if len(batch) == syncBatch {
+			if err := flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return n, err
+	}
+	if err := flush(); err != nil {
+		return n, err
+	}
+	if err := setSyncState(ctx, m.db, r, formatTime(now), formatTime(now)); err != nil {
+		return n, fmt.Errorf("mirror: syncing %s: %w", r, err)
+	}
+	return n, nil
+}
+
+// execer is implemented by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// setSyncState records a successful sync of r. An empty watermark is
+// stored as NULL.
+func setSyncState(ctx context.Context, db execer, r Resource, watermark, syncedAt string) error {
+	var wm any
+	if watermark != "" {
+		wm = watermark
+	}
+	_, err := db.ExecContext(ctx, "INSERT OR REPLACE INTO sync_state (resource, watermark, synced_at) VALUES (?, ?, ?)",
+		string(r), wm, syncedAt)
+	return err
+}
+
+func (m *Mirror) checkClient() error {
+	if m.client == nil {
+		return errors.New("mirror: no client configured for syncing")
+	}
+	return nil
+}
+
+// point returns the coordinates of an item, or nils when it has none.
+func point(p geo.Point, ok bool) (lat, lon any) {
+	if !ok {
+		return nil, nil
+	}
+	return p.Lat, p.Lon
+}
+
+// featureID identifies an aid to navigation by its List of Lights volume
+// and feature number, or by name when it has no feature number.
+func featureID(volume *string, feature string, name *string) string {
+	if feature == "" {
+		return vesselapi.Deref(name)
+	}
+	return vesselapi.Deref(volume) + "/" + feature
+}
+
+func intString(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// hashID derives a stable record ID from the fields that identify it.
+func hashID(parts ...string) string {
+	h := sha1.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,292 @@
+package nmea
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+)
+
+const (
+	defaultWriteTimeout   = 5 * time.Second
+	defaultStaticInterval = 6 * time.Minute
+)
+
+// BroadcasterOptions configures a Broadcaster. A nil *BroadcasterOptions
+// uses the defaults.
+type BroadcasterOptions struct {
+	// Encoder encodes the sentences. Defaults to NewEncoder(nil).
+	Encoder *Encoder
+
+	// WriteTimeout bounds each write to a TCP client; clients that do not
+	// keep up are disconnected. Defaults to 5 seconds.
+	WriteTimeout time.Duration
+
+	// StaticInterval is how often Run repeats the static data registered
+	// with SetStatic, as AIS transponders do. Defaults to 6 minutes.
+	StaticInterval time.Duration
+}
+
+// Broadcaster sends AIS sentences to NMEA listeners: TCP clients that
+// connect to it and UDP destinations it is given. Feed it a Watcher's
+// events with Run, or send positions and static data directly.
+type Broadcaster struct {
+	enc  *Encoder
+	opts BroadcasterOptions
+
+	mu        sync.Mutex
+	closed    bool
+	listeners []net.Listener
+	tcp       map[net.Conn]struct{}
+	udp       []net.Conn
+	static    map[int]StaticData
+}
+
+// NewBroadcaster returns a Broadcaster with no listeners.
+func NewBroadcaster(opts *BroadcasterOptions) *Broadcaster {
+	o := BroadcasterOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Encoder == nil {
+		o.Encoder = NewEncoder(nil)
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = defaultWriteTimeout
+	}
+	if o.StaticInterval <= 0 {
+		o.StaticInterval = defaultStaticInterval
+	}
+	return &Broadcaster{
+		enc:    o.Encoder,
+		opts:   o,
+		tcp:    make(map[net.Conn]struct{}),
+		static: make(map[int]StaticData),
+	}
+}
+
+// ListenTCP listens on the TCP address addr, such as ":10110", and serves
+// clients in the background until Close is called. It returns the address
+// listened on.
+func (b *Broadcaster) ListenTCP(addr string) (net.Addr, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	go b.Serve(l) //nolint:errcheck
+	return l.Addr(), nil
+}
+
+// Serve accepts TCP clients on l until Close is called, then returns nil.
+// Clients receive every sentence broadcast while they are connected;
+// anything they send is ignored.
+func (b *Broadcaster) Serve(l net.Listener) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return l.Close()
+	}
+	b.listeners = append(b.listeners, l)
+	b.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			b.mu.Lock()
+			closed := b.closed
+			b.mu.Unlock()
+			if closed {
+				return nil
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		b.tcp[conn] = struct{}{}
+		b.mu.Unlock()
+	}
+}
+
+// AddUDP adds a UDP destination, such as "192.168.1.20:10110". Sentences
+// are sent to it as datagrams whether or not anything is listening.
+func (b *Broadcaster) AddUDP(addr string) error {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		conn.Close()
+		return errors.New("nmea: broadcaster closed")
+	}
+	b.udp = append(b.udp, conn)
+	return nil
+}
+
+// Clients returns the number of connected TCP clients.
+func (b *Broadcaster) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.tcp)
+}
+
+// Send broadcasts sentences, each terminated by CR LF. TCP clients whose
+// write fails are disconnected; UDP errors are ignored.
+func (b *Broadcaster) Send(sentences []string) {
+	if len(sentences) == 0 {
+		return
+	}
+	data := []byte(strings.Join(sentences, "\r\n") + "\r\n")
+
+	b.mu.Lock()
+	tcp := make([]net.Conn, 0, len(b.tcp))
+	for c := range b.tcp {
+		tcp = append(tcp, c)
+	}
+	udp := append([]net.Conn(nil), b.udp...)
+	b.mu.Unlock()
+
+	for _, c := range udp {
+		// Each sentence is its own datagram.
+		for _, s := range sentences {
+			c.Write([]byte(s + "\r\n")) //nolint:errcheck
+		}
+	}
+	for _, c := range tcp {
+		c.SetWriteDeadline(time.Now().Add(b.opts.WriteTimeout)) //nolint:errcheck
+		if _, err := c.Write(data); err != nil {
+			b.mu.Lock()
+			delete(b.tcp, c)
+			b.mu.Unlock()
+			c.Close()
+		}
+	}
+}
+
+// SendPosition encodes and broadcasts a position report.
+func (b *Broadcaster) SendPosition(p vesselapi.VesselPosition) error {
+	s, err := b.enc.EncodePosition(p)
+	if err != nil {
+		return err
+	}
+	b.Send(s)
+	return nil
+}
+
+// SetStatic encodes and broadcasts the static data of a vessel, and
+// registers it to be repeated every StaticInterval while Run is running.
+// Setting static data again for the same MMSI replaces it.
+func (b *Broadcaster) SetStatic(s StaticData) error {
+	sentences, err := b.enc.EncodeStatic(s)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.static[s.MMSI] = s
+	b.mu.Unlock()
+	b.Send(sentences)
+	return nil
+}
+
+// Run broadcasts the positions carried by a Watcher's events until events
+// is closed or ctx is cancelled, then returns nil. Each report is sent
+// once, even when it causes several events; WatchDark and WatchError
+// events and positions without an MMSI are skipped. Set
+// WatcherOptions.EmitPositions to receive every report rather than only
+// those that change something.
+//
+// Run also repeats the registered static data every StaticInterval.
+func (b *Broadcaster) Run(ctx context.Context, events <-chan vesselapi.WatchEvent) error {
+	ticker := time.NewTicker(b.opts.StaticInterval)
+	defer ticker.Stop()
+	sent := make(map[int]vesselapi.VesselPosition)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			b.sendStatic()
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if ev.Type == vesselapi.WatchDark || ev.Type == vesselapi.WatchError {
+				continue
+			}
+			p := ev.Position
+			if p.Mmsi == nil {
+				continue
+			}
+			if last, ok := sent[*p.Mmsi]; ok && sameReport(last, p) {
+				continue
+			}
+			if b.SendPosition(p) == nil {
+				sent[*p.Mmsi] = p
+			}
+		}
+	}
+}
+
+// sameReport reports whether a and b are the same position report: by
+// timestamp when both have one, otherwise by content.
+func sameReport(a, b vesselapi.VesselPosition) bool {
+	ta, tb := vesselapi.Deref(a.Timestamp), vesselapi.Deref(b.Timestamp)
+	if ta != "" && tb != "" {
+		return ta == tb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// sendStatic broadcasts every registered static data message.
+func (b *Broadcaster) sendStatic() {
+	b.mu.Lock()
+	static := make([]StaticData, 0, len(b.static))
+	for _, s := range b.static {
+		static = append(static, s)
+	}
+	b.mu.Unlock()
+	for _, s := range static {
+		if sentences, err := b.enc.EncodeStatic(s); err == nil {
+			b.Send(sentences)
+		}
+	}
+}
+
+// Close stops accepting clients and closes every connection.
+func (b *Broadcaster) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	var errs []error
+	for _, l := range b.listeners {
+		if err := l.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for c := range b.tcp {
+		c.Close()
+	}
+	for _, c := range b.udp {
+		c.Close()
+	}
+	b.tcp = nil
+	b.udp = nil
+	return errors.Join(errs...)
+}
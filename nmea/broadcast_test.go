@@ -0,0 +1,178 @@
+package nmea
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+)
+
+func watchPos(mmsi int, ts string) vesselapi.VesselPosition {
+	return vesselapi.VesselPosition{
+		Mmsi:      vesselapi.Ptr(mmsi),
+		Latitude:  vesselapi.Ptr(51.9),
+		Longitude: vesselapi.Ptr(4.1),
+		Timestamp: vesselapi.Ptr(ts),
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBroadcaster_Run(t *testing.T) {
+	b := NewBroadcaster(nil)
+	defer b.Close()
+	addr, err := b.ListenTCP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	waitFor(t, func() bool { return b.Clients() == 1 })
+
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer udp.Close()
+	if err := b.AddUDP(udp.LocalAddr().String()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p1 := watchPos(244660000, "2025-01-01T00:00:00Z")
+	p2 := watchPos(244660000, "2025-01-01T00:01:00Z")
+	// Reports without a timestamp are told apart by content.
+	u1 := watchPos(244660000, "")
+	u2 := u1
+	u2.Latitude = vesselapi.Ptr(52.0)
+	events := make(chan vesselapi.WatchEvent, 16)
+	events <- vesselapi.WatchEvent{Type: vesselapi.WatchPosition, Position: p1}
+	events <- vesselapi.WatchEvent{Type: vesselapi.WatchMoved, Position: p1}
+	events <- vesselapi.WatchEvent{Type: vesselapi.WatchError}
+	events <- vesselapi.WatchEvent{Type: vesselapi.WatchDark, Position: p1}
+	events <- vesselapi.WatchEvent{Type: vesselapi.WatchPosition, Position: vesselapi.VesselPosition{Imo: vesselapi.Ptr(1)}}
+	events <- vesselapi.WatchEvent{Type: vesselapi.WatchPosition, Position: p2}
+	events <- vesselapi.WatchEvent{Type: vesselapi.WatchPosition, Position: u1}
+	events <- vesselapi.WatchEvent{Type: vesselapi.WatchMoved, Position: u1}
+	events <- vesselapi.WatchEvent{Type: vesselapi.WatchMoved, Position: u2}
+	close(events)
+	if err := b.Run(context.Background(), events); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	enc := NewEncoder(nil)
+	want1, _ := enc.EncodePosition(p1)
+	want2, _ := enc.EncodePosition(p2)
+	want3, _ := enc.EncodePosition(u1)
+	want4, _ := enc.EncodePosition(u2)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second)) //nolint:errcheck
+	r := bufio.NewReader(conn)
+	for _, want := range []string{want1[0], want2[0], want3[0], want4[0]} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasSuffix(line, "\r\n") || strings.TrimSpace(line) != want {
+			t.Errorf("expected %q, got %q", want, line)
+		}
+	}
+
+	buf := make([]byte, 256)
+	udp.SetReadDeadline(time.Now().Add(2 * time.Second)) //nolint:errcheck
+	n, _, err := udp.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(buf[:n]); got != want1[0]+"\r\n" {
+		t.Errorf("expected datagram %q, got %q", want1[0], got)
+	}
+
+	// Nothing else was sent: the duplicate, dark, error and MMSI-less
+	// events were skipped.
+	b.Close()
+	if rest, _ := r.ReadString('\n'); rest != "" {
+		t.Errorf("expected no more sentences, got %q", rest)
+	}
+}
+
+func TestBroadcaster_Static(t *testing.T) {
+	b := NewBroadcaster(&BroadcasterOptions{StaticInterval: 20 * time.Millisecond})
+	defer b.Close()
+	addr, err := b.ListenTCP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	waitFor(t, func() bool { return b.Clients() == 1 })
+
+	if err := b.SetStatic(StaticData{}); err == nil {
+		t.Error("expected an error for static data without an MMSI")
+	}
+	if err := b.SetStatic(StaticData{MMSI: 244660000, Name: "TEST"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Run(ctx, make(chan vesselapi.WatchEvent)) //nolint:errcheck
+	}()
+
+	// The message is sent once by SetStatic and repeated by Run; each is
+	// two sentences.
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second)) //nolint:errcheck
+	r := bufio.NewReader(conn)
+	for i := 0; i < 4; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("unexpected error after %d sentences: %v", i, err)
+		}
+		if !strings.HasPrefix(line, "!AIVDM,2,") {
+			t.Errorf("expected a fragment of a type 5 message, got %q", line)
+		}
+	}
+	cancel()
+	<-done
+}
+
+func TestBroadcaster_DropsClosedClients(t *testing.T) {
+	b := NewBroadcaster(&BroadcasterOptions{WriteTimeout: 100 * time.Millisecond})
+	defer b.Close()
+	addr, err := b.ListenTCP("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitFor(t, func() bool { return b.Clients() == 1 })
+	conn.Close()
+
+	// Writes to a closed peer fail once the connection reset arrives.
+	waitFor(t, func() bool {
+		b.SendPosition(watchPos(1, "2025-01-01T00:00:00Z")) //nolint:errcheck
+		return b.Clients() == 0
+	})
+}
@@ -0,0 +1,487 @@
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+)
+
+// ErrUnsupported is returned by Decoder.Decode for well-formed sentences
+// carrying a message type the decoder does not handle.
+var ErrUnsupported = errors.New("nmea: unsupported message type")
+
+// Message is a decoded AIS message. Depending on its type it carries a
+// position, static data, or both.
+type Message struct {
+	Type int
+	MMSI int
+
+	// Own is set for !AIVDO sentences, which report the receiver's own
+	// vessel.
+	Own bool
+
+	// Channel is the AIS radio channel the message was received on.
+	Channel byte
+
+	// ReceivedAt is the time the message was received: the tag block time
+	// if the sentence has one, otherwise the time it was decoded.
+	ReceivedAt time.Time
+
+	// Position is set for position reports (types 1, 2, 3, 18, 19 and 27)
+	// and base station reports (type 4). Its Timestamp is the reception
+	// time adjusted to the second the message reports, or the reported
+	// UTC time for base stations.
+	Position *vesselapi.VesselPosition
+
+	// ETA is set for static and voyage related data (type 5).
+	ETA *vesselapi.VesselETA
+
+	// Static is set for types 5, 19 and 24. Type 24 messages come in two
+	// parts, each carrying some of the fields; combine them with
+	// StaticData.Merge.
+	Static *StaticData
+}
+
+// Decoder decodes !AIVDM and !AIVDO sentences, reassembling messages that
+// span several sentences. It keeps state between calls and is not safe for
+// concurrent use.
+type Decoder struct {
+	now       func() time.Time
+	fragments map[string]*fragments
+}
+
+// fragments collects the sentences of a multi-sentence message.
+type fragments struct {
+	parts []string
+	have  int
+	fill  int
+}
+
+// NewDecoder returns a Decoder.
+func NewDecoder() *Decoder {
+	return &Decoder{now: time.Now, fragments: make(map[string]*fragments)}
+}
+
+// Decode decodes one sentence, optionally preceded by an NMEA 4.0 tag
+// block. It returns a nil Message and nil error for a fragment of a
+// message that is not yet complete. Sentences with a bad checksum or
+// payload return an error; well-formed sentences of other message types
+// return ErrUnsupported.
+func (d *Decoder) Decode(sentence string) (*Message, error) {
+	sentence = strings.TrimSpace(sentence)
+	received := d.now().UTC()
+	if strings.HasPrefix(sentence, `\`) {
+		end := strings.Index(sentence[1:], `\`)
+		if end < 0 {
+			return nil, fmt.Errorf("nmea: unterminated tag block in %q", sentence)
+		}
+		if t, ok := tagBlockTime(sentence[1 : end+1]); ok {
+			received = t
+		}
+		sentence = sentence[end+2:]
+	}
+
+	star := strings.LastIndexByte(sentence, '*')
+	if !strings.HasPrefix(sentence, "!") || star < 0 || len(sentence) < star+3 {
+		return nil, fmt.Errorf("nmea: malformed sentence %q", sentence)
+	}
+	body := sentence[1:star]
+	cs, err := strconv.ParseUint(sentence[star+1:star+3], 16, 8)
+	if err != nil || byte(cs) != checksum(body) {
+		return nil, fmt.Errorf("nmea: checksum mismatch in %q", sentence)
+	}
+	f := strings.Split(body, ",")
+	if len(f) != 7 || len(f[0]) != 5 || (f[0][2:] != "VDM" && f[0][2:] != "VDO") {
+		return nil, fmt.Errorf("nmea: not an AIS sentence: %q", sentence)
+	}
+	total, err1 := strconv.Atoi(f[1])
+	num, err2 := strconv.Atoi(f[2])
+	fill, err3 := strconv.Atoi(f[6])
+	if err1 != nil || err2 != nil || err3 != nil || total < 1 || num < 1 || num > total || fill < 0 || fill > 5 {
+		return nil, fmt.Errorf("nmea: malformed sentence %q", sentence)
+	}
+	var channel byte
+	if f[4] != "" {
+		channel = f[4][0]
+	}
+
+	payload := f[5]
+	if total > 1 {
+		key := f[0] + "," + f[1] + "," + f[3] + "," + f[4]
+		fr := d.fragments[key]
+		if num == 1 || fr == nil || len(fr.parts) != total {
+			fr = &fragments{parts: make([]string, total)}
+			d.fragments[key] = fr
+		}
+		if fr.parts[num-1] == "" {
+			fr.have++
+		}
+		fr.parts[num-1] = payload
+		if num == total {
+			fr.fill = fill
+		}
+		if fr.have < total {
+			return nil, nil
+		}
+		delete(d.fragments, key)
+		payload, fill = strings.Join(fr.parts, ""), fr.fill
+	}
+
+	bits, err := unarmor(payload, fill)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := decodeMessage(bitReader{bits}, received)
+	if err != nil {
+		return nil, err
+	}
+	msg.Own = f[0][2:] == "VDO"
+	msg.Channel = channel
+	return msg, nil
+}
+
+// tagBlockTime returns the c: (UNIX time) parameter of a tag block.
+func tagBlockTime(tags string) (time.Time, bool) {
+	if i := strings.LastIndexByte(tags, '*'); i >= 0 {
+		tags = tags[:i]
+	}
+	for _, p := range strings.Split(tags, ",") {
+		if !strings.HasPrefix(p, "c:") {
+			continue
+		}
+		v, err := strconv.ParseInt(p[2:], 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if v > 1e11 {
+			return time.UnixMilli(v).UTC(), true
+		}
+		return time.Unix(v, 0).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// unarmor converts a 6-bit armored payload to bits, dropping the fill bits.
+func unarmor(payload string, fill int) ([]byte, error) {
+	bits := make([]byte, 0, len(payload)*6)
+	for i := 0; i < len(payload); i++ {
+		c := payload[i]
+		if c < 48 || c > 119 || (c > 87 && c < 96) {
+			return nil, fmt.Errorf("nmea: invalid payload character %q", c)
+		}
+		v := c - 48
+		if v >= 40 {
+			v -= 8
+		}
+		for j := 5; j >= 0; j-- {
+			bits = append(bits, v>>uint(j)&1)
+		}
+	}
+	if fill > len(bits) {
+		return nil, errors.New("nmea: fill bits exceed payload")
+	}
+	return bits[:len(bits)-fill], nil
+}
+
+// bitReader reads fields of an AIS message. Bits past the end of the
+// message read as zero, so slightly short messages still decode.
+type bitReader struct {
+	bits []byte
+}
+
+func (r bitReader) uint(start, n int) uint64 {
+	var v uint64
+	for i := start; i < start+n; i++ {
+		v <<= 1
+		if i < len(r.bits) {
+			v |= uint64(r.bits[i])
+		}
+	}
+	return v
+}
+
+func (r bitReader) int(start, n int) int64 {
+	v := int64(r.uint(start, n))
+	if v&(1<<uint(n-1)) != 0 {
+		v -= 1 << uint(n)
+	}
+	return v
+}
+
+// text reads chars characters of 6-bit text, stopping at the first '@'
+// padding character and trimming trailing spaces.
+func (r bitReader) text(start, chars int) string {
+	var b strings.Builder
+	for i := 0; i < chars; i++ {
+		c := byte(r.uint(start+6*i, 6))
+		if c == 0 {
+			break
+		}
+		if c < 32 {
+			c += '@'
+		}
+		b.WriteByte(c)
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+func decodeMessage(r bitReader, received time.Time) (*Message, error) {
+	if len(r.bits) < 38 {
+		return nil, errors.New("nmea: message too short")
+	}
+	msg := &Message{
+		Type:       int(r.uint(0, 6)),
+		MMSI:       int(r.uint(8, 30)),
+		ReceivedAt: received,
+	}
+	mmsi := vesselapi.Ptr(msg.MMSI)
+	switch msg.Type {
+	case 1, 2, 3:
+		msg.Position = &vesselapi.VesselPosition{
+			Mmsi:      mmsi,
+			NavStatus: vesselapi.Ptr(int(r.uint(38, 4))),
+			Sog:       decodeSOG(r.uint(50, 10)),
+			Longitude: decodeCoord(r.int(61, 28), 600000, 180),
+			Latitude:  decodeCoord(r.int(89, 27), 600000, 90),
+			Cog:       decodeCOG(r.uint(116, 12)),
+			Heading:   decodeHeading(r.uint(128, 9)),
+			Timestamp: reportTimestamp(received, int(r.uint(137, 6))),
+		}
+	case 4:
+		at := received
+		t := time.Date(int(r.uint(38, 14)), time.Month(r.uint(52, 4)), int(r.uint(56, 5)),
+			int(r.uint(61, 5)), int(r.uint(66, 6)), int(r.uint(72, 6)), 0, time.UTC)
+		if r.uint(38, 14) != 0 && r.uint(52, 4) != 0 && r.uint(56, 5) != 0 && r.uint(61, 5) < 24 &&
+			r.uint(66, 6) < 60 && r.uint(72, 6) < 60 {
+			at = t
+		}
+		msg.Position = &vesselapi.VesselPosition{
+			Mmsi:      mmsi,
+			Longitude: decodeCoord(r.int(79, 28), 600000, 180),
+			Latitude:  decodeCoord(r.int(107, 27), 600000, 90),
+			Timestamp: vesselapi.Ptr(at.Format(time.RFC3339)),
+		}
+	case 5:
+		s := StaticData{
+			MMSI:        msg.MMSI,
+			IMO:         int(r.uint(40, 30)),
+			CallSign:    r.text(70, 7),
+			Name:        r.text(112, 20),
+			ShipType:    int(r.uint(232, 8)),
+			Draught:     float64(r.uint(294, 8)) / 10,
+			Destination: r.text(302, 20),
+		}
+		s.Length, s.Beam = dimensions(r, 240)
+		month, day, hour, minute := r.uint(274, 4), r.uint(278, 5), r.uint(283, 5), r.uint(288, 6)
+		eta := fmt.Sprintf("%02d-%02d %02d:%02d", month, day, hour, minute)
+		if t, err := vesselapi.ParseAISETA(eta, received); err == nil {
+			s.ETA = t
+		}
+		msg.Static = &s
+		msg.ETA = &vesselapi.VesselETA{
+			Mmsi:        mmsi,
+			Eta:         vesselapi.Ptr(eta),
+			Destination: optional(s.Destination),
+			VesselName:  optional(s.Name),
+			Timestamp:   vesselapi.Ptr(received.Format(time.RFC3339)),
+		}
+		if s.IMO != 0 {
+			msg.ETA.Imo = vesselapi.Ptr(s.IMO)
+		}
+		if s.Draught != 0 {
+			msg.ETA.Draught = vesselapi.Ptr(float32(s.Draught))
+		}
+	case 18, 19:
+		msg.Position = &vesselapi.VesselPosition{
+			Mmsi:      mmsi,
+			Sog:       decodeSOG(r.uint(46, 10)),
+			Longitude: decodeCoord(r.int(57, 28), 600000, 180),
+			Latitude:  decodeCoord(r.int(85, 27), 600000, 90),
+			Cog:       decodeCOG(r.uint(112, 12)),
+			Heading:   decodeHeading(r.uint(124, 9)),
+			Timestamp: reportTimestamp(received, int(r.uint(133, 6))),
+		}
+		if msg.Type == 19 {
+			s := StaticData{MMSI: msg.MMSI, Name: r.text(143, 20), ShipType: int(r.uint(263, 8))}
+			s.Length, s.Beam = dimensions(r, 271)
+			msg.Static = &s
+			msg.Position.VesselName = optional(s.Name)
+		}
+	case 24:
+		s := StaticData{MMSI: msg.MMSI}
+		switch r.uint(38, 2) {
+		case 0:
+			s.Name = r.text(40, 20)
+		case 1:
+			s.ShipType = int(r.uint(40, 8))
+			s.CallSign = r.text(90, 7)
+			s.Length, s.Beam = dimensions(r, 132)
+		default:
+			return nil, fmt.Errorf("nmea: invalid type 24 part number %d", r.uint(38, 2))
+		}
+		msg.Static = &s
+	case 27:
+		msg.Position = &vesselapi.VesselPosition{
+			Mmsi:      mmsi,
+			NavStatus: vesselapi.Ptr(int(r.uint(40, 4))),
+			Longitude: decodeCoord(r.int(44, 18), 600, 180),
+			Latitude:  decodeCoord(r.int(62, 17), 600, 90),
+			Timestamp: vesselapi.Ptr(received.Format(time.RFC3339)),
+		}
+		if v := r.uint(79, 6); v != 63 {
+			msg.Position.Sog = vesselapi.Ptr(float32(v))
+		}
+		if v := r.uint(85, 9); v < 360 {
+			msg.Position.Cog = vesselapi.Ptr(float32(v))
+		}
+	default:
+		return nil, fmt.Errorf("%w %d", ErrUnsupported, msg.Type)
+	}
+	return msg, nil
+}
+
+// dimensions returns the length and beam in meters from the four
+// dimension fields starting at start.
+func dimensions(r bitReader, start int) (length, beam float64) {
+	length = float64(r.uint(start, 9) + r.uint(start+9, 9))
+	beam = float64(r.uint(start+18, 6) + r.uint(start+24, 6))
+	return length, beam
+}
+
+func decodeSOG(v uint64) *float32 {
+	if v == sogNotAvailable {
+		return nil
+	}
+	return vesselapi.Ptr(float32(v) / 10)
+}
+
+func decodeCOG(v uint64) *float32 {
+	if v >= cogNotAvailable {
+		return nil
+	}
+	return vesselapi.Ptr(float32(v) / 10)
+}
+
+func decodeHeading(v uint64) *int {
+	if v > 359 {
+		return nil
+	}
+	return vesselapi.Ptr(int(v))
+}
+
+// decodeCoord converts a coordinate in 1/perDegree degrees, returning nil
+// for the "not available" value and anything else out of range.
+func decodeCoord(v int64, perDegree, limit float64) *float64 {
+	deg := float64(v) / perDegree
+	if math.Abs(deg) > limit {
+		return nil
+	}
+	return vesselapi.Ptr(deg)
+}
+
+// reportTimestamp returns the latest time at or before received whose
+// second is the reported one, or received itself when the second is not
+// available.
+func reportTimestamp(received time.Time, second int) *string {
+	t := received
+	if second < 60 {
+		t = received.Truncate(time.Minute).Add(time.Duration(second) * time.Second)
+		if t.After(received) {
+			t = t.Add(-time.Minute)
+		}
+	}
+	return vesselapi.Ptr(t.Format(time.RFC3339))
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// Merge returns s with every field that is set in o copied over it, for
+// combining partial static data such as the two parts of a type 24
+// message.
+func (s StaticData) Merge(o StaticData) StaticData {
+	if o.MMSI != 0 {
+		s.MMSI = o.MMSI
+	}
+	if o.IMO != 0 {
+		s.IMO = o.IMO
+	}
+	if o.CallSign != "" {
+		s.CallSign = o.CallSign
+	}
+	if o.Name != "" {
+		s.Name = o.Name
+	}
+	if o.ShipType != 0 {
+		s.ShipType = o.ShipType
+	}
+	if o.Length != 0 {
+		s.Length = o.Length
+	}
+	if o.Beam != 0 {
+		s.Beam = o.Beam
+	}
+	if o.Draught != 0 {
+		s.Draught = o.Draught
+	}
+	if o.Destination != "" {
+		s.Destination = o.Destination
+	}
+	if !o.ETA.IsZero() {
+		s.ETA = o.ETA
+	}
+	return s
+}
+
+// MergePositions combines positions from several sources, such as API
+// results and decoded AIS messages, keeping the newest report for each
+// vessel by Timestamp. Vessels are matched by MMSI, or by IMO number for
+// positions without one; positions with neither are dropped. The result is
+// ordered by MMSI, then IMO number.
+func MergePositions(sources ...[]vesselapi.VesselPosition) []vesselapi.VesselPosition {
+	type entry struct {
+		p  vesselapi.VesselPosition
+		at time.Time
+		ok bool
+	}
+	latest := make(map[string]entry)
+	for _, src := range sources {
+		for _, p := range src {
+			var key string
+			switch {
+			case p.Mmsi != nil:
+				key = "mmsi:" + strconv.Itoa(*p.Mmsi)
+			case p.Imo != nil:
+				key = "imo:" + strconv.Itoa(*p.Imo)
+			default:
+				continue
+			}
+			at, err := parseTimestamp(vesselapi.Deref(p.Timestamp))
+			e := entry{p, at, err == nil}
+			if cur, seen := latest[key]; !seen || (e.ok && (!cur.ok || e.at.After(cur.at))) {
+				latest[key] = e
+			}
+		}
+	}
+	out := make([]vesselapi.VesselPosition, 0, len(latest))
+	for _, e := range latest {
+		out = append(out, e.p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if mi, mj := vesselapi.Deref(out[i].Mmsi), vesselapi.Deref(out[j].Mmsi); mi != mj {
+			return mi < mj
+		}
+		return vesselapi.Deref(out[i].Imo) < vesselapi.Deref(out[j].Imo)
+	})
+	return out
+}
@@ -0,0 +1,239 @@
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+)
+
+var decodeNow = time.Date(2025, 3, 10, 12, 30, 20, 0, time.UTC)
+
+func testDecoder() *Decoder {
+	d := NewDecoder()
+	d.now = func() time.Time { return decodeNow }
+	return d
+}
+
+func withChecksum(body string) string {
+	return fmt.Sprintf("!%s*%02X", body, checksum(body))
+}
+
+func decodeOne(t *testing.T, d *Decoder, sentences ...string) *Message {
+	t.Helper()
+	var msg *Message
+	for i, s := range sentences {
+		m, err := d.Decode(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if i < len(sentences)-1 && m != nil {
+			t.Fatalf("expected no message before the last fragment, got %+v", m)
+		}
+		msg = m
+	}
+	if msg == nil {
+		t.Fatal("expected a message")
+	}
+	return msg
+}
+
+func near(got *float64, want float64) bool {
+	return got != nil && math.Abs(*got-want) < 1e-5
+}
+
+func TestDecode_PositionReport(t *testing.T) {
+	m := decodeOne(t, testDecoder(), "!AIVDM,1,1,,B,177KQJ5000G?tO`K>RA1wUbN0TKH,0*5C")
+	p := m.Position
+	if m.Type != 1 || m.MMSI != 477553000 || m.Channel != 'B' || m.Own || p == nil {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	if vesselapi.Deref(p.NavStatus) != 5 || vesselapi.Deref(p.Sog) != 0 || vesselapi.Deref(p.Cog) != 51 || vesselapi.Deref(p.Heading) != 181 {
+		t.Errorf("unexpected position %+v", p)
+	}
+	if !near(p.Latitude, 47.582833) || !near(p.Longitude, -122.345833) {
+		t.Errorf("unexpected coordinates %v, %v", vesselapi.Deref(p.Latitude), vesselapi.Deref(p.Longitude))
+	}
+	// Second 15 is after the decoder's clock (second 20) in the same
+	// minute.
+	if got := vesselapi.Deref(p.Timestamp); got != "2025-03-10T12:30:15Z" {
+		t.Errorf("expected timestamp 2025-03-10T12:30:15Z, got %s", got)
+	}
+}
+
+func TestDecode_StaticAndVoyage(t *testing.T) {
+	m := decodeOne(t, testDecoder(),
+		"!AIVDM,2,1,1,A,55?MbV02;H;s<HtKR20EHE:0@T4@Dn2222222216L961O5Gf0NSQEp6ClRp8,0*1C",
+		"!AIVDM,2,2,1,A,88888888880,2*25",
+	)
+	s := m.Static
+	if m.Type != 5 || s == nil || m.ETA == nil {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	if s.IMO != 9134270 || s.CallSign != "3FOF8" || s.Name != "EVER DIADEM" || s.ShipType != 70 || s.Destination != "NEW YORK" {
+		t.Errorf("unexpected static data %+v", s)
+	}
+	if s.Length != 295 || s.Beam != 32 || s.Draught != 12.2 {
+		t.Errorf("unexpected dimensions %+v", s)
+	}
+	if want := time.Date(2025, 5, 15, 14, 0, 0, 0, time.UTC); !s.ETA.Equal(want) {
+		t.Errorf("expected ETA %v, got %v", want, s.ETA)
+	}
+	e := m.ETA
+	if vesselapi.Deref(e.Eta) != "05-15 14:00" || vesselapi.Deref(e.Imo) != 9134270 || vesselapi.Deref(e.Draught) != float32(12.2) {
+		t.Errorf("unexpected ETA %+v", e)
+	}
+	if eta, err := vesselapi.ResolveETA(*e); err != nil || !eta.Equal(s.ETA) {
+		t.Errorf("expected the ETA to resolve to %v, got %v (%v)", s.ETA, eta, err)
+	}
+}
+
+func TestDecode_ClassB(t *testing.T) {
+	d := testDecoder()
+	m := decodeOne(t, d, "!AIVDM,1,1,,A,B52K>;h00Fc>jpUlNV@ikwpUoP06,0*4C")
+	p := m.Position
+	if m.Type != 18 || m.MMSI != 338087471 || p == nil {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	if vesselapi.Deref(p.Sog) != float32(0.1) || vesselapi.Deref(p.Cog) != float32(79.6) || p.Heading != nil {
+		t.Errorf("unexpected position %+v", p)
+	}
+	// Second 49 has not yet come this minute, so it was the previous one.
+	if got := vesselapi.Deref(p.Timestamp); got != "2025-03-10T12:29:49Z" {
+		t.Errorf("expected timestamp 2025-03-10T12:29:49Z, got %s", got)
+	}
+
+	m = decodeOne(t, d, "!AIVDM,1,1,,B,C5N3SRgPEnJGEBT>NhWAwwo862PaLELTBJ:V00000000S0D:R220,0*0B")
+	if m.Type != 19 || m.Position == nil || m.Static == nil {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	if vesselapi.Deref(m.Position.VesselName) != "CAPT.J.RIMES" || m.Static.ShipType != 70 || m.Static.Length != 26 || m.Static.Beam != 8 {
+		t.Errorf("unexpected type 19 data %+v %+v", m.Position, m.Static)
+	}
+
+	a := decodeOne(t, d, "!AIVDM,1,1,,A,H42O55i18tMET00000000000000,2*6D")
+	b := decodeOne(t, d, "!AIVDM,1,1,,A,H42O55lti4hhhilD3nink000?050,0*40")
+	s := a.Static.Merge(*b.Static)
+	if s.MMSI != 271041815 || s.Name != "PROGUY" || s.CallSign != "TC6163" || s.ShipType != 60 || s.Length != 15 || s.Beam != 5 {
+		t.Errorf("unexpected merged type 24 data %+v", s)
+	}
+}
+
+func TestDecode_BaseStationAndLongRange(t *testing.T) {
+	d := testDecoder()
+	m := decodeOne(t, d, "!AIVDM,1,1,,A,403OviQuMGCqWrRO9>E6fE700@GO,0*4D")
+	if m.Type != 4 || m.MMSI != 3669702 || !near(m.Position.Latitude, 36.883767) {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	if got := vesselapi.Deref(m.Position.Timestamp); got != "2007-05-14T19:57:39Z" {
+		t.Errorf("expected the reported UTC time, got %s", got)
+	}
+
+	m = decodeOne(t, d, withChecksum("AIVDM,1,1,,B,KC5E2b@U19PFdLbL,0"))
+	p := m.Position
+	if m.Type != 27 || m.MMSI != 206914217 || vesselapi.Deref(p.NavStatus) != 2 {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	if !near(p.Latitude, 4.84) || !near(p.Longitude, 137.023333) || vesselapi.Deref(p.Sog) != 57 || vesselapi.Deref(p.Cog) != 167 {
+		t.Errorf("unexpected position %+v", p)
+	}
+}
+
+func TestDecode_TagBlockAndOwnVessel(t *testing.T) {
+	m := decodeOne(t, testDecoder(), `\s:rx1,c:1700000000*00\`+"!AIVDO,1,1,,B,177KQJ5000G?tO`K>RA1wUbN0TKH,0*5E")
+	if !m.Own {
+		t.Error("expected an own-vessel message")
+	}
+	if want := time.Unix(1700000000, 0).UTC(); !m.ReceivedAt.Equal(want) {
+		t.Errorf("expected received time %v, got %v", want, m.ReceivedAt)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	d := testDecoder()
+	for _, s := range []string{
+		"!AIVDM,1,1,,B,177KQJ5000G?tO`K>RA1wUbN0TKH,0*5D", // bad checksum
+		"$GPGGA,1,2,3*00",
+		withChecksum("AIVDM,1,1,,B,1~~,0"),
+		withChecksum("AIVDM,1,1,,B,1,0"),
+	} {
+		if _, err := d.Decode(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+	// Type 8, binary broadcast.
+	if _, err := d.Decode(withChecksum("AIVDM,1,1,,A,85Mwp`1Kf3aCnsNvBWLi=wQuNhA5t43N`5nCuI=p<IBfVqnMgPGs,0")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	enc := NewEncoder(nil)
+	p := vesselapi.VesselPosition{
+		Mmsi:      vesselapi.Ptr(244660000),
+		NavStatus: vesselapi.Ptr(0),
+		Sog:       vesselapi.Ptr(float32(14.2)),
+		Cog:       vesselapi.Ptr(float32(93.5)),
+		Heading:   vesselapi.Ptr(94),
+		Latitude:  vesselapi.Ptr(51.95),
+		Longitude: vesselapi.Ptr(4.05),
+		Timestamp: vesselapi.Ptr("2025-03-10T12:30:05Z"),
+	}
+	s, err := enc.EncodePosition(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := decodeOne(t, testDecoder(), s...).Position
+	if vesselapi.Deref(got.Sog) != 14.2 || vesselapi.Deref(got.Cog) != 93.5 || vesselapi.Deref(got.Heading) != 94 ||
+		!near(got.Latitude, 51.95) || !near(got.Longitude, 4.05) || vesselapi.Deref(got.Timestamp) != "2025-03-10T12:30:05Z" {
+		t.Errorf("round trip changed the position: %+v", got)
+	}
+
+	sd := StaticData{MMSI: 244660000, IMO: 9811000, Name: "EVER GIVEN", CallSign: "H3RC", ShipType: 70, Length: 400, Beam: 59, Draught: 14.5, Destination: "NLRTM"}
+	s, err = enc.EncodeStatic(sd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotSD := *decodeOne(t, testDecoder(), s...).Static; gotSD != sd {
+		t.Errorf("round trip changed the static data: expected %+v, got %+v", sd, gotSD)
+	}
+}
+
+func TestMergePositions(t *testing.T) {
+	pos := func(mmsi, imo int, ts string) vesselapi.VesselPosition {
+		p := vesselapi.VesselPosition{Timestamp: vesselapi.Ptr(ts)}
+		if mmsi != 0 {
+			p.Mmsi = vesselapi.Ptr(mmsi)
+		}
+		if imo != 0 {
+			p.Imo = vesselapi.Ptr(imo)
+		}
+		return p
+	}
+	api := []vesselapi.VesselPosition{
+		pos(2, 0, "2025-01-01T00:00:00Z"),
+		pos(1, 9811000, "2025-01-01T00:05:00Z"),
+		pos(0, 0, "2025-01-01T00:00:00Z"),
+	}
+	local := []vesselapi.VesselPosition{
+		pos(2, 0, "2025-01-01T00:01:00Z"),
+		pos(1, 0, "2025-01-01T00:04:00Z"),
+		pos(0, 7, "2025-01-01T00:00:00Z"),
+	}
+	got := MergePositions(api, local)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 positions, got %d", len(got))
+	}
+	if vesselapi.Deref(got[0].Imo) != 7 {
+		t.Errorf("expected the IMO-only position first, got %+v", got[0])
+	}
+	if vesselapi.Deref(got[1].Mmsi) != 1 || vesselapi.Deref(got[1].Imo) != 9811000 {
+		t.Errorf("expected the newer API position for MMSI 1, got %+v", got[1])
+	}
+	if vesselapi.Deref(got[2].Timestamp) != "2025-01-01T00:01:00Z" {
+		t.Errorf("expected the newer local position for MMSI 2, got %+v", got[2])
+	}
+}
@@ -0,0 +1,343 @@
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+)
+
+// AIS "not available" values, in the units of the encoded fields.
+const (
+	navStatusNotDefined = 15
+	rotNotAvailable     = -128
+	sogNotAvailable     = 1023
+	lonNotAvailable     = 181 * 600000
+	latNotAvailable     = 91 * 600000
+	cogNotAvailable     = 3600
+	headingNotAvailable = 511
+	secondNotAvailable  = 60
+)
+
+// EncoderOptions configures an Encoder. A nil *EncoderOptions uses the
+// defaults.
+type EncoderOptions struct {
+	// Formatter is the sentence formatter: "AIVDM" (default) for reports
+	// of other vessels, or "AIVDO" for the receiver's own vessel.
+	Formatter string
+
+	// Channel is the AIS radio channel, 'A' (default) or 'B'.
+	Channel byte
+
+	// PositionType is the message type used for position reports: 1
+	// (default) for scheduled reports or 3 for special reports.
+	PositionType int
+}
+
+// Encoder encodes positions and static vessel data as !AIVDM sentences.
+// It is safe for concurrent use.
+type Encoder struct {
+	opts EncoderOptions
+
+	mu    sync.Mutex
+	seqID int
+}
+
+// NewEncoder returns an Encoder.
+func NewEncoder(opts *EncoderOptions) *Encoder {
+	o := EncoderOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Formatter == "" {
+		o.Formatter = "AIVDM"
+	}
+	if o.Channel == 0 {
+		o.Channel = 'A'
+	}
+	if o.PositionType != 3 {
+		o.PositionType = 1
+	}
+	return &Encoder{opts: o}
+}
+
+// EncodePosition encodes p as a class A position report, message type 1
+// or 3. Missing fields, and the API's own "not available" values, are
+// encoded as AIS "not available". The time stamp field carries the second
+// of p.Timestamp. It returns an error if p has no valid MMSI.
+func (e *Encoder) EncodePosition(p vesselapi.VesselPosition) ([]string, error) {
+	mmsi, err := checkMMSI(p.Mmsi)
+	if err != nil {
+		return nil, err
+	}
+	var w bitWriter
+	w.uint(uint64(e.opts.PositionType), 6)
+	w.uint(0, 2) // repeat indicator
+	w.uint(mmsi, 30)
+	w.uint(uint64(navStatus(p.NavStatus)), 4)
+	w.int(rotNotAvailable, 8)
+	w.uint(uint64(sog(p.Sog)), 10)
+	w.uint(0, 1) // position accuracy
+	w.int(coord(p.Longitude, 180, lonNotAvailable), 28)
+	w.int(coord(p.Latitude, 90, latNotAvailable), 27)
+	w.uint(uint64(cog(p.Cog)), 12)
+	w.uint(uint64(heading(p.Heading)), 9)
+	w.uint(uint64(second(p.Timestamp)), 6)
+	w.uint(0, 2)  // maneuver indicator
+	w.uint(0, 3)  // spare
+	w.uint(0, 1)  // RAIM
+	w.uint(0, 19) // radio status
+	return e.sentences(&w), nil
+}
+
+// StaticData is the static and voyage related data of a vessel, as carried
+// by AIS message type 5.
+type StaticData struct {
+	MMSI     int
+	IMO      int
+	CallSign string
+	Name     string
+
+	// ShipType is the AIS ship and cargo type code; 0 means not available.
+	ShipType int
+
+	// Length and Beam are the vessel's dimensions in meters. The antenna
+	// is assumed to be amidships.
+	Length float64
+	Beam   float64
+
+	// Draught is the present draught in meters.
+	Draught float64
+
+	Destination string
+
+	// ETA is the estimated time of arrival; the zero time means not
+	// available.
+	ETA time.Time
+}
+
+// NewStaticData builds the static data of a vessel from its registry
+// record and, if known, its latest ETA report. Either may be nil. The ETA
+// report supplies the draught, destination and ETA, and the MMSI, IMO and
+// name where the vessel record lacks them.
+func NewStaticData(v *vesselapi.Vessel, eta *vesselapi.VesselETA) StaticData {
+	var s StaticData
+	if v != nil {
+		s.MMSI = vesselapi.Deref(v.Mmsi)
+		s.IMO = vesselapi.Deref(v.Imo)
+		s.CallSign = vesselapi.Deref(v.CallSign)
+		s.Name = vesselapi.Deref(v.Name)
+		s.ShipType = ShipTypeCode(vesselapi.Deref(v.VesselType))
+		s.Length = meters(v.Length, v.LengthUnit)
+		s.Beam = meters(v.Breadth, v.BreadthUnit)
+		s.Draught = meters(v.Draft, v.DraftUnit)
+	}
+	if eta != nil {
+		if s.MMSI == 0 {
+			s.MMSI = vesselapi.Deref(eta.Mmsi)
+		}
+		if s.IMO == 0 {
+			s.IMO = vesselapi.Deref(eta.Imo)
+		}
+		if s.Name == "" {
+			s.Name = vesselapi.Deref(eta.VesselName)
+		}
+		if eta.Draught != nil {
+			s.Draught = float64(*eta.Draught)
+		}
+		s.Destination = vesselapi.Deref(eta.Destination)
+		if t, err := vesselapi.ResolveETA(*eta); err == nil {
+			s.ETA = t
+		}
+	}
+	return s
+}
+
+// EncodeStatic encodes s as message type 5, which spans two sentences. It
+// returns an error if s has no valid MMSI.
+func (e *Encoder) EncodeStatic(s StaticData) ([]string, error) {
+	mmsi, err := checkMMSI(&s.MMSI)
+	if err != nil {
+		return nil, err
+	}
+	var w bitWriter
+	w.uint(5, 6)
+	w.uint(0, 2) // repeat indicator
+	w.uint(mmsi, 30)
+	w.uint(0, 2) // AIS version
+	w.uint(uint64(clamp(s.IMO, 0, 1<<30-1)), 30)
+	w.text(s.CallSign, 7)
+	w.text(s.Name, 20)
+	w.uint(uint64(clamp(s.ShipType, 0, 255)), 8)
+	bow := clamp(int(math.Round(s.Length/2)), 0, 511)
+	stern := clamp(int(math.Round(s.Length))-bow, 0, 511)
+	port := clamp(int(math.Round(s.Beam/2)), 0, 63)
+	starboard := clamp(int(math.Round(s.Beam))-port, 0, 63)
+	w.uint(uint64(bow), 9)
+	w.uint(uint64(stern), 9)
+	w.uint(uint64(port), 6)
+	w.uint(uint64(starboard), 6)
+	w.uint(1, 4) // EPFD: GPS
+	if s.ETA.IsZero() {
+		w.uint(0, 4)
+		w.uint(0, 5)
+		w.uint(24, 5)
+		w.uint(60, 6)
+	} else {
+		eta := s.ETA.UTC()
+		w.uint(uint64(eta.Month()), 4)
+		w.uint(uint64(eta.Day()), 5)
+		w.uint(uint64(eta.Hour()), 5)
+		w.uint(uint64(eta.Minute()), 6)
+	}
+	w.uint(uint64(clamp(int(math.Round(s.Draught*10)), 0, 255)), 8)
+	w.text(s.Destination, 20)
+	w.uint(0, 1) // DTE ready
+	w.uint(0, 1) // spare
+	return e.sentences(&w), nil
+}
+
+// sentences armors and fragments a message, assigning a sequential message
+// ID to multi-sentence messages.
+func (e *Encoder) sentences(w *bitWriter) []string {
+	payload, fill := w.armor()
+	seqID := 0
+	if len(payload) > maxFragmentPayload {
+		e.mu.Lock()
+		seqID = e.seqID
+		e.seqID = (e.seqID + 1) % 10
+		e.mu.Unlock()
+	}
+	return fragment(e.opts.Formatter, e.opts.Channel, seqID, payload, fill)
+}
+
+// shipTypes maps keywords of the API's vessel type descriptions to AIS
+// ship and cargo type codes. More specific keywords come first.
+var shipTypes = []struct {
+	keyword string
+	code    int
+}{
+	{"wing in ground", 20},
+	{"fishing", 30},
+	{"towing", 31},
+	{"dredg", 33},
+	{"diving", 34},
+	{"military", 35},
+	{"naval", 35},
+	{"sailing", 36},
+	{"yacht", 37},
+	{"pleasure", 37},
+	{"high speed", 40},
+	{"pilot", 50},
+	{"search and rescue", 51},
+	{"rescue", 51},
+	{"tug", 52},
+	{"tender", 53},
+	{"anti-pollution", 54},
+	{"pollution", 54},
+	{"law enforcement", 55},
+	{"patrol", 55},
+	{"medical", 58},
+	{"hospital", 58},
+	{"tanker", 80},
+	{"gas carrier", 80},
+	{"lng", 80},
+	{"lpg", 80},
+	{"passenger", 60},
+	{"ferry", 60},
+	{"cruise", 60},
+	{"cargo", 70},
+	{"container", 70},
+	{"bulk", 70},
+	{"carrier", 70},
+	{"ro-ro", 70},
+	{"reefer", 70},
+}
+
+// ShipTypeCode maps a vessel type description such as "Container Ship" or
+// "Crude Oil Tanker" to the AIS ship and cargo type code, or 0 when the
+// description is not recognized.
+func ShipTypeCode(vesselType string) int {
+	t := strings.ToLower(vesselType)
+	for _, st := range shipTypes {
+		if strings.Contains(t, st.keyword) {
+			return st.code
+		}
+	}
+	return 0
+}
+
+func checkMMSI(mmsi *int) (uint64, error) {
+	if mmsi == nil || *mmsi == 0 {
+		return 0, errors.New("nmea: vessel has no MMSI")
+	}
+	if *mmsi < 0 || *mmsi > 999999999 {
+		return 0, fmt.Errorf("nmea: invalid MMSI %d", *mmsi)
+	}
+	return uint64(*mmsi), nil
+}
+
+func navStatus(v *int) int {
+	if v == nil || *v < 0 || *v > 15 {
+		return navStatusNotDefined
+	}
+	return *v
+}
+
+func sog(v *float32) int {
+	if v == nil || *v < 0 || *v >= 102.3 {
+		return sogNotAvailable
+	}
+	return min(int(math.Round(float64(*v)*10)), 1022)
+}
+
+func cog(v *float32) int {
+	if v == nil || *v < 0 || *v >= 360 {
+		return cogNotAvailable
+	}
+	return int(math.Round(float64(*v)*10)) % 3600
+}
+
+func heading(v *int) int {
+	if v == nil || *v < 0 || *v > 359 {
+		return headingNotAvailable
+	}
+	return *v
+}
+
+// coord returns a latitude or longitude in 1/10000 minutes.
+func coord(v *float64, limit float64, notAvailable int64) int64 {
+	if v == nil || math.IsNaN(*v) || math.Abs(*v) > limit {
+		return notAvailable
+	}
+	return int64(math.Round(*v * 600000))
+}
+
+func second(ts *string) int {
+	t, err := parseTimestamp(vesselapi.Deref(ts))
+	if err != nil {
+		return secondNotAvailable
+	}
+	return t.Second()
+}
+
+// meters converts a registry dimension to meters. Units other than feet
+// are taken to be meters.
+func meters(v *int, unit *string) float64 {
+	if v == nil {
+		return 0
+	}
+	switch strings.ToLower(vesselapi.Deref(unit)) {
+	case "ft", "feet", "foot":
+		return float64(*v) * 0.3048
+	}
+	return float64(*v)
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
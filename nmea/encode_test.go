@@ -0,0 +1,247 @@
+package nmea
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+)
+
+// payloadBits unarmors the payloads of the sentences of one message.
+func payloadBits(t *testing.T, sentences []string) []byte {
+	t.Helper()
+	var bits []byte
+	for _, s := range sentences {
+		star := strings.IndexByte(s, '*')
+		if star < 0 || !strings.HasPrefix(s, "!") {
+			t.Fatalf("malformed sentence %q", s)
+		}
+		if got, want := s[star+1:], fmt.Sprintf("%02X", checksum(s[1:star])); got != want {
+			t.Errorf("expected checksum %s, got %s in %q", want, got, s)
+		}
+		fields := strings.Split(s[1:star], ",")
+		for _, c := range []byte(fields[5]) {
+			v := c - 48
+			if v > 40 {
+				v -= 8
+			}
+			for i := 5; i >= 0; i-- {
+				bits = append(bits, v>>uint(i)&1)
+			}
+		}
+	}
+	return bits
+}
+
+func field(bits []byte, start, n int) uint64 {
+	var v uint64
+	for _, b := range bits[start : start+n] {
+		v = v<<1 | uint64(b)
+	}
+	return v
+}
+
+func signed(bits []byte, start, n int) int64 {
+	v := int64(field(bits, start, n))
+	if v&(1<<uint(n-1)) != 0 {
+		v -= 1 << uint(n)
+	}
+	return v
+}
+
+func TestChecksum(t *testing.T) {
+	// A published type 1 sentence.
+	if cs := checksum("AIVDM,1,1,,B,177KQJ5000G?tO`K>RA1wUbN0TKH,0"); cs != 0x5C {
+		t.Errorf("expected checksum 5C, got %02X", cs)
+	}
+}
+
+func TestEncodePosition(t *testing.T) {
+	p := vesselapi.VesselPosition{
+		Mmsi:      vesselapi.Ptr(477553000),
+		NavStatus: vesselapi.Ptr(5),
+		Sog:       vesselapi.Ptr(float32(12.3)),
+		Cog:       vesselapi.Ptr(float32(51.2)),
+		Heading:   vesselapi.Ptr(181),
+		Latitude:  vesselapi.Ptr(47.582833),
+		Longitude: vesselapi.Ptr(-122.345833),
+		Timestamp: vesselapi.Ptr("2025-01-01T10:20:15Z"),
+	}
+	s, err := NewEncoder(nil).EncodePosition(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s) != 1 || !strings.HasPrefix(s[0], "!AIVDM,1,1,,A,") || !strings.Contains(s[0], ",0*") {
+		t.Fatalf("unexpected sentences %q", s)
+	}
+	bits := payloadBits(t, s)
+	if len(bits) != 168 {
+		t.Fatalf("expected 168 bits, got %d", len(bits))
+	}
+	checks := []struct {
+		name      string
+		got, want int64
+	}{
+		{"type", int64(field(bits, 0, 6)), 1},
+		{"mmsi", int64(field(bits, 8, 30)), 477553000},
+		{"status", int64(field(bits, 38, 4)), 5},
+		{"rot", signed(bits, 42, 8), -128},
+		{"sog", int64(field(bits, 50, 10)), 123},
+		{"lon", signed(bits, 61, 28), -73407500},
+		{"lat", signed(bits, 89, 27), 28549700},
+		{"cog", int64(field(bits, 116, 12)), 512},
+		{"heading", int64(field(bits, 128, 9)), 181},
+		{"second", int64(field(bits, 137, 6)), 15},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("expected %s %d, got %d", c.name, c.want, c.got)
+		}
+	}
+}
+
+func TestEncodePosition_NotAvailable(t *testing.T) {
+	enc := NewEncoder(&EncoderOptions{Formatter: "AIVDO", Channel: 'B', PositionType: 3})
+	s, err := enc.EncodePosition(vesselapi.VesselPosition{
+		Mmsi: vesselapi.Ptr(1),
+		Sog:  vesselapi.Ptr(float32(102.3)),
+		Cog:  vesselapi.Ptr(float32(360)),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(s[0], "!AIVDO,1,1,,B,") {
+		t.Errorf("unexpected sentence %q", s[0])
+	}
+	bits := payloadBits(t, s)
+	for _, c := range []struct {
+		name      string
+		got, want uint64
+	}{
+		{"type", field(bits, 0, 6), 3},
+		{"status", field(bits, 38, 4), 15},
+		{"sog", field(bits, 50, 10), 1023},
+		{"lon", field(bits, 61, 28), 181 * 600000},
+		{"lat", field(bits, 89, 27), 91 * 600000},
+		{"cog", field(bits, 116, 12), 3600},
+		{"heading", field(bits, 128, 9), 511},
+		{"second", field(bits, 137, 6), 60},
+	} {
+		if c.got != c.want {
+			t.Errorf("expected %s %d, got %d", c.name, c.want, c.got)
+		}
+	}
+
+	if _, err := enc.EncodePosition(vesselapi.VesselPosition{Imo: vesselapi.Ptr(9811000)}); err == nil {
+		t.Error("expected an error for a position without an MMSI")
+	}
+}
+
+func sixBitText(bits []byte, start, chars int) string {
+	var b strings.Builder
+	for i := 0; i < chars; i++ {
+		c := byte(field(bits, start+6*i, 6))
+		if c < 32 {
+			c += '@'
+		}
+		b.WriteByte(c)
+	}
+	return strings.TrimRight(b.String(), "@ ")
+}
+
+func TestEncodeStatic(t *testing.T) {
+	v := &vesselapi.Vessel{
+		Mmsi:       vesselapi.Ptr(353136000),
+		Imo:        vesselapi.Ptr(9811000),
+		Name:       vesselapi.Ptr("Ever Given"),
+		CallSign:   vesselapi.Ptr("H3RC"),
+		VesselType: vesselapi.Ptr("Container Ship"),
+		Length:     vesselapi.Ptr(400),
+		Breadth:    vesselapi.Ptr(59),
+	}
+	eta := &vesselapi.VesselETA{
+		Destination: vesselapi.Ptr("NL RTM"),
+		Draught:     vesselapi.Ptr(float32(14.5)),
+		Eta:         vesselapi.Ptr("03-15 06:30"),
+		Timestamp:   vesselapi.Ptr("2025-03-10T00:00:00Z"),
+	}
+	sd := NewStaticData(v, eta)
+	if sd.ShipType != 70 {
+		t.Errorf("expected ship type 70, got %d", sd.ShipType)
+	}
+	if want := time.Date(2025, 3, 15, 6, 30, 0, 0, time.UTC); !sd.ETA.Equal(want) {
+		t.Errorf("expected ETA %v, got %v", want, sd.ETA)
+	}
+
+	enc := NewEncoder(nil)
+	s, err := enc.EncodeStatic(sd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s) != 2 || !strings.HasPrefix(s[0], "!AIVDM,2,1,0,A,") || !strings.HasPrefix(s[1], "!AIVDM,2,2,0,A,") {
+		t.Fatalf("unexpected sentences %q", s)
+	}
+	if !strings.HasSuffix(strings.Split(s[1], "*")[0], ",2") {
+		t.Errorf("expected 2 fill bits on the last fragment, got %q", s[1])
+	}
+	bits := payloadBits(t, s)
+	if len(bits) != 426 {
+		t.Fatalf("expected 424 bits plus 2 fill bits, got %d", len(bits))
+	}
+	for _, c := range []struct {
+		name      string
+		got, want uint64
+	}{
+		{"type", field(bits, 0, 6), 5},
+		{"mmsi", field(bits, 8, 30), 353136000},
+		{"imo", field(bits, 40, 30), 9811000},
+		{"ship type", field(bits, 232, 8), 70},
+		{"bow", field(bits, 240, 9), 200},
+		{"stern", field(bits, 249, 9), 200},
+		{"port", field(bits, 258, 6), 30},
+		{"starboard", field(bits, 264, 6), 29},
+		{"month", field(bits, 274, 4), 3},
+		{"day", field(bits, 278, 5), 15},
+		{"hour", field(bits, 283, 5), 6},
+		{"minute", field(bits, 288, 6), 30},
+		{"draught", field(bits, 294, 8), 145},
+	} {
+		if c.got != c.want {
+			t.Errorf("expected %s %d, got %d", c.name, c.want, c.got)
+		}
+	}
+	if got := sixBitText(bits, 70, 7); got != "H3RC" {
+		t.Errorf("expected call sign H3RC, got %q", got)
+	}
+	if got := sixBitText(bits, 112, 20); got != "EVER GIVEN" {
+		t.Errorf("expected name EVER GIVEN, got %q", got)
+	}
+	if got := sixBitText(bits, 302, 20); got != "NL RTM" {
+		t.Errorf("expected destination NL RTM, got %q", got)
+	}
+
+	// Consecutive multi-sentence messages get new sequential message IDs.
+	s, _ = enc.EncodeStatic(sd)
+	if !strings.HasPrefix(s[0], "!AIVDM,2,1,1,A,") {
+		t.Errorf("expected sequential message ID 1, got %q", s[0])
+	}
+}
+
+func TestShipTypeCode(t *testing.T) {
+	for in, want := range map[string]int{
+		"Container Ship":   70,
+		"Crude Oil Tanker": 80,
+		"LNG Carrier":      80,
+		"Bulk Carrier":     70,
+		"Passenger Ship":   60,
+		"Tug":              52,
+		"Fishing Vessel":   30,
+		"Unknown":          0,
+	} {
+		if got := ShipTypeCode(in); got != want {
+			t.Errorf("expected %s to map to %d, got %d", in, want, got)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package nmea converts between SDK models and AIS messages carried in
+// NMEA 0183 !AIVDM sentences, for chart plotters, VTS software and other
+// tools that consume raw AIS.
+package nmea
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxFragmentPayload is the number of payload characters per sentence. It
+// keeps every sentence within the 82 characters NMEA 0183 allows.
+const maxFragmentPayload = 60
+
+// bitWriter accumulates an AIS message bit by bit, most significant first.
+type bitWriter struct {
+	bits []byte
+}
+
+// uint appends the low n bits of v.
+func (w *bitWriter) uint(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		w.bits = append(w.bits, byte(v>>uint(i))&1)
+	}
+}
+
+// int appends v as an n-bit two's complement number.
+func (w *bitWriter) int(v int64, n int) {
+	w.uint(uint64(v)&(1<<uint(n)-1), n)
+}
+
+// text appends s in the AIS 6-bit character set, upper-cased, truncated or
+// padded with '@' to chars characters. Characters outside the set are
+// written as '?'.
+func (w *bitWriter) text(s string, chars int) {
+	s = strings.ToUpper(s)
+	for i := 0; i < chars; i++ {
+		c := byte('@')
+		if i < len(s) {
+			c = s[i]
+		}
+		switch {
+		case c >= '@' && c <= '_':
+			c -= '@'
+		case c >= ' ' && c <= '?':
+		default:
+			c = '?'
+		}
+		w.uint(uint64(c), 6)
+	}
+}
+
+// armor returns the message as 6-bit armored payload characters and the
+// number of fill bits added to complete the last character.
+func (w *bitWriter) armor() (string, int) {
+	fill := (6 - len(w.bits)%6) % 6
+	bits := append(w.bits, make([]byte, fill)...)
+	var b strings.Builder
+	for i := 0; i < len(bits); i += 6 {
+		v := byte(0)
+		for _, bit := range bits[i : i+6] {
+			v = v<<1 | bit
+		}
+		if v < 40 {
+			v += 48
+		} else {
+			v += 56
+		}
+		b.WriteByte(v)
+	}
+	return b.String(), fill
+}
+
+// checksum returns the XOR of the bytes of a sentence body, the characters
+// between the leading '!' and the '*'.
+func checksum(body string) byte {
+	var cs byte
+	for i := 0; i < len(body); i++ {
+		cs ^= body[i]
+	}
+	return cs
+}
+
+// fragment splits an armored payload into one or more sentences. seqID is
+// the sequential message ID that ties the fragments of a multi-sentence
+// message together; it is left empty for single-sentence messages.
+func fragment(formatter string, channel byte, seqID int, payload string, fill int) []string {
+	total := (len(payload) + maxFragmentPayload - 1) / maxFragmentPayload
+	if total == 0 {
+		total = 1
+	}
+	seq := ""
+	if total > 1 {
+		seq = fmt.Sprint(seqID)
+	}
+	out := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		part := payload[i*maxFragmentPayload : min((i+1)*maxFragmentPayload, len(payload))]
+		f := 0
+		if i == total-1 {
+			f = fill
+		}
+		body := fmt.Sprintf("%s,%d,%d,%s,%c,%s,%d", formatter, total, i+1, seq, channel, part, f)
+		out = append(out, fmt.Sprintf("!%s*%02X", body, checksum(body)))
+	}
+	return out
+}
+
+// timestampLayouts are the formats the API uses for timestamp fields, most
+// specific first.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+}
+
+// parseTimestamp parses an API timestamp string and returns it in UTC.
+// Timestamps without a zone offset are interpreted as UTC.
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("nmea: unrecognized timestamp %q", s)
+}
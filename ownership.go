@@ -0,0 +1,329 @@
+package vesselapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultChangeInterval = 6 * time.Hour
+
+// VesselChangeType identifies the kind of change a VesselChange reports.
+type VesselChangeType string
+
+const (
+	// ChangeOwner reports a new registered owner.
+	ChangeOwner VesselChangeType = "owner_changed"
+
+	// ChangeManager reports a new ship manager.
+	ChangeManager VesselChangeType = "manager_changed"
+
+	// ChangeDocCompany reports a new ISM Document of Compliance holder.
+	ChangeDocCompany VesselChangeType = "doc_company_changed"
+
+	// ChangeRenamed reports a new vessel name.
+	ChangeRenamed VesselChangeType = "renamed"
+
+	// ChangeReflagged reports a new flag state.
+	ChangeReflagged VesselChangeType = "reflagged"
+
+	// ChangeError reports a failed fetch. The detector keeps running.
+	ChangeError VesselChangeType = "error"
+)
+
+// VesselChange is a change detected by a ChangeDetector.
+type VesselChange struct {
+	Type VesselChangeType
+
+	// ID is the watched IMO or MMSI number.
+	ID string
+
+	// Old and New are the previous and current values: names for owner,
+	// manager, DOC company and rename changes, and flag country codes (or
+	// country names when a code is missing) for reflagging.
+	Old string
+	New string
+
+	// Since is when Old was last observed, and At when New was first
+	// observed; the change happened in between.
+	Since time.Time
+	At    time.Time
+
+	// Err is the fetch error for ChangeError events.
+	Err error
+}
+
+// ChangeDetectorOptions configures a ChangeDetector. A nil
+// *ChangeDetectorOptions uses the defaults.
+type ChangeDetectorOptions struct {
+	// IDType selects whether the watched IDs are IMO (default) or MMSI
+	// numbers.
+	IDType GetVesselIdParamsFilterIdType
+
+	// Store keeps the observed state, so that changes made while the
+	// detector was stopped are reported when it restarts. Every check adds
+	// a snapshot per vessel, so a Store that keeps full history grows
+	// without bound. Defaults to a MemoryStore that keeps only the latest
+	// snapshot of each vessel.
+	Store Store
+
+	// Interval is the time between checks. Defaults to six hours.
+	Interval time.Duration
+
+	// Jitter randomizes each interval by up to this fraction in either
+	// direction. Defaults to 0.1; set a negative value to disable.
+	Jitter float64
+
+	// Concurrency is the number of vessels fetched at once. Defaults to 4.
+	Concurrency int
+
+	// Buffer is the capacity of the events channel.
+	Buffer int
+}
+
+// ChangeDetector periodically fetches the details and ownership of a watch
+// list of vessels, compares them with the previously observed state and
+// emits a VesselChange for every change of owner, manager, DOC company,
+// name or flag. Create one with NewChangeDetector, start it with Run and
+// read Events until it is closed, or call Check directly.
+//
+// A value that was never observed, or that is missing from the current
+// fetch, is not a change: the first check only records the state, and a
+// failed or empty section keeps the last known value. Values are compared
+// ignoring case and surrounding space.
+type ChangeDetector struct {
+	snap   *Snapshotter
+	store  Store
+	opts   ChangeDetectorOptions
+	events chan VesselChange
+
+	mu      sync.Mutex
+	running bool
+	known   map[string]map[string]observed
+	errs    []VesselChange
+}
+
+// observed is the last non-empty value of a tracked field and when it was
+// last seen.
+type observed struct {
+	value string
+	at    time.Time
+}
+
+// Tracked fields of a vessel's known state. The flag is tracked both by
+// country code and by country name, since either may be missing.
+const (
+	fieldOwner       = "owner"
+	fieldManager     = "manager"
+	fieldDocCompany  = "doc_company"
+	fieldName        = "name"
+	fieldFlagCode    = "flag_code"
+	fieldFlagCountry = "flag_country"
+)
+
+// trackedFields returns the non-empty tracked fields of a snapshot.
+func trackedFields(s Snapshot) map[string]string {
+	f := make(map[string]string)
+	set := func(k string, v *string) {
+		if t := strings.TrimSpace(Deref(v)); t != "" {
+			f[k] = t
+		}
+	}
+	if o := s.Ownership; o != nil {
+		set(fieldOwner, o.RegisteredOwner)
+		set(fieldManager, o.ShipManager)
+		set(fieldDocCompany, o.DocCompany)
+	}
+	if v := s.Vessel; v != nil {
+		set(fieldName, v.Name)
+		set(fieldFlagCode, v.CountryCode)
+		set(fieldFlagCountry, v.Country)
+	}
+	return f
+}
+
+// NewChangeDetector returns a ChangeDetector for the vessels with the given
+// IDs.
+func NewChangeDetector(vessels *VesselsService, ids []string, opts *ChangeDetectorOptions) *ChangeDetector {
+	o := ChangeDetectorOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Store == nil {
+		o.Store = &MemoryStore{Keep: 1}
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultChangeInterval
+	}
+	if o.Jitter == 0 {
+		o.Jitter = defaultWatchJitter
+	}
+	d := &ChangeDetector{
+		store:  o.Store,
+		opts:   o,
+		events: make(chan VesselChange, max(o.Buffer, 0)),
+		known:  make(map[string]map[string]observed),
+	}
+	// The sections are valid, so NewSnapshotter cannot fail.
+	d.snap, _ = NewSnapshotter(vessels, o.Store, ids, &SnapshotterOptions{
+		IDType:      o.IDType,
+		Sections:    []ProfileSection{ProfileDetails, ProfileOwnership},
+		Interval:    o.Interval,
+		Jitter:      o.Jitter,
+		Concurrency: o.Concurrency,
+		OnError:     d.onError,
+	})
+	return d
+}
+
+// Events returns the channel on which changes are delivered. It is closed
+// when Run returns.
+func (d *ChangeDetector) Events() <-chan VesselChange {
+	return d.events
+}
+
+// Add starts watching the given vessels from the next check.
+func (d *ChangeDetector) Add(ids ...string) {
+	d.snap.Add(ids...)
+}
+
+// Remove stops watching the given vessels. Their state stays in the store.
+func (d *ChangeDetector) Remove(ids ...string) {
+	d.snap.Remove(ids...)
+}
+
+// IDs returns the watched vessel IDs in ascending order.
+func (d *ChangeDetector) IDs() []string {
+	return d.snap.IDs()
+}
+
+// Check fetches the current state of every watched vessel, stores it and
+// returns the changes since the previous check, ordered by vessel ID. Fetch
+// failures are returned as ChangeError changes. An error is returned only
+// if ctx is cancelled or the store fails.
+func (d *ChangeDetector) Check(ctx context.Context) ([]VesselChange, error) {
+	for _, id := range d.snap.IDs() {
+		if err := d.load(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	snaps, err := d.snap.Capture(ctx)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	changes := d.errs
+	d.errs = nil
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range snaps {
+		changes = append(changes, d.update(s)...)
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	return changes, nil
+}
+
+// onError records a section failure reported by the snapshotter.
+func (d *ChangeDetector) onError(id string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.errs = append(d.errs, VesselChange{Type: ChangeError, ID: id, At: d.snap.now().UTC(), Err: err})
+}
+
+// load seeds the known state of a vessel seen for the first time from the
+// latest stored snapshots that hold each section.
+func (d *ChangeDetector) load(ctx context.Context, id string) error {
+	d.mu.Lock()
+	_, ok := d.known[id]
+	d.mu.Unlock()
+	if ok {
+		return nil
+	}
+	history, err := d.store.History(ctx, id, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	st := make(map[string]observed)
+	for _, s := range history {
+		for k, v := range trackedFields(s) {
+			st[k] = observed{value: v, at: s.At}
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.known[id]; !ok {
+		d.known[id] = st
+	}
+	return nil
+}
+
+// update records a snapshot and returns the changes it shows. d.mu must be
+// held.
+func (d *ChangeDetector) update(s Snapshot) []VesselChange {
+	st := d.known[s.ID]
+	if st == nil {
+		st = make(map[string]observed)
+		d.known[s.ID] = st
+	}
+	cur := trackedFields(s)
+	var changes []VesselChange
+	diff := func(t VesselChangeType, field string) bool {
+		prev, had := st[field]
+		v, has := cur[field]
+		if !had || !has {
+			return false
+		}
+		if !strings.EqualFold(prev.value, v) {
+			changes = append(changes, VesselChange{Type: t, ID: s.ID, Old: prev.value, New: v, Since: prev.at, At: s.At})
+		}
+		return true
+	}
+	diff(ChangeOwner, fieldOwner)
+	diff(ChangeManager, fieldManager)
+	diff(ChangeDocCompany, fieldDocCompany)
+	diff(ChangeRenamed, fieldName)
+	if !diff(ChangeReflagged, fieldFlagCode) {
+		diff(ChangeReflagged, fieldFlagCountry)
+	}
+	for k, v := range cur {
+		st[k] = observed{value: v, at: s.At}
+	}
+	return changes
+}
+
+// Run checks immediately and then every Interval until ctx is cancelled,
+// then closes the events channel and returns nil. It returns the error of
+// a failed store access. Run may only be called once.
+func (d *ChangeDetector) Run(ctx context.Context) error {
+	d.mu.Lock()
+	if d.running {
+		d.mu.Unlock()
+		return errors.New("vesselapi: change detector already running")
+	}
+	d.running = true
+	d.mu.Unlock()
+	defer close(d.events)
+
+	for {
+		changes, err := d.Check(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, c := range changes {
+			select {
+			case d.events <- c:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		if sleepCtx(ctx, jitterDuration(d.opts.Interval, d.opts.Jitter)) != nil {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRegistry serves vessel details and ownership from mutable state.
+type fakeRegistry struct {
+	mu        sync.Mutex
+	vessels   map[string]Vessel
+	ownership map[string]TypesVesselOwnership
+	fail      bool
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	id := strings.TrimPrefix(r.URL.Path, "/vessel/")
+	id, ownership := strings.CutSuffix(id, "/ownership")
+	if f.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error":{"message":"down"}}`)
+		return
+	}
+	if ownership {
+		json.NewEncoder(w).Encode(TypesOwnershipResponse{Ownership: Ptr(f.ownership[id])})
+		return
+	}
+	json.NewEncoder(w).Encode(VesselResponse{Vessel: Ptr(f.vessels[id])})
+}
+
+func (f *fakeRegistry) set(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn()
+}
+
+func TestChangeDetector_Check(t *testing.T) {
+	reg := &fakeRegistry{
+		vessels: map[string]Vessel{
+			"9811000": {Name: Ptr("EVER GIVEN"), CountryCode: Ptr("PA")},
+			"9839131": {Name: Ptr("MSC OSCAR"), Country: Ptr("Panama")},
+		},
+		ownership: map[string]TypesVesselOwnership{
+			"9811000": {RegisteredOwner: Ptr("Higaki Sangyo"), ShipManager: Ptr("Bernhard Schulte"), DocCompany: Ptr("BSM")},
+			"9839131": {RegisteredOwner: Ptr("MSC")},
+		},
+	}
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "state.jsonl")}
+	d := NewChangeDetector(vc.Vessels, []string{"9839131", "9811000"}, &ChangeDetectorOptions{Store: store})
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := t0
+	d.snap.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	changes, err := d.Check(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes on the first check, got %+v", changes)
+	}
+
+	reg.set(func() {
+		reg.vessels["9811000"] = Vessel{Name: Ptr("EVER GIVEN"), CountryCode: Ptr("LR")}
+		reg.ownership["9811000"] = TypesVesselOwnership{RegisteredOwner: Ptr("higaki sangyo "), ShipManager: Ptr("Evergreen")}
+		reg.vessels["9839131"] = Vessel{Name: Ptr("MSC OSCAR II"), Country: Ptr("Malta")}
+	})
+	now = t0.Add(time.Hour)
+	changes, err = d.Check(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []VesselChange{
+		{Type: ChangeManager, ID: "9811000", Old: "Bernhard Schulte", New: "Evergreen"},
+		{Type: ChangeReflagged, ID: "9811000", Old: "PA", New: "LR"},
+		{Type: ChangeRenamed, ID: "9839131", Old: "MSC OSCAR", New: "MSC OSCAR II"},
+		{Type: ChangeReflagged, ID: "9839131", Old: "Panama", New: "Malta"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for i, c := range changes {
+		w := want[i]
+		if c.Type != w.Type || c.ID != w.ID || c.Old != w.Old || c.New != w.New {
+			t.Errorf("change %d: expected %+v, got %+v", i, w, c)
+		}
+		if !c.Since.Equal(t0) || !c.At.Equal(now) {
+			t.Errorf("change %d: expected the change between %v and %v, got %v and %v", i, t0, now, c.Since, c.At)
+		}
+	}
+
+	// A failed fetch is reported and keeps the known state.
+	reg.set(func() { reg.fail = true })
+	now = t0.Add(2 * time.Hour)
+	changes, _ = d.Check(ctx)
+	if len(changes) == 0 || changes[0].Type != ChangeError || changes[0].Err == nil {
+		t.Errorf("expected error changes, got %+v", changes)
+	}
+
+	// A new detector on the same store picks up the stored state.
+	reg.set(func() {
+		reg.fail = false
+		reg.ownership["9811000"] = TypesVesselOwnership{RegisteredOwner: Ptr("Evergreen Marine"), ShipManager: Ptr("Evergreen")}
+	})
+	d = NewChangeDetector(vc.Vessels, []string{"9811000"}, &ChangeDetectorOptions{Store: store})
+	now = t0.Add(3 * time.Hour)
+	d.snap.now = func() time.Time { return now }
+	changes, err = d.Check(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Type != ChangeOwner || changes[0].Old != "higaki sangyo" || changes[0].New != "Evergreen Marine" {
+		t.Errorf("expected an owner change after restart, got %+v", changes)
+	}
+}
+
+func TestChangeDetector_Run(t *testing.T) {
+	reg := &fakeRegistry{
+		vessels:   map[string]Vessel{"1": {Name: Ptr("A")}},
+		ownership: map[string]TypesVesselOwnership{"1": {}},
+	}
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := NewChangeDetector(vc.Vessels, []string{"1"}, &ChangeDetectorOptions{Interval: 10 * time.Millisecond, Jitter: -1})
+	polls := 0
+	d.snap.now = func() time.Time {
+		polls++
+		if polls == 2 {
+			reg.set(func() { reg.vessels["1"] = Vessel{Name: Ptr("B")} })
+		}
+		return time.Now()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go d.Run(ctx)
+
+	select {
+	case c := <-d.Events():
+		if c.Type != ChangeRenamed || c.Old != "A" || c.New != "B" {
+			t.Errorf("expected a rename from A to B, got %+v", c)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for a change")
+	}
+	cancel()
+	for range d.Events() {
+	}
+	if h, _ := d.store.History(context.Background(), "1", time.Time{}, time.Time{}); len(h) != 1 {
+		t.Errorf("expected the default store to keep only the latest snapshot, got %d", len(h))
+	}
+	if err := d.Run(context.Background()); err == nil {
+		t.Error("expected an error running twice")
+	}
+}
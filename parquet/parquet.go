@@ -0,0 +1,308 @@
+// Package parquet writes vessel positions, port events and emission reports
+// as Apache Parquet files for analytics engines such as DuckDB, Spark and
+// pandas.
+//
+// Each model is mapped to a typed row struct: timestamps become Parquet
+// TIMESTAMP columns adjusted to UTC, and every field the API may omit is an
+// optional column that is null when the model's pointer is nil. The package
+// is a separate module so that the core SDK does not depend on a Parquet
+// implementation.
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+)
+
+// DefaultRowGroupSize is the number of rows per row group used when
+// Options.RowGroupSize is not set.
+const DefaultRowGroupSize = 128 * 1024
+
+// writeBatch is the number of rows converted before they are handed to the
+// Parquet writer.
+const writeBatch = 1024
+
+// Iterator is the subset of vesselapi.Iterator used to stream values.
+type Iterator[T any] interface {
+	Next() bool
+	Value() T
+	Err() error
+}
+
+// Options configures the writers. A nil *Options uses the defaults.
+type Options struct {
+	// RowGroupSize is the maximum number of rows per row group. Smaller row
+	// groups lower memory use while writing; larger ones compress and scan
+	// better. Defaults to DefaultRowGroupSize.
+	RowGroupSize int64
+
+	// Compression is the codec used for column pages. Defaults to Snappy.
+	Compression compress.Codec
+}
+
+// PositionRow is the Parquet schema of a VesselPosition.
+type PositionRow struct {
+	MMSI               *int64     `parquet:"mmsi,optional"`
+	IMO                *int64     `parquet:"imo,optional"`
+	VesselName         *string    `parquet:"vessel_name,optional"`
+	Latitude           *float64   `parquet:"latitude,optional"`
+	Longitude          *float64   `parquet:"longitude,optional"`
+	SOG                *float32   `parquet:"sog,optional"`
+	COG                *float32   `parquet:"cog,optional"`
+	Heading            *int32     `parquet:"heading,optional"`
+	NavStatus          *int32     `parquet:"nav_status,optional"`
+	SuspectedGlitch    *bool      `parquet:"suspected_glitch,optional"`
+	Timestamp          *time.Time `parquet:"timestamp,optional"`
+	ProcessedTimestamp *time.Time `parquet:"processed_timestamp,optional"`
+}
+
+// PortEventRow is the Parquet schema of a PortEvent, with the port and
+// vessel references flattened into columns.
+type PortEventRow struct {
+	Event       *string    `parquet:"event,optional"`
+	Timestamp   *time.Time `parquet:"timestamp,optional"`
+	PortUnlo    *string    `parquet:"port_unlo_code,optional"`
+	PortName    *string    `parquet:"port_name,optional"`
+	PortCountry *string    `parquet:"port_country,optional"`
+	VesselIMO   *int64     `parquet:"vessel_imo,optional"`
+	VesselMMSI  *int64     `parquet:"vessel_mmsi,optional"`
+	VesselName  *string    `parquet:"vessel_name,optional"`
+}
+
+// EmissionRow is the Parquet schema of a VesselEmission. The document
+// dates are kept as the strings the API reports.
+type EmissionRow struct {
+	IMO                        *int64     `parquet:"imo,optional"`
+	Name                       *string    `parquet:"name,optional"`
+	ReportingPeriod            *string    `parquet:"reporting_period,optional"`
+	VesselType                 *string    `parquet:"vessel_type,optional"`
+	FlagCode                   *string    `parquet:"flag_code,optional"`
+	FlagName                   *string    `parquet:"flag_name,optional"`
+	HomePort                   *string    `parquet:"home_port,optional"`
+	IceClass                   *string    `parquet:"ice_class,optional"`
+	CollectedAt                *time.Time `parquet:"collected_at,optional"`
+	DocIssueDate               *string    `parquet:"doc_issue_date,optional"`
+	DocExpiryDate              *string    `parquet:"doc_expiry_date,optional"`
+	Co2EmissionsTotal          *float32   `parquet:"co2_emissions_total,optional"`
+	Co2EmissionsAtBerth        *float32   `parquet:"co2_emissions_at_berth,optional"`
+	Co2EmissionsOnLadenVoyages *float32   `parquet:"co2_emissions_on_laden_voyages,optional"`
+	Co2PerDistance             *float32   `parquet:"co2_per_distance,optional"`
+	Co2PerTransportWork        *float32   `parquet:"co2_per_transport_work,optional"`
+	FuelConsumptionTotal       *float32   `parquet:"fuel_consumption_total,optional"`
+	FuelConsumptionHfo         *float32   `parquet:"fuel_consumption_hfo,optional"`
+	FuelConsumptionLfo         *float32   `parquet:"fuel_consumption_lfo,optional"`
+	FuelConsumptionLng         *float32   `parquet:"fuel_consumption_lng,optional"`
+	FuelConsumptionMdo         *float32   `parquet:"fuel_consumption_mdo,optional"`
+	FuelConsumptionMgo         *float32   `parquet:"fuel_consumption_mgo,optional"`
+	FuelConsumptionOther       *float32   `parquet:"fuel_consumption_other,optional"`
+	FuelPerDistance            *float32   `parquet:"fuel_per_distance,optional"`
+	FuelPerTransportWork       *float32   `parquet:"fuel_per_transport_work,optional"`
+	DistanceThroughIce         *float32   `parquet:"distance_through_ice,optional"`
+	TimeAtSeaThroughIce        *float32   `parquet:"time_at_sea_through_ice,optional"`
+	TotalTimeAtSea             *float32   `parquet:"total_time_at_sea,optional"`
+	PortCallsWithinEu          *int64     `parquet:"port_calls_within_eu,optional"`
+	PortCallsOutsideEu         *int64     `parquet:"port_calls_outside_eu,optional"`
+	TechnicalEfficiency        *string    `parquet:"technical_efficiency,optional"`
+	TechnicalEfficiencyValue   *float32   `parquet:"technical_efficiency_value,optional"`
+	MonitoringMethodA          *string    `parquet:"monitoring_method_a,optional"`
+	MonitoringMethodB          *string    `parquet:"monitoring_method_b,optional"`
+	MonitoringMethodC          *string    `parquet:"monitoring_method_c,optional"`
+	MonitoringMethodD          *string    `parquet:"monitoring_method_d,optional"`
+	VerifierName               *string    `parquet:"verifier_name,optional"`
+	VerifierAddress            *string    `parquet:"verifier_address,optional"`
+	VerifierAccreditation      *string    `parquet:"verifier_accreditation,optional"`
+	SourceURL                  *string    `parquet:"source_url,optional"`
+	UniqueKey                  *string    `parquet:"unique_key,optional"`
+}
+
+// NewPositionRow converts a position to its Parquet row. An unparseable
+// timestamp is written as null.
+func NewPositionRow(p vesselapi.VesselPosition) PositionRow {
+	return PositionRow{
+		MMSI:               int64Ptr(p.Mmsi),
+		IMO:                int64Ptr(p.Imo),
+		VesselName:         p.VesselName,
+		Latitude:           p.Latitude,
+		Longitude:          p.Longitude,
+		SOG:                p.Sog,
+		COG:                p.Cog,
+		Heading:            int32Ptr(p.Heading),
+		NavStatus:          int32Ptr(p.NavStatus),
+		SuspectedGlitch:    p.SuspectedGlitch,
+		Timestamp:          parseTime(p.Timestamp),
+		ProcessedTimestamp: parseTime(p.ProcessedTimestamp),
+	}
+}
+
+// NewPortEventRow converts a port event to its Parquet row.
+func NewPortEventRow(e vesselapi.PortEvent) PortEventRow {
+	row := PortEventRow{
+		Event:     e.Event,
+		Timestamp: parseTime(e.Timestamp),
+	}
+	if e.Port != nil {
+		row.PortUnlo = e.Port.UnloCode
+		row.PortName = e.Port.Name
+		row.PortCountry = e.Port.Country
+	}
+	if e.Vessel != nil {
+		row.VesselIMO = int64Ptr(e.Vessel.Imo)
+		row.VesselMMSI = int64Ptr(e.Vessel.Mmsi)
+		row.VesselName = e.Vessel.Name
+	}
+	return row
+}
+
+// NewEmissionRow converts an emission report to its Parquet row.
+func NewEmissionRow(e vesselapi.VesselEmission) EmissionRow {
+	return EmissionRow{
+		IMO:                        int64Ptr(e.Imo),
+		Name:                       e.Name,
+		ReportingPeriod:            e.ReportingPeriod,
+		VesselType:                 e.VesselType,
+		FlagCode:                   e.FlagCode,
+		FlagName:                   e.FlagName,
+		HomePort:                   e.HomePort,
+		IceClass:                   e.IceClass,
+		CollectedAt:                parseTime(e.CollectedAt),
+		DocIssueDate:               e.DocIssueDate,
+		DocExpiryDate:              e.DocExpiryDate,
+		Co2EmissionsTotal:          e.Co2EmissionsTotal,
+		Co2EmissionsAtBerth:        e.Co2EmissionsAtBerth,
+		Co2EmissionsOnLadenVoyages: e.Co2EmissionsOnLadenVoyages,
+		Co2PerDistance:             e.Co2PerDistance,
+		Co2PerTransportWork:        e.Co2PerTransportWork,
+		FuelConsumptionTotal:       e.FuelConsumptionTotal,
+		FuelConsumptionHfo:         e.FuelConsumptionHfo,
+		FuelConsumptionLfo:         e.FuelConsumptionLfo,
+		FuelConsumptionLng:         e.FuelConsumptionLng,
+		FuelConsumptionMdo:         e.FuelConsumptionMdo,
+		FuelConsumptionMgo:         e.FuelConsumptionMgo,
+		FuelConsumptionOther:       e.FuelConsumptionOther,
+		FuelPerDistance:            e.FuelPerDistance,
+		FuelPerTransportWork:       e.FuelPerTransportWork,
+		DistanceThroughIce:         e.DistanceThroughIce,
+		TimeAtSeaThroughIce:        e.TimeAtSeaThroughIce,
+		TotalTimeAtSea:             e.TotalTimeAtSea,
+		PortCallsWithinEu:          int64Ptr(e.PortCallsWithinEu),
+		PortCallsOutsideEu:         int64Ptr(e.PortCallsOutsideEu),
+		TechnicalEfficiency:        e.TechnicalEfficiency,
+		TechnicalEfficiencyValue:   e.TechnicalEfficiencyValue,
+		MonitoringMethodA:          e.MonitoringMethodA,
+		MonitoringMethodB:          e.MonitoringMethodB,
+		MonitoringMethodC:          e.MonitoringMethodC,
+		MonitoringMethodD:          e.MonitoringMethodD,
+		VerifierName:               e.VerifierName,
+		VerifierAddress:            e.VerifierAddress,
+		VerifierAccreditation:      e.VerifierAccreditation,
+		SourceURL:                  e.SourceUrl,
+		UniqueKey:                  e.UniqueKey,
+	}
+}
+
+// WritePositions writes every position of it to w as a Parquet file and
+// returns the number of rows written. Rows are flushed to w a row group at
+// a time as pages are fetched.
+func WritePositions(w io.Writer, it Iterator[vesselapi.VesselPosition], opts *Options) (int, error) {
+	return write(w, it, NewPositionRow, opts)
+}
+
+// WritePortEvents writes every port event of it to w as a Parquet file and
+// returns the number of rows written.
+func WritePortEvents(w io.Writer, it Iterator[vesselapi.PortEvent], opts *Options) (int, error) {
+	return write(w, it, NewPortEventRow, opts)
+}
+
+// WriteEmissions writes every emission report of it to w as a Parquet file
+// and returns the number of rows written.
+func WriteEmissions(w io.Writer, it Iterator[vesselapi.VesselEmission], opts *Options) (int, error) {
+	return write(w, it, NewEmissionRow, opts)
+}
+
+// write drains it into a Parquet file of R rows. If the iterator fails the
+// file is left without a footer and the iterator's error is returned.
+func write[T, R any](w io.Writer, it Iterator[T], row func(T) R, opts *Options) (int, error) {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.RowGroupSize <= 0 {
+		o.RowGroupSize = DefaultRowGroupSize
+	}
+	if o.Compression == nil {
+		o.Compression = &pq.Snappy
+	}
+
+	pw := pq.NewGenericWriter[R](w, pq.MaxRowsPerRowGroup(o.RowGroupSize), pq.Compression(o.Compression))
+	n := 0
+	batch := make([]R, 0, writeBatch)
+	flush := func() error {
+		if _, err := pw.Write(batch); err != nil {
+			return fmt.Errorf("parquet: writing rows: %w", err)
+		}
+		n += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	for it.Next() {
+		batch = append(batch, row(it.Value()))
+		if len(batch) == cap(batch) {
+			if err := flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return n, err
+	}
+	if err := flush(); err != nil {
+		return n, err
+	}
+	if err := pw.Close(); err != nil {
+		return n, fmt.Errorf("parquet: closing file: %w", err)
+	}
+	return n, nil
+}
+
+func int64Ptr(v *int) *int64 {
+	if v == nil {
+		return nil
+	}
+	i := int64(*v)
+	return &i
+}
+
+func int32Ptr(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	i := int32(*v)
+	return &i
+}
+
+// timestampLayouts are the formats the API uses for timestamp fields, most
+// specific first.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+}
+
+// parseTime parses an API timestamp in UTC, returning nil when it is
+// missing or unusable. Timestamps without a zone offset are interpreted as
+// UTC.
+func parseTime(s *string) *time.Time {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, vesselapi.Deref(s)); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
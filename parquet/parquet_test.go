@@ -0,0 +1,188 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	pq "github.com/parquet-go/parquet-go"
+	vesselapi "github.com/vessel-api/vesselapi-go/v3"
+)
+
+type sliceIterator[T any] struct {
+	items []T
+	i     int
+	err   error
+}
+
+func (it *sliceIterator[T]) Next() bool {
+	it.i++
+	return it.i < len(it.items)
+}
+
+func (it *sliceIterator[T]) Value() T { return it.items[it.i] }
+
+func (it *sliceIterator[T]) Err() error { return it.err }
+
+func iter[T any](items ...T) *sliceIterator[T] {
+	return &sliceIterator[T]{items: items, i: -1}
+}
+
+func readRows[R any](t *testing.T, data []byte) ([]R, *pq.File) {
+	t.Helper()
+	f, err := pq.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unexpected error opening file: %v", err)
+	}
+	r := pq.NewGenericReader[R](bytes.NewReader(data))
+	defer r.Close()
+	rows := make([]R, f.NumRows())
+	if n, err := r.Read(rows); n != len(rows) {
+		t.Fatalf("expected %d rows, read %d: %v", len(rows), n, err)
+	}
+	return rows, f
+}
+
+func TestWritePositions(t *testing.T) {
+	positions := []vesselapi.VesselPosition{
+		{
+			Mmsi:       vesselapi.Ptr(244660000),
+			Imo:        vesselapi.Ptr(9811000),
+			VesselName: vesselapi.Ptr("EVER GIVEN"),
+			Latitude:   vesselapi.Ptr(51.9),
+			Longitude:  vesselapi.Ptr(4.1),
+			Sog:        vesselapi.Ptr(float32(12.5)),
+			Heading:    vesselapi.Ptr(270),
+			Timestamp:  vesselapi.Ptr("2025-01-01T12:30:00+01:00"),
+		},
+		{Mmsi: vesselapi.Ptr(1), Timestamp: vesselapi.Ptr("not a time")},
+	}
+	var buf bytes.Buffer
+	n, err := WritePositions(&buf, iter(positions...), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 rows, got %d", n)
+	}
+
+	rows, f := readRows[PositionRow](t, buf.Bytes())
+	got := rows[0]
+	if got.MMSI == nil || *got.MMSI != 244660000 || got.VesselName == nil || *got.VesselName != "EVER GIVEN" {
+		t.Errorf("unexpected first row %+v", got)
+	}
+	if got.Heading == nil || *got.Heading != 270 || got.SOG == nil || *got.SOG != 12.5 {
+		t.Errorf("unexpected heading or speed in %+v", got)
+	}
+	want := time.Date(2025, 1, 1, 11, 30, 0, 0, time.UTC)
+	if got.Timestamp == nil || !got.Timestamp.Equal(want) {
+		t.Errorf("expected timestamp %v, got %v", want, got.Timestamp)
+	}
+	if got.COG != nil || got.ProcessedTimestamp != nil {
+		t.Errorf("expected nulls for missing fields, got %+v", got)
+	}
+	if rows[1].Timestamp != nil || rows[1].Latitude != nil {
+		t.Errorf("expected nulls in the second row, got %+v", rows[1])
+	}
+
+	col, ok := f.Schema().Lookup("timestamp")
+	if !ok {
+		t.Fatal("expected a timestamp column")
+	}
+	if lt := col.Node.Type().LogicalType(); lt == nil || lt.Timestamp == nil {
+		t.Errorf("expected a timestamp logical type, got %v", lt)
+	}
+	if !col.Node.Optional() {
+		t.Error("expected the timestamp column to be optional")
+	}
+}
+
+func TestWritePortEvents(t *testing.T) {
+	events := []vesselapi.PortEvent{
+		{
+			Event:     vesselapi.Ptr("Arrival"),
+			Timestamp: vesselapi.Ptr("2025-01-01T00:00:00Z"),
+			Port:      &vesselapi.GithubComVesselapiCommonVesselDataContractsTypesPortReference{UnloCode: vesselapi.Ptr("NLRTM")},
+			Vessel:    &vesselapi.GithubComVesselapiCommonVesselDataContractsTypesVesselReference{Imo: vesselapi.Ptr(9811000)},
+		},
+		{Event: vesselapi.Ptr("Departure")},
+	}
+	var buf bytes.Buffer
+	if _, err := WritePortEvents(&buf, iter(events...), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, _ := readRows[PortEventRow](t, buf.Bytes())
+	if rows[0].PortUnlo == nil || *rows[0].PortUnlo != "NLRTM" || rows[0].VesselIMO == nil || *rows[0].VesselIMO != 9811000 {
+		t.Errorf("unexpected first row %+v", rows[0])
+	}
+	if rows[1].PortUnlo != nil || rows[1].VesselIMO != nil || rows[1].Timestamp != nil {
+		t.Errorf("expected nulls without references, got %+v", rows[1])
+	}
+}
+
+func TestWriteEmissions(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := WriteEmissions(&buf, iter(vesselapi.VesselEmission{
+		Imo:               vesselapi.Ptr(9811000),
+		ReportingPeriod:   vesselapi.Ptr("2023"),
+		Co2EmissionsTotal: vesselapi.Ptr(float32(1234.5)),
+		CollectedAt:       vesselapi.Ptr("2024-06-30 08:00:00"),
+		DocIssueDate:      vesselapi.Ptr("30/04/2024"),
+	}), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, _ := readRows[EmissionRow](t, buf.Bytes())
+	got := rows[0]
+	if got.Co2EmissionsTotal == nil || *got.Co2EmissionsTotal != 1234.5 || got.FuelConsumptionTotal != nil {
+		t.Errorf("unexpected row %+v", got)
+	}
+	if want := time.Date(2024, 6, 30, 8, 0, 0, 0, time.UTC); got.CollectedAt == nil || !got.CollectedAt.Equal(want) {
+		t.Errorf("expected collected_at %v, got %v", want, got.CollectedAt)
+	}
+	if got.DocIssueDate == nil || *got.DocIssueDate != "30/04/2024" {
+		t.Errorf("expected the document date as reported, got %v", got.DocIssueDate)
+	}
+}
+
+func TestWrite_RowGroupSize(t *testing.T) {
+	positions := make([]vesselapi.VesselPosition, 2500)
+	for i := range positions {
+		positions[i] = vesselapi.VesselPosition{
+			Mmsi:      vesselapi.Ptr(i),
+			Timestamp: vesselapi.Ptr(fmt.Sprintf("2025-01-01T00:00:%02dZ", i%60)),
+		}
+	}
+	var buf bytes.Buffer
+	n, err := WritePositions(&buf, iter(positions...), &Options{RowGroupSize: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != len(positions) {
+		t.Errorf("expected %d rows, got %d", len(positions), n)
+	}
+	rows, f := readRows[PositionRow](t, buf.Bytes())
+	if groups := len(f.RowGroups()); groups != 3 {
+		t.Errorf("expected 3 row groups, got %d", groups)
+	}
+	if *rows[2499].MMSI != 2499 {
+		t.Errorf("expected rows in order, got last MMSI %d", *rows[2499].MMSI)
+	}
+}
+
+func TestWrite_IteratorError(t *testing.T) {
+	boom := errors.New("boom")
+	it := iter(vesselapi.VesselPosition{})
+	it.err = boom
+	var buf bytes.Buffer
+	if _, err := WritePositions(&buf, it, nil); !errors.Is(err, boom) {
+		t.Errorf("expected the iterator error, got %v", err)
+	}
+}
+
+func TestIteratorCompatibility(t *testing.T) {
+	// *vesselapi.Iterator satisfies Iterator.
+	var _ Iterator[vesselapi.VesselPosition] = (*vesselapi.Iterator[vesselapi.VesselPosition])(nil)
+}
@@ -0,0 +1,245 @@
+package vesselapi
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PortCall is one visit of a vessel to a port, reconstructed from its
+// arrival and departure events.
+type PortCall struct {
+	Vessel *GithubComVesselapiCommonVesselDataContractsTypesVesselReference
+	Port   *GithubComVesselapiCommonVesselDataContractsTypesPortReference
+
+	// Arrival and Departure are the events that opened and closed the call.
+	// Either is nil when the call is unmatched.
+	Arrival   *PortEvent
+	Departure *PortEvent
+
+	// ArrivalTime and DepartureTime are the event timestamps, or zero when
+	// the corresponding event is missing.
+	ArrivalTime   time.Time
+	DepartureTime time.Time
+
+	// UnmatchedArrival marks an arrival with no departure: the vessel is
+	// still in port, or its departure was not recorded before the next
+	// arrival elsewhere.
+	UnmatchedArrival bool
+
+	// UnmatchedDeparture marks a departure with no preceding arrival, for
+	// example one at the start of the queried time range.
+	UnmatchedDeparture bool
+}
+
+// Complete reports whether the call has both an arrival and a departure.
+func (c PortCall) Complete() bool {
+	return !c.UnmatchedArrival && !c.UnmatchedDeparture
+}
+
+// Duration returns the time between arrival and departure, or zero for an
+// unmatched call.
+func (c PortCall) Duration() time.Duration {
+	if !c.Complete() {
+		return 0
+	}
+	return c.DepartureTime.Sub(c.ArrivalTime)
+}
+
+// start returns the earliest known time of the call.
+func (c PortCall) start() time.Time {
+	if c.Arrival != nil {
+		return c.ArrivalTime
+	}
+	return c.DepartureTime
+}
+
+// ReconstructPortCalls pairs arrival and departure events into port calls,
+// as returned by PortEventsService.AllByVessel or AllByPort. Events may be
+// in any order and may cover any number of vessels and ports.
+//
+// Each vessel's events are replayed in timestamp order. A departure closes
+// the open arrival at the same port; an arrival or departure at a different
+// port leaves the open arrival unmatched. A repeated arrival at the port the
+// vessel is already in is ignored, as are exact duplicates and events with
+// no vessel, no port, an unknown event type or an unusable timestamp.
+//
+// Calls are returned ordered by their first known time.
+func ReconstructPortCalls(events []PortEvent) []PortCall {
+	type timed struct {
+		event   PortEvent
+		at      time.Time
+		arrival bool
+	}
+	byVessel := make(map[string][]timed)
+	seen := make(map[string]bool, len(events))
+	for _, ev := range events {
+		if ev.Vessel == nil || ev.Port == nil {
+			continue
+		}
+		var arrival bool
+		switch strings.ToLower(Deref(ev.Event)) {
+		case "arrival":
+			arrival = true
+		case "departure":
+		default:
+			continue
+		}
+		at, err := parseTimestamp(Deref(ev.Timestamp))
+		if err != nil {
+			continue
+		}
+		key := portEventKey(ev)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		v := vesselRefKey(ev.Vessel)
+		byVessel[v] = append(byVessel[v], timed{ev, at, arrival})
+	}
+
+	var calls []PortCall
+	for _, evs := range byVessel {
+		sort.SliceStable(evs, func(i, j int) bool { return evs[i].at.Before(evs[j].at) })
+		var open *PortCall
+		for i := range evs {
+			t := &evs[i]
+			samePort := open != nil && portRefKey(open.Port) == portRefKey(t.event.Port)
+			if t.arrival && samePort {
+				continue
+			}
+			if open != nil && !samePort {
+				calls = append(calls, *open)
+				open = nil
+			}
+			switch {
+			case t.arrival:
+				open = &PortCall{
+					Vessel:           t.event.Vessel,
+					Port:             t.event.Port,
+					Arrival:          &t.event,
+					ArrivalTime:      t.at,
+					UnmatchedArrival: true,
+				}
+			case open != nil:
+				open.Departure = &t.event
+				open.DepartureTime = t.at
+				open.UnmatchedArrival = false
+				calls = append(calls, *open)
+				open = nil
+			default:
+				calls = append(calls, PortCall{
+					Vessel:             t.event.Vessel,
+					Port:               t.event.Port,
+					Departure:          &t.event,
+					DepartureTime:      t.at,
+					UnmatchedDeparture: true,
+				})
+			}
+		}
+		if open != nil {
+			calls = append(calls, *open)
+		}
+	}
+
+	sort.SliceStable(calls, func(i, j int) bool {
+		si, sj := calls[i].start(), calls[j].start()
+		if !si.Equal(sj) {
+			return si.Before(sj)
+		}
+		return vesselRefKey(calls[i].Vessel) < vesselRefKey(calls[j].Vessel)
+	})
+	return calls
+}
+
+// PortCallsByVessel fetches every port event of the vessel and reconstructs
+// its port calls. Narrow the time range with params to avoid fetching the
+// vessel's full history; calls straddling the range edges come back
+// unmatched.
+func (s *PortEventsService) PortCallsByVessel(ctx context.Context, id string, params *GetPorteventsVesselIdParams) ([]PortCall, error) {
+	events, err := s.AllByVessel(ctx, id, params).Collect()
+	if err != nil {
+		return nil, err
+	}
+	return ReconstructPortCalls(events), nil
+}
+
+// PortCallsByPort fetches every port event at the port and reconstructs the
+// port calls made there.
+func (s *PortEventsService) PortCallsByPort(ctx context.Context, unlocode string, params *GetPorteventsPortUnlocodeParams) ([]PortCall, error) {
+	events, err := s.AllByPort(ctx, unlocode, params).Collect()
+	if err != nil {
+		return nil, err
+	}
+	return ReconstructPortCalls(events), nil
+}
+
+// DwellStats summarizes the durations of a group of complete port calls.
+type DwellStats struct {
+	// Calls is the number of complete calls; Unmatched counts the calls in
+	// the group that were left out because they lack an arrival or
+	// departure.
+	Calls     int
+	Unmatched int
+
+	Total  time.Duration
+	Mean   time.Duration
+	Median time.Duration
+	Min    time.Duration
+	Max    time.Duration
+}
+
+// DwellStatsBy groups calls by key and summarizes each group. Calls for
+// which key returns "" are skipped.
+func DwellStatsBy(calls []PortCall, key func(PortCall) string) map[string]DwellStats {
+	durations := make(map[string][]time.Duration)
+	unmatched := make(map[string]int)
+	for _, c := range calls {
+		k := key(c)
+		if k == "" {
+			continue
+		}
+		if !c.Complete() {
+			unmatched[k]++
+			if _, ok := durations[k]; !ok {
+				durations[k] = nil
+			}
+			continue
+		}
+		durations[k] = append(durations[k], c.Duration())
+	}
+
+	stats := make(map[string]DwellStats, len(durations))
+	for k, ds := range durations {
+		s := DwellStats{Calls: len(ds), Unmatched: unmatched[k]}
+		if len(ds) > 0 {
+			sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+			for _, d := range ds {
+				s.Total += d
+			}
+			s.Mean = s.Total / time.Duration(len(ds))
+			s.Min, s.Max = ds[0], ds[len(ds)-1]
+			if n := len(ds); n%2 == 1 {
+				s.Median = ds[n/2]
+			} else {
+				s.Median = (ds[n/2-1] + ds[n/2]) / 2
+			}
+		}
+		stats[k] = s
+	}
+	return stats
+}
+
+// DwellStatsByPort summarizes port call durations per port, keyed by
+// UN/LOCODE, or by "name:" and the port name for ports without one.
+func DwellStatsByPort(calls []PortCall) map[string]DwellStats {
+	return DwellStatsBy(calls, func(c PortCall) string { return portRefKey(c.Port) })
+}
+
+// DwellStatsByVessel summarizes port call durations per vessel, keyed as
+// "imo:<IMO>", or as "mmsi:<MMSI>" or "name:<name>" for vessels without an
+// IMO number.
+func DwellStatsByVessel(calls []PortCall) map[string]DwellStats {
+	return DwellStatsBy(calls, func(c PortCall) string { return vesselRefKey(c.Vessel) })
+}
@@ -0,0 +1,149 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReconstructPortCalls(t *testing.T) {
+	events := []PortEvent{
+		// Out of order and with a duplicate.
+		tailEvent(1, "NLRTM", "Departure", "2025-01-02T06:00:00Z"),
+		tailEvent(1, "NLRTM", "Arrival", "2025-01-01T06:00:00Z"),
+		tailEvent(1, "NLRTM", "Arrival", "2025-01-01T06:00:00Z"),
+		// Repeated arrival at the same port is ignored.
+		tailEvent(1, "NLRTM", "Arrival", "2025-01-01T08:00:00Z"),
+		// Arrival in Antwerp never closed before arriving in Hamburg.
+		tailEvent(1, "BEANR", "Arrival", "2025-01-03T06:00:00Z"),
+		tailEvent(1, "DEHAM", "Arrival", "2025-01-05T06:00:00Z"),
+		tailEvent(1, "DEHAM", "Departure", "2025-01-05T18:00:00Z"),
+		// Still in port.
+		tailEvent(1, "NOOSL", "Arrival", "2025-01-07T06:00:00Z"),
+		// Second vessel: departure with no arrival.
+		tailEvent(2, "NLRTM", "Departure", "2024-12-31T00:00:00Z"),
+		// Ignored: no timestamp, unknown type.
+		tailEvent(2, "NLRTM", "Arrival", ""),
+		tailEvent(2, "NLRTM", "Anchored", "2025-01-01T00:00:00Z"),
+	}
+	calls := ReconstructPortCalls(events)
+	if len(calls) != 5 {
+		t.Fatalf("expected 5 calls, got %d: %+v", len(calls), calls)
+	}
+
+	want := []struct {
+		vessel, port               string
+		unmatchedArr, unmatchedDep bool
+		duration                   time.Duration
+	}{
+		{"imo:2", "NLRTM", false, true, 0},
+		{"imo:1", "NLRTM", false, false, 24 * time.Hour},
+		{"imo:1", "BEANR", true, false, 0},
+		{"imo:1", "DEHAM", false, false, 12 * time.Hour},
+		{"imo:1", "NOOSL", true, false, 0},
+	}
+	for i, w := range want {
+		c := calls[i]
+		if got := vesselRefKey(c.Vessel); got != w.vessel {
+			t.Errorf("call %d: expected vessel %s, got %s", i, w.vessel, got)
+		}
+		if got := portRefKey(c.Port); got != w.port {
+			t.Errorf("call %d: expected port %s, got %s", i, w.port, got)
+		}
+		if c.UnmatchedArrival != w.unmatchedArr || c.UnmatchedDeparture != w.unmatchedDep {
+			t.Errorf("call %d: expected unmatched arrival/departure %v/%v, got %v/%v", i, w.unmatchedArr, w.unmatchedDep, c.UnmatchedArrival, c.UnmatchedDeparture)
+		}
+		if c.Duration() != w.duration {
+			t.Errorf("call %d: expected duration %v, got %v", i, w.duration, c.Duration())
+		}
+	}
+	if calls[0].Arrival != nil || calls[0].DepartureTime.IsZero() {
+		t.Errorf("expected departure-only call, got %+v", calls[0])
+	}
+	if calls[4].Departure != nil || !calls[4].DepartureTime.IsZero() {
+		t.Errorf("expected arrival-only call, got %+v", calls[4])
+	}
+}
+
+func TestReconstructPortCalls_DepartureFromOtherPort(t *testing.T) {
+	calls := ReconstructPortCalls([]PortEvent{
+		tailEvent(1, "NLRTM", "Arrival", "2025-01-01T00:00:00Z"),
+		tailEvent(1, "BEANR", "Departure", "2025-01-02T00:00:00Z"),
+	})
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(calls))
+	}
+	if !calls[0].UnmatchedArrival || !calls[1].UnmatchedDeparture {
+		t.Errorf("expected unmatched arrival then unmatched departure, got %+v", calls)
+	}
+}
+
+func TestDwellStats(t *testing.T) {
+	call := func(imo int, port string, hours int) []PortEvent {
+		return []PortEvent{
+			tailEvent(imo, port, "Arrival", "2025-01-01T00:00:00Z"),
+			tailEvent(imo, port, "Departure", time.Date(2025, 1, 1, hours, 0, 0, 0, time.UTC).Format(time.RFC3339)),
+		}
+	}
+	var events []PortEvent
+	events = append(events, call(1, "NLRTM", 2)...)
+	events = append(events, call(2, "NLRTM", 4)...)
+	events = append(events, call(3, "NLRTM", 9)...)
+	events = append(events, call(4, "NLRTM", 11)...)
+	events = append(events, tailEvent(5, "NLRTM", "Arrival", "2025-01-01T00:00:00Z"))
+	events = append(events, tailEvent(5, "BEANR", "Departure", "2025-01-01T01:00:00Z"))
+	calls := ReconstructPortCalls(events)
+
+	byPort := DwellStatsByPort(calls)
+	rtm := byPort["NLRTM"]
+	if rtm.Calls != 4 || rtm.Unmatched != 1 {
+		t.Fatalf("expected 4 calls and 1 unmatched, got %+v", rtm)
+	}
+	if rtm.Total != 26*time.Hour || rtm.Mean != 6*time.Hour+30*time.Minute {
+		t.Errorf("expected total 26h and mean 6h30m, got %v and %v", rtm.Total, rtm.Mean)
+	}
+	if rtm.Median != 6*time.Hour+30*time.Minute || rtm.Min != 2*time.Hour || rtm.Max != 11*time.Hour {
+		t.Errorf("expected median 6h30m, min 2h, max 11h, got %+v", rtm)
+	}
+	if anr := byPort["BEANR"]; anr.Calls != 0 || anr.Unmatched != 1 {
+		t.Errorf("expected only an unmatched call at BEANR, got %+v", anr)
+	}
+
+	byVessel := DwellStatsByVessel(calls)
+	if len(byVessel) != 5 {
+		t.Fatalf("expected 5 vessels, got %d", len(byVessel))
+	}
+	if v := byVessel["imo:3"]; v.Calls != 1 || v.Median != 9*time.Hour {
+		t.Errorf("expected one 9h call for imo:3, got %+v", v)
+	}
+}
+
+func TestPortCallsByVessel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/portevents/vessel/9811000" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		events := []PortEvent{
+			tailEvent(9811000, "NLRTM", "Departure", "2025-01-02T00:00:00Z"),
+			tailEvent(9811000, "NLRTM", "Arrival", "2025-01-01T00:00:00Z"),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PortEventsResponse{PortEvents: &events})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls, err := vc.PortEvents.PortCallsByVessel(context.Background(), "9811000", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 1 || calls[0].Duration() != 24*time.Hour {
+		t.Errorf("expected one 24h call, got %+v", calls)
+	}
+}
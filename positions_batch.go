@@ -0,0 +1,157 @@
+package vesselapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultPositionsBatchSize is the default number of IDs sent in each
+	// Positions request.
+	DefaultPositionsBatchSize = 50
+
+	// defaultPositionsConcurrency is the default number of batches fetched
+	// at once.
+	defaultPositionsConcurrency = 4
+)
+
+// PositionsBatchOptions configures PositionsBatch. A nil
+// *PositionsBatchOptions uses the defaults.
+type PositionsBatchOptions struct {
+	// IDType selects whether the IDs are IMO (default) or MMSI numbers.
+	IDType GetVesselsPositionsParamsFilterIdType
+
+	// BatchSize is the number of IDs per request. Defaults to
+	// DefaultPositionsBatchSize.
+	BatchSize int
+
+	// Concurrency is the maximum number of batches fetched at once.
+	// Defaults to 4.
+	Concurrency int
+
+	// TimeFrom and TimeTo bound the position timestamps, in RFC 3339 format.
+	TimeFrom *string
+	TimeTo   *string
+
+	// PaginationLimit is the page size used for each batch.
+	PaginationLimit *int
+}
+
+// PositionsBatchResult is the merged result of PositionsBatch.
+type PositionsBatchResult struct {
+	// Positions holds every position returned, grouped by vessel in the
+	// order the IDs were given.
+	Positions []VesselPosition
+
+	// Missing lists the requested IDs for which no position was returned,
+	// in the order they were given.
+	Missing []int
+}
+
+// PositionsBatch fetches positions for any number of vessels. The IDs are
+// deduplicated and split into batches of opts.BatchSize that are fetched
+// concurrently, following every page of each batch.
+//
+// The first batch error cancels the remaining requests and is returned.
+func (s *VesselsService) PositionsBatch(ctx context.Context, ids []int, opts *PositionsBatchOptions) (*PositionsBatchResult, error) {
+	if opts == nil {
+		opts = &PositionsBatchOptions{}
+	}
+	idType := opts.IDType
+	if idType == "" {
+		idType = GetVesselsPositionsParamsFilterIdTypeImo
+	}
+	if idType != GetVesselsPositionsParamsFilterIdTypeImo && idType != GetVesselsPositionsParamsFilterIdTypeMmsi {
+		return nil, fmt.Errorf("vesselapi: invalid id type %q", idType)
+	}
+	size := opts.BatchSize
+	if size <= 0 {
+		size = DefaultPositionsBatchSize
+	}
+
+	var unique []int
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	var batches [][]int
+	for start := 0; start < len(unique); start += size {
+		batches = append(batches, unique[start:min(start+size, len(unique))])
+	}
+
+	var (
+		mu   sync.Mutex
+		byID = make(map[int][]VesselPosition, len(unique))
+	)
+	workers := concurrency(opts.Concurrency, defaultPositionsConcurrency, len(batches))
+	err := runPool(ctx, len(batches), workers, func(ctx context.Context, i int) error {
+		positions, err := s.AllPositions(ctx, &GetVesselsPositionsParams{
+			FilterIds:       joinIDs(batches[i]),
+			FilterIdType:    idType,
+			TimeFrom:        opts.TimeFrom,
+			TimeTo:          opts.TimeTo,
+			PaginationLimit: opts.PaginationLimit,
+		}).Collect()
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, p := range positions {
+			if id := positionID(p, idType); seen[id] {
+				byID[id] = append(byID[id], p)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PositionsBatchResult{}
+	for _, id := range unique {
+		if positions, ok := byID[id]; ok {
+			result.Positions = append(result.Positions, positions...)
+		} else {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+	return result, nil
+}
+
+// joinIDs formats ids as the comma-separated list expected by FilterIds.
+func joinIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+// positionID returns the IMO or MMSI number of p, following idType, or 0 if
+// it is missing.
+func positionID(p VesselPosition, idType GetVesselsPositionsParamsFilterIdType) int {
+	if idType == GetVesselsPositionsParamsFilterIdTypeMmsi {
+		return Deref(p.Mmsi)
+	}
+	return Deref(p.Imo)
+}
+
+// latestByID returns the freshest position for each vessel, keyed by
+// positionID.
+func latestByID(positions []VesselPosition, idType GetVesselsPositionsParamsFilterIdType) map[int]VesselPosition {
+	latest := make(map[int]VesselPosition)
+	for _, p := range positions {
+		id := positionID(p, idType)
+		if prev, ok := latest[id]; !ok || newerPosition(p, prev) {
+			latest[id] = p
+		}
+	}
+	return latest
+}
@@ -0,0 +1,123 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPositionsBatch_ChunksAndReportsMisses(t *testing.T) {
+	var requests, inFlight, maxInFlight atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		q := r.URL.Query()
+		if q.Get("filter.idType") != "mmsi" {
+			t.Errorf("expected idType mmsi, got %s", q.Get("filter.idType"))
+		}
+		ids := strings.Split(q.Get("filter.ids"), ",")
+		if len(ids) > 3 {
+			t.Errorf("expected at most 3 ids per request, got %d", len(ids))
+		}
+
+		// Odd MMSIs have no position; the first ID of each batch returns a
+		// second page.
+		var positions []VesselPosition
+		for _, s := range ids {
+			id, _ := strconv.Atoi(s)
+			if id%2 == 0 {
+				positions = append(positions, VesselPosition{Mmsi: Ptr(id)})
+			}
+		}
+		resp := VesselPositionsResponse{VesselPositions: &positions}
+		if q.Get("pagination.nextToken") == "" {
+			resp.NextToken = Ptr("page2")
+		} else {
+			id, _ := strconv.Atoi(ids[0])
+			resp.VesselPositions = &[]VesselPosition{{Mmsi: Ptr(id), Timestamp: Ptr("later")}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ids := []int{2, 3, 4, 5, 6, 7, 8, 2, 10}
+	got, err := vc.Vessels.PositionsBatch(context.Background(), ids, &PositionsBatchOptions{
+		IDType:      GetVesselsPositionsParamsFilterIdTypeMmsi,
+		BatchSize:   3,
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 8 unique IDs in batches of 3, two pages each.
+	if requests.Load() != 6 {
+		t.Errorf("expected 6 requests, got %d", requests.Load())
+	}
+	if maxInFlight.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxInFlight.Load())
+	}
+	if fmt.Sprint(got.Missing) != "[3 7]" {
+		t.Errorf("expected missing [3 7], got %v", got.Missing)
+	}
+	var order []int
+	for _, p := range got.Positions {
+		order = append(order, Deref(p.Mmsi))
+	}
+	// Batch leaders 2, 5 and 8 each have an extra position from page two;
+	// 5 is odd, so its page-two position marks it as found.
+	if fmt.Sprint(order) != "[2 2 4 5 6 8 8 10]" {
+		t.Errorf("unexpected positions order %v", order)
+	}
+}
+
+func TestPositionsBatch_Errors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"bad key"}}`)
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = vc.Vessels.PositionsBatch(context.Background(), []int{1, 2, 3}, &PositionsBatchOptions{BatchSize: 1})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsAuthError() {
+		t.Fatalf("expected auth APIError, got %v", err)
+	}
+
+	if _, err := vc.Vessels.PositionsBatch(context.Background(), []int{1}, &PositionsBatchOptions{IDType: "name"}); err == nil {
+		t.Error("expected error for invalid id type")
+	}
+
+	got, err := vc.Vessels.PositionsBatch(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error for empty input: %v", err)
+	}
+	if len(got.Positions) != 0 || len(got.Missing) != 0 {
+		t.Errorf("expected empty result, got %+v", got)
+	}
+}
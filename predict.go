@@ -0,0 +1,223 @@
+package vesselapi
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+const (
+	defaultPredictBaseUncertainty = 25
+	defaultPredictSpeedError      = 1
+	defaultPredictCourseError     = 5
+	defaultPredictTurnWindow      = 15 * time.Minute
+	defaultPredictMaxTurn         = 90
+
+	// predictStep is the integration step used while a vessel is turning.
+	predictStep = 30 * time.Second
+
+	// maxTurnSteps caps the number of integration steps of a turn; longer
+	// turns use proportionally longer steps.
+	maxTurnSteps = 360
+
+	// minRateOfTurn is the rate of turn, in degrees per minute, below
+	// which a vessel is taken to be holding its course.
+	minRateOfTurn = 0.1
+
+	// minTurnSegment is the shortest track segment, in meters, whose
+	// bearing is used to estimate the rate of turn.
+	minTurnSegment = 50
+)
+
+// PredictOptions configures Predict. A nil *PredictOptions uses the
+// defaults.
+type PredictOptions struct {
+	// Recent, if set, is the vessel's recent track, used to estimate its
+	// rate of turn. Only points within TurnWindow before the report are
+	// used.
+	Recent Track
+
+	// TurnWindow is how far back Recent is read. Defaults to 15 minutes.
+	TurnWindow time.Duration
+
+	// MaxTurn caps the total change of course, in degrees, applied by the
+	// rate of turn; past it the vessel is assumed to hold its course.
+	// Defaults to 90 degrees.
+	MaxTurn float64
+
+	// BaseUncertainty is the position error of the report itself in
+	// meters. Defaults to 25 m.
+	BaseUncertainty float64
+
+	// SpeedError and CourseError are the assumed errors of the reported
+	// speed in knots and course in degrees, which make the uncertainty grow
+	// with elapsed time. They default to 1 knot and 5 degrees.
+	SpeedError  float64
+	CourseError float64
+}
+
+// Prediction is an estimated vessel position.
+type Prediction struct {
+	Point geo.Point
+	At    time.Time
+
+	// Elapsed is the time between the report and At.
+	Elapsed time.Duration
+
+	// Course and Speed are the course in degrees and speed in knots the
+	// vessel is estimated to have at At.
+	Course float64
+	Speed  float64
+
+	// RateOfTurn is the rate of turn applied, in degrees per minute,
+	// positive to starboard.
+	RateOfTurn float64
+
+	// Uncertainty is the radius in meters around Point within which the
+	// vessel is expected to be.
+	Uncertainty float64
+}
+
+// Predict estimates where the vessel in report p is at time at by dead
+// reckoning from its reported course and speed, which follow the rules of
+// PositionMotion. When opts.Recent holds enough of the vessel's recent
+// track, the rate of turn seen in it is continued, up to MaxTurn; a rate
+// below 0.1 degrees per minute is taken as a straight course.
+//
+// The uncertainty radius starts at BaseUncertainty and grows with elapsed
+// time by the distance SpeedError accumulates along track and CourseError
+// across it. A time before the report projects backwards.
+//
+// It returns ErrNoPosition if p has no coordinates and an error if it has
+// no usable timestamp.
+func Predict(p VesselPosition, at time.Time, opts *PredictOptions) (Prediction, error) {
+	o := PredictOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.TurnWindow <= 0 {
+		o.TurnWindow = defaultPredictTurnWindow
+	}
+	if o.MaxTurn <= 0 {
+		o.MaxTurn = defaultPredictMaxTurn
+	}
+	if o.BaseUncertainty <= 0 {
+		o.BaseUncertainty = defaultPredictBaseUncertainty
+	}
+	if o.SpeedError <= 0 {
+		o.SpeedError = defaultPredictSpeedError
+	}
+	if o.CourseError <= 0 {
+		o.CourseError = defaultPredictCourseError
+	}
+
+	m, err := PositionMotion(p)
+	if err != nil {
+		return Prediction{}, err
+	}
+	reported, err := parseTimestamp(Deref(p.Timestamp))
+	if err != nil {
+		return Prediction{}, fmt.Errorf("vesselapi: position timestamp: %w", err)
+	}
+	elapsed := at.Sub(reported)
+
+	pred := Prediction{At: at, Elapsed: elapsed, Course: m.Course, Speed: m.Speed}
+	if m.Speed > 0 {
+		if r := rateOfTurn(o.Recent, reported, o.TurnWindow); math.Abs(r) >= minRateOfTurn {
+			pred.RateOfTurn = r
+		}
+	}
+
+	if pred.RateOfTurn == 0 {
+		pred.Point = m.Project(elapsed)
+	} else {
+		// Turn in steps until the course has changed by MaxTurn, then hold
+		// the course for the rest of the time. The turn time is bounded by
+		// the elapsed time before converting, since a tiny rate of turn
+		// would overflow a Duration.
+		turnSeconds := o.MaxTurn / math.Abs(pred.RateOfTurn) * 60
+		turning := elapsed
+		if turnSeconds < math.Abs(elapsed.Seconds()) {
+			turning = time.Duration(math.Copysign(turnSeconds, float64(elapsed)) * float64(time.Second))
+		}
+		step := max(predictStep, (turning/maxTurnSteps).Abs())
+		if elapsed < 0 {
+			step = -step
+		}
+		pos := m.Position
+		for remaining := turning; remaining != 0; {
+			dt := step
+			if math.Abs(float64(remaining)) < math.Abs(float64(step)) {
+				dt = remaining
+			}
+			turn := pred.RateOfTurn * dt.Minutes()
+			// Advance on the mean course over the step.
+			pos = geo.Motion{Position: pos, Course: normalizeCourse(pred.Course + turn/2), Speed: m.Speed}.Project(dt)
+			pred.Course = normalizeCourse(pred.Course + turn)
+			remaining -= dt
+		}
+		pred.Point = geo.Motion{Position: pos, Course: pred.Course, Speed: m.Speed}.Project(elapsed - turning)
+	}
+
+	hours := math.Abs(elapsed.Hours())
+	along := geo.NMToMeters(o.SpeedError * hours)
+	across := geo.NMToMeters(m.Speed*hours) * math.Sin(o.CourseError*math.Pi/180)
+	pred.Uncertainty = o.BaseUncertainty + math.Hypot(along, across)
+	return pred, nil
+}
+
+// rateOfTurn estimates a rate of turn in degrees per minute from the
+// bearings of the track segments within window before t. It returns zero if
+// fewer than two usable segments are found.
+func rateOfTurn(track Track, t time.Time, window time.Duration) float64 {
+	type segment struct {
+		bearing float64
+		mid     time.Time
+	}
+	var segs []segment
+	var prev *TrackPoint
+	for i := range track {
+		p := &track[i]
+		if p.Time.After(t) || p.Time.Before(t.Add(-window)) {
+			continue
+		}
+		if prev != nil && geo.Distance(prev.Point, p.Point) >= minTurnSegment {
+			segs = append(segs, segment{
+				bearing: geo.Bearing(prev.Point, p.Point),
+				mid:     prev.Time.Add(p.Time.Sub(prev.Time) / 2),
+			})
+			prev = p
+		} else if prev == nil {
+			prev = p
+		}
+	}
+	if len(segs) < 2 {
+		return 0
+	}
+	var turn float64
+	for i := 1; i < len(segs); i++ {
+		turn += signedCourseDelta(segs[i-1].bearing, segs[i].bearing)
+	}
+	minutes := segs[len(segs)-1].mid.Sub(segs[0].mid).Minutes()
+	if minutes <= 0 {
+		return 0
+	}
+	return turn / minutes
+}
+
+// signedCourseDelta returns the change of course from a to b in degrees,
+// between -180 and 180, positive to starboard.
+func signedCourseDelta(a, b float64) float64 {
+	return math.Mod(b-a+540, 360) - 180
+}
+
+// normalizeCourse returns c in the range [0, 360).
+func normalizeCourse(c float64) float64 {
+	c = math.Mod(c, 360)
+	if c < 0 {
+		c += 360
+	}
+	return c
+}
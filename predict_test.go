@@ -0,0 +1,158 @@
+package vesselapi
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+func TestPredict_Straight(t *testing.T) {
+	p := watchPos(1, "2025-01-01T00:00:00Z", 0, 0, 10, 90, 0)
+	at := time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC)
+	pred, err := Predict(p, at, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := geo.MetersToNM(geo.Distance(geo.Point{}, pred.Point)); math.Abs(d-10) > 0.01 {
+		t.Errorf("expected 10 NM travelled, got %.3f", d)
+	}
+	if math.Abs(pred.Point.Lat) > 1e-9 || pred.Point.Lon <= 0 {
+		t.Errorf("expected a point due east on the equator, got %+v", pred.Point)
+	}
+	if pred.Elapsed != time.Hour || pred.Course != 90 || pred.Speed != 10 {
+		t.Errorf("unexpected prediction %+v", pred)
+	}
+	want := 25 + math.Hypot(1852, 18520*math.Sin(5*math.Pi/180))
+	if math.Abs(pred.Uncertainty-want) > 0.01 {
+		t.Errorf("expected uncertainty %.1f m, got %.1f m", want, pred.Uncertainty)
+	}
+
+	later, _ := Predict(p, at.Add(time.Hour), nil)
+	if later.Uncertainty <= pred.Uncertainty {
+		t.Errorf("expected uncertainty to grow with time, got %.1f then %.1f", pred.Uncertainty, later.Uncertainty)
+	}
+}
+
+func TestPredict_Stationary(t *testing.T) {
+	p := watchPos(1, "2025-01-01T00:00:00Z", 10, 10, 102.3, 360, 1)
+	pred, err := Predict(p, time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pred.Point != (geo.Point{Lat: 10, Lon: 10}) {
+		t.Errorf("expected the vessel to stay put, got %+v", pred.Point)
+	}
+	if want := 25 + 2*1852.0; math.Abs(pred.Uncertainty-want) > 0.01 {
+		t.Errorf("expected uncertainty %.0f m, got %.1f m", want, pred.Uncertainty)
+	}
+}
+
+func TestPredict_Errors(t *testing.T) {
+	if _, err := Predict(VesselPosition{Timestamp: Ptr("2025-01-01T00:00:00Z")}, time.Now(), nil); !errors.Is(err, ErrNoPosition) {
+		t.Errorf("expected ErrNoPosition, got %v", err)
+	}
+	if _, err := Predict(VesselPosition{Latitude: Ptr(0.0), Longitude: Ptr(0.0)}, time.Now(), nil); err == nil {
+		t.Error("expected an error for a missing timestamp")
+	}
+}
+
+func TestPredict_RateOfTurn(t *testing.T) {
+	// A vessel at 10 knots turning to starboard at 2 degrees per minute.
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pt, course := geo.Point{}, 0.0
+	var recent Track
+	for i := 0; i <= 10; i++ {
+		recent = append(recent, TrackPoint{Point: pt, Time: start.Add(time.Duration(i) * time.Minute)})
+		pt = geo.Motion{Position: pt, Course: course + 1, Speed: 10}.Project(time.Minute)
+		course += 2
+	}
+	last := recent[len(recent)-1]
+	p := watchPos(1, last.Time.Format(time.RFC3339), last.Point.Lat, last.Point.Lon, 10, float32(course), 0)
+
+	pred, err := Predict(p, last.Time.Add(30*time.Minute), &PredictOptions{Recent: recent})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(pred.RateOfTurn-2) > 0.05 {
+		t.Errorf("expected a rate of turn of 2°/min, got %.3f", pred.RateOfTurn)
+	}
+	if want := course + 60; math.Abs(pred.Course-want) > 2 {
+		t.Errorf("expected course %.0f after 30 minutes, got %.1f", want, pred.Course)
+	}
+	straight, _ := Predict(p, last.Time.Add(30*time.Minute), nil)
+	if geo.Distance(pred.Point, straight.Point) < 500 {
+		t.Errorf("expected the turn to move the prediction off the straight line")
+	}
+
+	capped, _ := Predict(p, last.Time.Add(2*time.Hour), &PredictOptions{Recent: recent})
+	if want := course + 90; math.Abs(capped.Course-want) > 2 {
+		t.Errorf("expected course capped at %.0f, got %.1f", want, capped.Course)
+	}
+
+	// Only track points up to the report are used: here just the first.
+	if r := rateOfTurn(recent, start, time.Hour); r != 0 {
+		t.Errorf("expected no rate of turn from a single point, got %v", r)
+	}
+}
+
+func TestPredict_TinyRateOfTurn(t *testing.T) {
+	// A straight northbound track with a rounding-level kink is taken as a
+	// straight course, even when predicting far from the report.
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := Track{
+		{Point: geo.Point{Lat: 0, Lon: 0}, Time: start},
+		{Point: geo.Point{Lat: 0.003, Lon: 0}, Time: start.Add(time.Minute)},
+		{Point: geo.Point{Lat: 0.006, Lon: 1e-14}, Time: start.Add(2 * time.Minute)},
+	}
+	last := recent[len(recent)-1]
+	p := watchPos(1, last.Time.Format(time.RFC3339), last.Point.Lat, last.Point.Lon, 10, 0, 0)
+
+	pred, err := Predict(p, last.Time.Add(time.Hour), &PredictOptions{Recent: recent})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pred.RateOfTurn != 0 {
+		t.Errorf("expected no rate of turn, got %g", pred.RateOfTurn)
+	}
+	if d := geo.MetersToNM(geo.Distance(last.Point, pred.Point)); math.Abs(d-10) > 0.01 {
+		t.Errorf("expected 10 NM travelled, got %.3f", d)
+	}
+	if pred.Point.Lat <= last.Point.Lat {
+		t.Errorf("expected the vessel to keep heading north, got %+v", pred.Point)
+	}
+}
+
+func TestPredict_FarFromReport(t *testing.T) {
+	// A vessel turning at 0.2 degrees per minute with no cap on the total
+	// turn, predicted at the zero time and a century ahead.
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pt, course := geo.Point{}, 0.0
+	var recent Track
+	for i := 0; i <= 10; i++ {
+		recent = append(recent, TrackPoint{Point: pt, Time: start.Add(time.Duration(i) * time.Minute)})
+		pt = geo.Motion{Position: pt, Course: course + 0.1, Speed: 10}.Project(time.Minute)
+		course += 0.2
+	}
+	last := recent[len(recent)-1]
+	p := watchPos(1, last.Time.Format(time.RFC3339), last.Point.Lat, last.Point.Lon, 10, float32(course), 0)
+	opts := &PredictOptions{Recent: recent, MaxTurn: 1e12}
+
+	for _, at := range []time.Time{{}, last.Time.AddDate(100, 0, 0)} {
+		done := make(chan Prediction)
+		go func() {
+			pred, _ := Predict(p, at, opts)
+			done <- pred
+		}()
+		select {
+		case pred := <-done:
+			if pred.RateOfTurn == 0 || math.IsNaN(pred.Point.Lat) || math.IsNaN(pred.Point.Lon) {
+				t.Errorf("%s: expected a turning prediction, got %+v", at, pred)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: prediction did not finish", at)
+		}
+	}
+}
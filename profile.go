@@ -0,0 +1,198 @@
+package vesselapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ProfileSection names one part of a VesselProfile.
+type ProfileSection string
+
+const (
+	ProfileDetails        ProfileSection = "details"
+	ProfilePosition       ProfileSection = "position"
+	ProfileETA            ProfileSection = "eta"
+	ProfileOwnership      ProfileSection = "ownership"
+	ProfileClassification ProfileSection = "classification"
+	ProfileInspections    ProfileSection = "inspections"
+	ProfileCasualties     ProfileSection = "casualties"
+	ProfileEmissions      ProfileSection = "emissions"
+	ProfileLastPortEvent  ProfileSection = "last_port_event"
+)
+
+// AllProfileSections lists every section, in the order they appear in
+// VesselProfile. Profile fetches all of them when no sections are given.
+var AllProfileSections = []ProfileSection{
+	ProfileDetails,
+	ProfilePosition,
+	ProfileETA,
+	ProfileOwnership,
+	ProfileClassification,
+	ProfileInspections,
+	ProfileCasualties,
+	ProfileEmissions,
+	ProfileLastPortEvent,
+}
+
+// VesselProfile combines the records available for one vessel. Fields for
+// sections that were not requested or that failed are left empty; the
+// failures are recorded in Errors.
+type VesselProfile struct {
+	Vessel         *Vessel
+	Position       *VesselPosition
+	ETA            *VesselETA
+	Ownership      *TypesVesselOwnership
+	Classification *ClassificationVessel
+	Inspections    []TypesInspection
+	Casualties     []MarineCasualty
+	Emissions      []VesselEmission
+	LastPortEvent  *PortEvent
+
+	// Errors holds the error of every section that failed. A section the
+	// API has no record for fails with a not-found *APIError.
+	Errors map[ProfileSection]error
+}
+
+// Err returns the section errors joined in AllProfileSections order, or nil
+// if every requested section succeeded.
+func (p *VesselProfile) Err() error {
+	var errs []error
+	for _, s := range AllProfileSections {
+		if err := p.Errors[s]; err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Profile fetches the given sections for the vessel with IMO number id
+// concurrently, or every section if none are given. Casualties and
+// emissions include every page.
+//
+// Section failures do not fail the call: the profile is returned with the
+// successful sections filled in and the failures recorded in Errors. An
+// error is returned only for an unknown section, or when every requested
+// section failed, in which case it is the profile's Err.
+func (s *VesselsService) Profile(ctx context.Context, id string, sections ...ProfileSection) (*VesselProfile, error) {
+	return s.profile(ctx, id, "imo", sections)
+}
+
+// ProfileByMMSI is like Profile but identifies the vessel by MMSI.
+func (s *VesselsService) ProfileByMMSI(ctx context.Context, mmsi string, sections ...ProfileSection) (*VesselProfile, error) {
+	return s.profile(ctx, mmsi, "mmsi", sections)
+}
+
+func (s *VesselsService) profile(ctx context.Context, id, idType string, sections []ProfileSection) (*VesselProfile, error) {
+	if len(sections) == 0 {
+		sections = AllProfileSections
+	}
+	fetchers := s.profileFetchers(id, idType)
+	want := make(map[ProfileSection]bool, len(sections))
+	for _, sec := range sections {
+		if fetchers[sec] == nil {
+			return nil, fmt.Errorf("vesselapi: unknown profile section %q", sec)
+		}
+		want[sec] = true
+	}
+
+	profile := &VesselProfile{Errors: make(map[ProfileSection]error)}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for sec := range want {
+		wg.Add(1)
+		go func(sec ProfileSection) {
+			defer wg.Done()
+			set, err := fetchers[sec](ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				profile.Errors[sec] = err
+				return
+			}
+			set(profile)
+		}(sec)
+	}
+	wg.Wait()
+
+	if len(profile.Errors) == len(want) {
+		return profile, profile.Err()
+	}
+	return profile, nil
+}
+
+// profileFetcher fetches one section and returns a function that stores it
+// in a profile.
+type profileFetcher func(ctx context.Context) (func(*VesselProfile), error)
+
+func (s *VesselsService) profileFetchers(id, idType string) map[ProfileSection]profileFetcher {
+	events := &PortEventsService{client: s.client}
+	return map[ProfileSection]profileFetcher{
+		ProfileDetails: func(ctx context.Context) (func(*VesselProfile), error) {
+			resp, err := s.Get(ctx, id, &GetVesselIdParams{FilterIdType: GetVesselIdParamsFilterIdType(idType)})
+			if err != nil {
+				return nil, err
+			}
+			return func(p *VesselProfile) { p.Vessel = resp.Vessel }, nil
+		},
+		ProfilePosition: func(ctx context.Context) (func(*VesselProfile), error) {
+			resp, err := s.Position(ctx, id, &GetVesselIdPositionParams{FilterIdType: GetVesselIdPositionParamsFilterIdType(idType)})
+			if err != nil {
+				return nil, err
+			}
+			return func(p *VesselProfile) { p.Position = resp.VesselPosition }, nil
+		},
+		ProfileETA: func(ctx context.Context) (func(*VesselProfile), error) {
+			resp, err := s.ETA(ctx, id, &GetVesselIdEtaParams{FilterIdType: GetVesselIdEtaParamsFilterIdType(idType)})
+			if err != nil {
+				return nil, err
+			}
+			return func(p *VesselProfile) { p.ETA = resp.VesselEta }, nil
+		},
+		ProfileOwnership: func(ctx context.Context) (func(*VesselProfile), error) {
+			resp, err := s.Ownership(ctx, id, &GetVesselIdOwnershipParams{FilterIdType: GetVesselIdOwnershipParamsFilterIdType(idType)})
+			if err != nil {
+				return nil, err
+			}
+			return func(p *VesselProfile) { p.Ownership = resp.Ownership }, nil
+		},
+		ProfileClassification: func(ctx context.Context) (func(*VesselProfile), error) {
+			resp, err := s.Classification(ctx, id, &GetVesselIdClassificationParams{FilterIdType: GetVesselIdClassificationParamsFilterIdType(idType)})
+			if err != nil {
+				return nil, err
+			}
+			return func(p *VesselProfile) { p.Classification = resp.Classification }, nil
+		},
+		ProfileInspections: func(ctx context.Context) (func(*VesselProfile), error) {
+			resp, err := s.Inspections(ctx, id, &GetVesselIdInspectionsParams{FilterIdType: GetVesselIdInspectionsParamsFilterIdType(idType)})
+			if err != nil {
+				return nil, err
+			}
+			return func(p *VesselProfile) { p.Inspections = derefSlice(resp.Inspections) }, nil
+		},
+		ProfileCasualties: func(ctx context.Context) (func(*VesselProfile), error) {
+			items, err := s.AllCasualties(ctx, id, &GetVesselIdCasualtiesParams{FilterIdType: GetVesselIdCasualtiesParamsFilterIdType(idType)}).Collect()
+			if err != nil {
+				return nil, err
+			}
+			return func(p *VesselProfile) { p.Casualties = items }, nil
+		},
+		ProfileEmissions: func(ctx context.Context) (func(*VesselProfile), error) {
+			items, err := s.AllEmissions(ctx, id, &GetVesselIdEmissionsParams{FilterIdType: GetVesselIdEmissionsParamsFilterIdType(idType)}).Collect()
+			if err != nil {
+				return nil, err
+			}
+			return func(p *VesselProfile) { p.Emissions = items }, nil
+		},
+		ProfileLastPortEvent: func(ctx context.Context) (func(*VesselProfile), error) {
+			resp, err := events.LastByVessel(ctx, id, &GetPorteventsVesselIdLastParams{FilterIdType: GetPorteventsVesselIdLastParamsFilterIdType(idType)})
+			if err != nil {
+				return nil, err
+			}
+			return func(p *VesselProfile) { p.LastPortEvent = resp.PortEvent }, nil
+		},
+	}
+}
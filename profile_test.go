@@ -0,0 +1,120 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProfile_PartialFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("filter.idType"); got != "imo" {
+			t.Errorf("expected idType imo, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vessel/9811000":
+			json.NewEncoder(w).Encode(VesselResponse{Vessel: &Vessel{Name: Ptr("EVER GIVEN")}})
+		case "/vessel/9811000/position":
+			json.NewEncoder(w).Encode(VesselPositionResponse{VesselPosition: &VesselPosition{Mmsi: Ptr(353136000)}})
+		case "/vessel/9811000/casualties":
+			if r.URL.Query().Get("pagination.nextToken") == "" {
+				json.NewEncoder(w).Encode(MarineCasualtiesResponse{Casualties: &[]MarineCasualty{{}}, NextToken: Ptr("p2")})
+				return
+			}
+			json.NewEncoder(w).Encode(MarineCasualtiesResponse{Casualties: &[]MarineCasualty{{}}})
+		case "/vessel/9811000/ownership":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"message":"no ownership"}}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := vc.Vessels.Profile(context.Background(), "9811000",
+		ProfileDetails, ProfilePosition, ProfileCasualties, ProfileOwnership, ProfileDetails)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if Deref(p.Vessel.Name) != "EVER GIVEN" {
+		t.Errorf("expected vessel details, got %+v", p.Vessel)
+	}
+	if Deref(p.Position.Mmsi) != 353136000 {
+		t.Errorf("expected position, got %+v", p.Position)
+	}
+	if len(p.Casualties) != 2 {
+		t.Errorf("expected 2 casualties across pages, got %d", len(p.Casualties))
+	}
+	if p.Ownership != nil || p.ETA != nil {
+		t.Error("expected failed and unrequested sections to be empty")
+	}
+	if len(p.Errors) != 1 {
+		t.Fatalf("expected 1 section error, got %v", p.Errors)
+	}
+	var apiErr *APIError
+	if !errors.As(p.Errors[ProfileOwnership], &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("expected not-found ownership error, got %v", p.Errors[ProfileOwnership])
+	}
+	if err := p.Err(); err == nil || !strings.HasPrefix(err.Error(), "ownership: ") {
+		t.Errorf("expected joined ownership error, got %v", err)
+	}
+}
+
+func TestProfile_AllSectionsByMMSI(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("filter.idType"); got != "mmsi" {
+			t.Errorf("%s: expected idType mmsi, got %q", r.URL.Path, got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := vc.Vessels.ProfileByMMSI(context.Background(), "353136000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.Errors) != 0 {
+		t.Errorf("expected no section errors, got %v", p.Errors)
+	}
+}
+
+func TestProfile_Errors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"bad key"}}`)
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := vc.Vessels.Profile(context.Background(), "9811000", ProfileETA, ProfileEmissions)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsAuthError() {
+		t.Fatalf("expected auth error when every section fails, got %v", err)
+	}
+	if p == nil || len(p.Errors) != 2 {
+		t.Errorf("expected profile with 2 section errors, got %+v", p)
+	}
+
+	if _, err := vc.Vessels.Profile(context.Background(), "9811000", "crew"); err == nil {
+		t.Error("expected error for unknown section")
+	}
+}
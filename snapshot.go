@@ -0,0 +1,400 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSnapshotInterval    = 15 * time.Minute
+	defaultSnapshotConcurrency = 4
+)
+
+// DefaultSnapshotSections are the sections a Snapshotter captures when
+// SnapshotterOptions.Sections is empty.
+var DefaultSnapshotSections = []ProfileSection{ProfilePosition, ProfileETA, ProfileOwnership}
+
+// Snapshot is the state of one vessel at one point in time. Sections that
+// were not captured, or that the API had no record for, are nil.
+type Snapshot struct {
+	// ID is the vessel's IMO or MMSI number, as given to the Snapshotter.
+	ID string `json:"id"`
+
+	// At is when the snapshot was taken.
+	At time.Time `json:"at"`
+
+	Vessel    *Vessel               `json:"vessel,omitempty"`
+	Position  *VesselPosition       `json:"position,omitempty"`
+	ETA       *VesselETA            `json:"eta,omitempty"`
+	Ownership *TypesVesselOwnership `json:"ownership,omitempty"`
+}
+
+// Store keeps a timestamped history of snapshots. Implementations must be
+// safe for concurrent use. Snapshots returned by a Store share their
+// records with it and must not be modified.
+type Store interface {
+	// Put adds snapshots to the history.
+	Put(ctx context.Context, snapshots ...Snapshot) error
+
+	// History returns the snapshots of vessel id taken between from and
+	// to inclusive, oldest first. A zero from or to leaves that end of the
+	// range open.
+	History(ctx context.Context, id string, from, to time.Time) ([]Snapshot, error)
+
+	// Latest returns the most recent snapshot of vessel id, or nil if
+	// there is none.
+	Latest(ctx context.Context, id string) (*Snapshot, error)
+}
+
+// MemoryStore is a Store that keeps snapshots in memory. The zero value is
+// ready to use and keeps every snapshot.
+type MemoryStore struct {
+	// Keep, if positive, is the number of most recent snapshots kept per
+	// vessel; older ones are discarded as new ones are added.
+	Keep int
+
+	mu    sync.Mutex
+	snaps map[string][]Snapshot
+}
+
+// Put adds snapshots, keeping each vessel's history in time order.
+func (s *MemoryStore) Put(ctx context.Context, snapshots ...Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.snaps == nil {
+		s.snaps = make(map[string][]Snapshot)
+	}
+	for _, snap := range snapshots {
+		h := s.snaps[snap.ID]
+		i := sort.Search(len(h), func(i int) bool { return h[i].At.After(snap.At) })
+		h = append(h, Snapshot{})
+		copy(h[i+1:], h[i:])
+		h[i] = snap
+		if s.Keep > 0 && len(h) > s.Keep {
+			h = append([]Snapshot(nil), h[len(h)-s.Keep:]...)
+		}
+		s.snaps[snap.ID] = h
+	}
+	return nil
+}
+
+// History returns the snapshots of vessel id taken between from and to.
+func (s *MemoryStore) History(ctx context.Context, id string, from, to time.Time) ([]Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Snapshot
+	for _, snap := range s.snaps[id] {
+		if inRange(snap.At, from, to) {
+			out = append(out, snap)
+		}
+	}
+	return out, nil
+}
+
+// Latest returns the most recent snapshot of vessel id.
+func (s *MemoryStore) Latest(ctx context.Context, id string) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := s.snaps[id]
+	if len(h) == 0 {
+		return nil, nil
+	}
+	snap := h[len(h)-1]
+	return &snap, nil
+}
+
+// FileStore is a Store that appends snapshots to a file as JSON lines.
+// Queries read the whole file, so it suits histories of modest size; use a
+// database-backed Store for larger ones.
+type FileStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Put appends snapshots to the file, creating it if needed. The snapshots
+// are written with a single write.
+func (s *FileStore) Put(ctx context.Context, snapshots ...Snapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	var buf []byte
+	for _, snap := range snapshots {
+		data, err := json.Marshal(snap)
+		if err != nil {
+			return fmt.Errorf("vesselapi: encoding snapshot: %w", err)
+		}
+		buf = append(append(buf, data...), '\n')
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("vesselapi: writing snapshots: %w", err)
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return fmt.Errorf("vesselapi: writing snapshots: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("vesselapi: writing snapshots: %w", err)
+	}
+	return nil
+}
+
+// History returns the snapshots of vessel id taken between from and to. A
+// missing file holds no snapshots.
+func (s *FileStore) History(ctx context.Context, id string, from, to time.Time) ([]Snapshot, error) {
+	var out []Snapshot
+	err := s.scan(func(snap Snapshot) {
+		if snap.ID == id && inRange(snap.At, from, to) {
+			out = append(out, snap)
+		}
+	})
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, err
+}
+
+// Latest returns the most recent snapshot of vessel id. Of snapshots taken
+// at the same time, the one written last wins.
+func (s *FileStore) Latest(ctx context.Context, id string) (*Snapshot, error) {
+	var latest *Snapshot
+	err := s.scan(func(snap Snapshot) {
+		if snap.ID == id && (latest == nil || !snap.At.Before(latest.At)) {
+			latest = &snap
+		}
+	})
+	return latest, err
+}
+
+// scan calls fn for every snapshot in the file, in file order.
+func (s *FileStore) scan(fn func(Snapshot)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("vesselapi: reading snapshots: %w", err)
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	for {
+		var snap Snapshot
+		err := dec.Decode(&snap)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("vesselapi: decoding snapshots %s: %w", s.Path, err)
+		}
+		fn(snap)
+	}
+}
+
+// inRange reports whether t lies between from and to inclusive, treating a
+// zero bound as open.
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+}
+
+// SnapshotterOptions configures a Snapshotter. A nil *SnapshotterOptions
+// uses the defaults.
+type SnapshotterOptions struct {
+	// IDType selects whether the vessel IDs are IMO (default) or MMSI
+	// numbers.
+	IDType GetVesselIdParamsFilterIdType
+
+	// Sections are the parts of each vessel captured: any of
+	// ProfileDetails, ProfilePosition, ProfileETA and ProfileOwnership.
+	// Defaults to DefaultSnapshotSections.
+	Sections []ProfileSection
+
+	// Interval is the time between captures. Defaults to 15 minutes.
+	Interval time.Duration
+
+	// Jitter randomizes each interval by up to this fraction in either
+	// direction. Defaults to 0.1; set a negative value to disable.
+	Jitter float64
+
+	// Concurrency is the number of vessels fetched at once. Defaults to 4.
+	Concurrency int
+
+	// OnError, if set, is called with the vessel ID for every section that
+	// failed to fetch. Sections the API has no record for are not errors;
+	// they are left nil in the snapshot. It may be called from several
+	// goroutines at once.
+	OnError func(id string, err error)
+}
+
+// Snapshotter periodically captures the state of a fleet into a Store.
+// Create one with NewSnapshotter and start it with Run, or call Capture
+// directly.
+type Snapshotter struct {
+	vessels *VesselsService
+	store   Store
+	opts    SnapshotterOptions
+	now     func() time.Time
+
+	mu      sync.Mutex
+	running bool
+	ids     map[string]bool
+}
+
+// NewSnapshotter returns a Snapshotter that stores snapshots of the vessels
+// with the given IDs in store. It returns an error for a section a Snapshot
+// has no field for.
+func NewSnapshotter(vessels *VesselsService, store Store, ids []string, opts *SnapshotterOptions) (*Snapshotter, error) {
+	o := SnapshotterOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.IDType == "" {
+		o.IDType = GetVesselIdParamsFilterIdTypeImo
+	}
+	if len(o.Sections) == 0 {
+		o.Sections = DefaultSnapshotSections
+	}
+	for _, sec := range o.Sections {
+		switch sec {
+		case ProfileDetails, ProfilePosition, ProfileETA, ProfileOwnership:
+		default:
+			return nil, fmt.Errorf("vesselapi: section %q cannot be snapshotted", sec)
+		}
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultSnapshotInterval
+	}
+	if o.Jitter == 0 {
+		o.Jitter = defaultWatchJitter
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultSnapshotConcurrency
+	}
+	s := &Snapshotter{
+		vessels: vessels,
+		store:   store,
+		opts:    o,
+		now:     time.Now,
+		ids:     make(map[string]bool),
+	}
+	s.Add(ids...)
+	return s, nil
+}
+
+// Add includes the given vessels from the next capture.
+func (s *Snapshotter) Add(ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.ids[id] = true
+	}
+}
+
+// Remove excludes the given vessels from the next capture. Their history
+// stays in the store.
+func (s *Snapshotter) Remove(ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.ids, id)
+	}
+}
+
+// IDs returns the IDs of the captured vessels in ascending order.
+func (s *Snapshotter) IDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.ids))
+	for id := range s.ids {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Capture fetches the configured sections for every vessel, stores a
+// snapshot of each and returns them in ID order. All snapshots of one
+// capture share the same At. Section failures are reported to OnError; a
+// vessel whose sections all failed is left out, unless the API merely had
+// no records for it. An error is returned only if ctx is cancelled or the
+// store fails.
+func (s *Snapshotter) Capture(ctx context.Context) ([]Snapshot, error) {
+	ids := s.IDs()
+	at := s.now().UTC()
+	snaps := make([]*Snapshot, len(ids))
+	err := runPool(ctx, len(ids), s.opts.Concurrency, func(ctx context.Context, i int) error {
+		snaps[i] = s.capture(ctx, ids[i], at)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out []Snapshot
+	for _, snap := range snaps {
+		if snap != nil {
+			out = append(out, *snap)
+		}
+	}
+	if err := s.store.Put(ctx, out...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// capture fetches one vessel, or returns nil if nothing could be fetched.
+func (s *Snapshotter) capture(ctx context.Context, id string, at time.Time) *Snapshot {
+	p, err := s.vessels.profile(ctx, id, string(s.opts.IDType), s.opts.Sections)
+	if p == nil || ctx.Err() != nil {
+		return nil
+	}
+	var failed bool
+	for _, sec := range s.opts.Sections {
+		secErr := p.Errors[sec]
+		var apiErr *APIError
+		if secErr == nil || (errors.As(secErr, &apiErr) && apiErr.IsNotFound()) {
+			continue
+		}
+		failed = true
+		if s.opts.OnError != nil {
+			s.opts.OnError(id, fmt.Errorf("%s: %w", sec, secErr))
+		}
+	}
+	if err != nil && failed {
+		return nil
+	}
+	return &Snapshot{ID: id, At: at, Vessel: p.Vessel, Position: p.Position, ETA: p.ETA, Ownership: p.Ownership}
+}
+
+// Run captures immediately and then every Interval until ctx is cancelled,
+// when it returns nil. It returns the error of a failed store write. Run
+// may only be called once.
+func (s *Snapshotter) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return errors.New("vesselapi: snapshotter already running")
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	for {
+		if _, err := s.Capture(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if sleepCtx(ctx, jitterDuration(s.opts.Interval, s.opts.Jitter)) != nil {
+			return nil
+		}
+	}
+}
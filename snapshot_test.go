@@ -0,0 +1,159 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	return map[string]Store{
+		"memory": &MemoryStore{},
+		"file":   &FileStore{Path: filepath.Join(t.TempDir(), "snapshots.jsonl")},
+	}
+}
+
+func TestStores(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return t0.Add(time.Duration(h) * time.Hour) }
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if snap, err := store.Latest(ctx, "1"); snap != nil || err != nil {
+				t.Fatalf("expected no snapshot in an empty store, got %+v, %v", snap, err)
+			}
+			err := store.Put(ctx,
+				Snapshot{ID: "1", At: at(2), Position: &VesselPosition{Sog: Ptr(float32(12))}},
+				Snapshot{ID: "1", At: at(0)},
+				Snapshot{ID: "2", At: at(1)},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := store.Put(ctx, Snapshot{ID: "1", At: at(1), ETA: &VesselETA{Destination: Ptr("NLRTM")}}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			h, err := store.History(ctx, "1", time.Time{}, time.Time{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(h) != 3 || !h[0].At.Equal(at(0)) || !h[1].At.Equal(at(1)) || !h[2].At.Equal(at(2)) {
+				t.Errorf("expected 3 snapshots oldest first, got %+v", h)
+			}
+			h, _ = store.History(ctx, "1", at(1), at(1))
+			if len(h) != 1 || Deref(h[0].ETA.Destination) != "NLRTM" {
+				t.Errorf("expected the snapshot at hour 1, got %+v", h)
+			}
+			snap, err := store.Latest(ctx, "1")
+			if err != nil || snap == nil || Deref(snap.Position.Sog) != 12 {
+				t.Errorf("expected the hour 2 snapshot, got %+v, %v", snap, err)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_Keep(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &MemoryStore{Keep: 2}
+	ctx := context.Background()
+	for h := 0; h < 5; h++ {
+		if err := store.Put(ctx, Snapshot{ID: "1", At: t0.Add(time.Duration(h) * time.Hour)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	h, _ := store.History(ctx, "1", time.Time{}, time.Time{})
+	if len(h) != 2 || !h[0].At.Equal(t0.Add(3*time.Hour)) || !h[1].At.Equal(t0.Add(4*time.Hour)) {
+		t.Errorf("expected the 2 latest snapshots, got %+v", h)
+	}
+}
+
+func TestSnapshotter_Capture(t *testing.T) {
+	var mu sync.Mutex
+	sog := float32(10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("filter.idType"); got != "mmsi" {
+			t.Errorf("expected idType mmsi, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/vessel/3"):
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"bad id"}}`)
+		case strings.HasSuffix(r.URL.Path, "/position"):
+			mu.Lock()
+			defer mu.Unlock()
+			json.NewEncoder(w).Encode(VesselPositionResponse{VesselPosition: &VesselPosition{Sog: Ptr(sog)}})
+		case strings.HasSuffix(r.URL.Path, "/eta"):
+			json.NewEncoder(w).Encode(VesselETAResponse{VesselEta: &VesselETA{Destination: Ptr("NLRTM")}})
+		case strings.HasSuffix(r.URL.Path, "/ownership"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"message":"no ownership"}}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var (
+		errMu  sync.Mutex
+		errIDs []string
+	)
+	store := &MemoryStore{}
+	s, err := NewSnapshotter(vc.Vessels, store, []string{"2", "1", "3"}, &SnapshotterOptions{
+		IDType: GetVesselIdParamsFilterIdTypeMmsi,
+		OnError: func(id string, err error) {
+			errMu.Lock()
+			defer errMu.Unlock()
+			errIDs = append(errIDs, id)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	snaps, err := s.Capture(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snaps) != 2 || snaps[0].ID != "1" || snaps[1].ID != "2" {
+		t.Fatalf("expected snapshots of 1 and 2, got %+v", snaps)
+	}
+	if snap := snaps[0]; !snap.At.Equal(now) || Deref(snap.Position.Sog) != 10 || Deref(snap.ETA.Destination) != "NLRTM" || snap.Ownership != nil {
+		t.Errorf("unexpected snapshot %+v", snap)
+	}
+	if len(errIDs) != 3 || errIDs[0] != "3" {
+		t.Errorf("expected three section errors for vessel 3, got %v", errIDs)
+	}
+
+	mu.Lock()
+	sog = 11
+	mu.Unlock()
+	now = now.Add(time.Hour)
+	if _, err := s.Capture(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h, _ := store.History(context.Background(), "1", time.Time{}, time.Time{})
+	if len(h) != 2 || Deref(h[0].Position.Sog) != 10 || Deref(h[1].Position.Sog) != 11 {
+		t.Errorf("expected two snapshots of vessel 1, got %+v", h)
+	}
+}
+
+func TestNewSnapshotter_InvalidSection(t *testing.T) {
+	if _, err := NewSnapshotter(nil, &MemoryStore{}, nil, &SnapshotterOptions{Sections: []ProfileSection{ProfileEmissions}}); err == nil {
+		t.Error("expected an error for a section a snapshot cannot hold")
+	}
+}
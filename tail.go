@@ -0,0 +1,327 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTailInterval = time.Minute
+	defaultTailLateness = 10 * time.Minute
+)
+
+// Watermark records how far a tail has progressed.
+type Watermark struct {
+	// Time is the latest event timestamp delivered.
+	Time time.Time `json:"time"`
+
+	// Seen maps the key of every event delivered within the lateness
+	// window before Time to its timestamp.
+	Seen map[string]time.Time `json:"seen,omitempty"`
+}
+
+// WatermarkStore persists a tail's Watermark between runs.
+type WatermarkStore interface {
+	// Load returns the stored watermark, or the zero Watermark if none has
+	// been saved.
+	Load(ctx context.Context) (Watermark, error)
+
+	// Save replaces the stored watermark.
+	Save(ctx context.Context, w Watermark) error
+}
+
+// MemoryWatermarkStore is a WatermarkStore that keeps the watermark in
+// memory. The zero value is ready to use.
+type MemoryWatermarkStore struct {
+	mu sync.Mutex
+	w  Watermark
+}
+
+// Load returns a copy of the stored watermark.
+func (s *MemoryWatermarkStore) Load(ctx context.Context) (Watermark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.clone(), nil
+}
+
+// Save stores a copy of w.
+func (s *MemoryWatermarkStore) Save(ctx context.Context, w Watermark) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w = w.clone()
+	return nil
+}
+
+// FileWatermarkStore is a WatermarkStore that keeps the watermark in a JSON
+// file. Saves replace the file atomically.
+type FileWatermarkStore struct {
+	Path string
+}
+
+// Load reads the watermark file. A missing file yields the zero Watermark.
+func (s FileWatermarkStore) Load(ctx context.Context) (Watermark, error) {
+	var w Watermark
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return w, fmt.Errorf("vesselapi: reading watermark: %w", err)
+	}
+	if err := json.Unmarshal(data, &w); err != nil {
+		return w, fmt.Errorf("vesselapi: decoding watermark %s: %w", s.Path, err)
+	}
+	return w, nil
+}
+
+// Save writes w to a temporary file and renames it over the watermark file.
+func (s FileWatermarkStore) Save(ctx context.Context, w Watermark) error {
+	data, err := json.Marshal(w)
+	if err != nil {
+		return fmt.Errorf("vesselapi: encoding watermark: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("vesselapi: writing watermark: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("vesselapi: writing watermark: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("vesselapi: writing watermark: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("vesselapi: writing watermark: %w", err)
+	}
+	return nil
+}
+
+func (w Watermark) clone() Watermark {
+	c := Watermark{Time: w.Time}
+	if w.Seen != nil {
+		c.Seen = make(map[string]time.Time, len(w.Seen))
+		for k, v := range w.Seen {
+			c.Seen[k] = v
+		}
+	}
+	return c
+}
+
+// TailOptions configures Tail. A nil *TailOptions uses the defaults.
+type TailOptions struct {
+	// Params filters the events, for example by country, port or event
+	// type. TimeFrom and TimeTo are managed by Tail and ignored.
+	Params *GetPorteventsParams
+
+	// Store persists the watermark. Defaults to a new
+	// MemoryWatermarkStore.
+	Store WatermarkStore
+
+	// Start is where to begin when the store holds no watermark. Defaults
+	// to the current time.
+	Start time.Time
+
+	// Interval is the time between polls. Defaults to one minute.
+	Interval time.Duration
+
+	// Jitter randomizes each interval by up to this fraction in either
+	// direction. Defaults to 0.1; set a negative value to disable.
+	Jitter float64
+
+	// Lateness is how far behind the watermark an event may be timestamped
+	// and still be delivered. Each poll re-reads this window, so larger
+	// values tolerate slower ingestion at the cost of bigger queries.
+	// Defaults to 10 minutes.
+	Lateness time.Duration
+
+	// OnLate, if set, is called for every event a poll returns that is
+	// timestamped before the lateness window and was not delivered before.
+	// Such events are not passed to the handler. Events published later
+	// than Lateness after their timestamp are only seen if the API returns
+	// them despite the window; increase Lateness to deliver them.
+	OnLate func(PortEvent)
+
+	// OnDrop, if set, is called with the parse error for every event that
+	// is not delivered because its timestamp is unusable.
+	OnDrop func(PortEvent, error)
+
+	// OnError, if set, is called when a poll fails with a retryable error.
+	// Tail keeps polling after such errors.
+	OnError func(error)
+}
+
+// Tail follows new port events, calling handler once for each in timestamp
+// order, until ctx is cancelled or an error stops it.
+//
+// Each poll requests events from the watermark minus the lateness window,
+// so overlapping windows are expected; events are deduplicated by vessel,
+// port, event type and timestamp. The watermark never moves past the
+// current time, so an event timestamped in the future does not hide the
+// events that follow it. After handler returns nil the watermark
+// is saved to the store, so an event is delivered exactly once across
+// restarts unless the process stops between the handler returning and the
+// save completing, in which case that event is delivered again.
+//
+// Tail returns nil when ctx is cancelled. It returns the error of a handler,
+// of the store, or of a poll that failed with an error that is not
+// retryable; the failed event is redelivered by the next Tail.
+func (s *PortEventsService) Tail(ctx context.Context, handler func(context.Context, PortEvent) error, opts *TailOptions) error {
+	o := TailOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Store == nil {
+		o.Store = &MemoryWatermarkStore{}
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultTailInterval
+	}
+	if o.Jitter == 0 {
+		o.Jitter = defaultWatchJitter
+	}
+	if o.Lateness <= 0 {
+		o.Lateness = defaultTailLateness
+	}
+
+	w, err := o.Store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if w.Time.IsZero() {
+		w.Time = o.Start
+		if w.Time.IsZero() {
+			w.Time = time.Now()
+		}
+		w.Time = w.Time.UTC()
+	}
+	if w.Seen == nil {
+		w.Seen = make(map[string]time.Time)
+	}
+
+	for {
+		err := s.tailPoll(ctx, handler, &o, &w)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && isRetryableErr(err):
+			if o.OnError != nil {
+				o.OnError(err)
+			}
+		case err != nil:
+			return err
+		}
+		if sleepCtx(ctx, jitterDuration(o.Interval, o.Jitter)) != nil {
+			return nil
+		}
+	}
+}
+
+// tailPoll fetches the events in the current window and delivers the new
+// ones, advancing w.
+func (s *PortEventsService) tailPoll(ctx context.Context, handler func(context.Context, PortEvent) error, o *TailOptions, w *Watermark) error {
+	now := time.Now().UTC()
+	params := GetPorteventsParams{}
+	if o.Params != nil {
+		params = *o.Params
+	}
+	params.TimeFrom = Ptr(w.Time.Add(-o.Lateness).Format(time.RFC3339))
+	params.TimeTo = nil
+	params.PaginationNextToken = nil
+	events, err := s.ListAll(ctx, &params).Collect()
+	if err != nil {
+		return err
+	}
+
+	type timed struct {
+		event PortEvent
+		at    time.Time
+	}
+	batch := make([]timed, 0, len(events))
+	for _, ev := range events {
+		at, err := parseTimestamp(Deref(ev.Timestamp))
+		if err != nil {
+			if o.OnDrop != nil {
+				o.OnDrop(ev, err)
+			}
+			continue
+		}
+		batch = append(batch, timed{ev, at})
+	}
+	sort.SliceStable(batch, func(i, j int) bool { return batch[i].at.Before(batch[j].at) })
+
+	for _, t := range batch {
+		key := portEventKey(t.event)
+		if _, dup := w.Seen[key]; dup {
+			continue
+		}
+		// An event before the window may have been delivered already, but
+		// its key is gone from Seen, so it cannot be delivered again.
+		if t.at.Before(w.Time.Add(-o.Lateness)) {
+			if o.OnLate != nil {
+				o.OnLate(t.event)
+			}
+			continue
+		}
+		if err := handler(ctx, t.event); err != nil {
+			return err
+		}
+		w.Seen[key] = t.at
+		if t.at.After(w.Time) {
+			w.Time = t.at
+			if w.Time.After(now) {
+				w.Time = now
+			}
+		}
+		for k, at := range w.Seen {
+			if at.Before(w.Time.Add(-o.Lateness)) {
+				delete(w.Seen, k)
+			}
+		}
+		if err := o.Store.Save(ctx, *w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// portEventKey identifies a port event by vessel, port, event type and
+// timestamp.
+func portEventKey(ev PortEvent) string {
+	return vesselRefKey(ev.Vessel) + "|" + portRefKey(ev.Port) + "|" + Deref(ev.Event) + "|" + Deref(ev.Timestamp)
+}
+
+// vesselRefKey identifies a vessel reference by IMO, falling back to MMSI
+// and then name, as "imo:9811000", "mmsi:353136000" or "name:EVER GIVEN".
+// It returns "" for a nil reference.
+func vesselRefKey(v *GithubComVesselapiCommonVesselDataContractsTypesVesselReference) string {
+	switch {
+	case v == nil:
+		return ""
+	case v.Imo != nil:
+		return fmt.Sprintf("imo:%d", *v.Imo)
+	case v.Mmsi != nil:
+		return fmt.Sprintf("mmsi:%d", *v.Mmsi)
+	}
+	return "name:" + Deref(v.Name)
+}
+
+// portRefKey identifies a port reference by UN/LOCODE, falling back to
+// "name:" and its name. It returns "" for a nil reference.
+func portRefKey(p *GithubComVesselapiCommonVesselDataContractsTypesPortReference) string {
+	switch {
+	case p == nil:
+		return ""
+	case p.UnloCode != nil:
+		return *p.UnloCode
+	}
+	return "name:" + Deref(p.Name)
+}
@@ -0,0 +1,273 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func tailEvent(imo int, port, event, ts string) PortEvent {
+	return PortEvent{
+		Event:     Ptr(event),
+		Timestamp: Ptr(ts),
+		Port:      &GithubComVesselapiCommonVesselDataContractsTypesPortReference{UnloCode: Ptr(port)},
+		Vessel:    &GithubComVesselapiCommonVesselDataContractsTypesVesselReference{Imo: Ptr(imo)},
+	}
+}
+
+// tailServer serves successive pages of port events and records the
+// time.from of each request. Like the API, it leaves out events before
+// time.from.
+type tailServer struct {
+	mu    sync.Mutex
+	polls [][]PortEvent
+	froms []string
+}
+
+func (s *tailServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.froms = append(s.froms, r.URL.Query().Get("time.from"))
+	var page []PortEvent
+	if len(s.polls) > 0 {
+		page, s.polls = s.polls[0], s.polls[1:]
+	}
+	from, _ := parseTimestamp(s.froms[len(s.froms)-1])
+	page = slices.DeleteFunc(slices.Clone(page), func(ev PortEvent) bool {
+		at, err := parseTimestamp(Deref(ev.Timestamp))
+		return err == nil && at.Before(from)
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PortEventsResponse{PortEvents: &page})
+}
+
+func TestTail_DeduplicatesAndWatermarks(t *testing.T) {
+	srv := &tailServer{polls: [][]PortEvent{
+		{
+			tailEvent(1, "NLRTM", "Arrival", "2025-01-01T10:05:00Z"),
+			tailEvent(2, "NLRTM", "Arrival", "2025-01-01T10:01:00Z"),
+		},
+		{
+			// Overlapping window: both repeat, plus one new and one late
+			// event within the lateness window.
+			tailEvent(2, "NLRTM", "Arrival", "2025-01-01T10:01:00Z"),
+			tailEvent(1, "NLRTM", "Arrival", "2025-01-01T10:05:00Z"),
+			tailEvent(1, "NLRTM", "Departure", "2025-01-01T10:20:00Z"),
+			tailEvent(3, "BEANR", "Arrival", "2025-01-01T10:03:00Z"),
+		},
+		{
+			// Published too late to fall in the window, unparseable and a
+			// new event.
+			tailEvent(4, "DEHAM", "Arrival", "2025-01-01T10:00:00Z"),
+			{Event: Ptr("Arrival"), Timestamp: Ptr("yesterday")},
+			tailEvent(5, "DEHAM", "Arrival", "2025-01-01T10:30:00Z"),
+		},
+	}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store := &MemoryWatermarkStore{}
+	var (
+		delivered []string
+		dropped   []error
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = vc.PortEvents.Tail(ctx, func(ctx context.Context, ev PortEvent) error {
+		delivered = append(delivered, fmt.Sprintf("%d %s", Deref(ev.Vessel.Imo), Deref(ev.Event)))
+		if len(delivered) == 5 {
+			cancel()
+		}
+		return nil
+	}, &TailOptions{
+		Params:   &GetPorteventsParams{FilterCountry: Ptr("NL")},
+		Store:    store,
+		Start:    time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+		Interval: time.Millisecond,
+		Lateness: 15 * time.Minute,
+		OnDrop:   func(ev PortEvent, err error) { dropped = append(dropped, err) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"2 Arrival", "1 Arrival", "3 Arrival", "1 Departure", "5 Arrival"}
+	if fmt.Sprint(delivered) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, delivered)
+	}
+	if len(dropped) != 1 {
+		t.Errorf("expected only the unparseable event to be dropped, got %v", dropped)
+	}
+	if srv.froms[0] != "2025-01-01T09:45:00Z" || srv.froms[2] != "2025-01-01T10:05:00Z" {
+		t.Errorf("unexpected time.from sequence %v", srv.froms)
+	}
+
+	w, _ := store.Load(context.Background())
+	if !w.Time.Equal(time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected watermark 10:30, got %s", w.Time)
+	}
+	// Only events within the lateness window of the watermark are kept.
+	if len(w.Seen) != 2 {
+		t.Errorf("expected 2 seen keys, got %v", w.Seen)
+	}
+}
+
+func TestTail_HandlerErrorRedelivers(t *testing.T) {
+	events := []PortEvent{
+		tailEvent(1, "NLRTM", "Arrival", "2025-01-01T10:01:00Z"),
+		tailEvent(2, "NLRTM", "Arrival", "2025-01-01T10:02:00Z"),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PortEventsResponse{PortEvents: &events})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store := FileWatermarkStore{Path: filepath.Join(t.TempDir(), "watermark.json")}
+	opts := &TailOptions{Store: store, Start: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), Interval: time.Millisecond}
+
+	boom := errors.New("boom")
+	var first []int
+	err = vc.PortEvents.Tail(context.Background(), func(ctx context.Context, ev PortEvent) error {
+		if Deref(ev.Vessel.Imo) == 2 {
+			return boom
+		}
+		first = append(first, Deref(ev.Vessel.Imo))
+		return nil
+	}, opts)
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected handler error, got %v", err)
+	}
+
+	// A restarted tail resumes from the file and delivers only the failed
+	// event.
+	var second []int
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = vc.PortEvents.Tail(ctx, func(ctx context.Context, ev PortEvent) error {
+		second = append(second, Deref(ev.Vessel.Imo))
+		cancel()
+		return nil
+	}, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(first) != "[1]" || fmt.Sprint(second) != "[2]" {
+		t.Errorf("expected [1] then [2], got %v then %v", first, second)
+	}
+}
+
+func TestTail_LateAndFutureEvents(t *testing.T) {
+	now := time.Now().UTC()
+	stamp := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+	// The server ignores time.from and returns each page in turn.
+	pages := [][]PortEvent{
+		{
+			tailEvent(1, "NLRTM", "Arrival", stamp(-25*time.Minute)),
+			tailEvent(2, "NLRTM", "Arrival", stamp(time.Hour)),
+		},
+		{
+			tailEvent(3, "NLRTM", "Arrival", stamp(-5*time.Minute)),
+			tailEvent(4, "NLRTM", "Arrival", stamp(-2*time.Hour)),
+		},
+	}
+	var polls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := pages[min(int(polls.Add(1)), len(pages))-1]
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PortEventsResponse{PortEvents: &page})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store := &MemoryWatermarkStore{}
+	var late []int
+	opts := &TailOptions{
+		Store:    store,
+		Start:    now.Add(-30 * time.Minute),
+		Interval: time.Millisecond,
+		OnLate:   func(ev PortEvent) { late = append(late, Deref(ev.Vessel.Imo)) },
+	}
+
+	var got []int
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = vc.PortEvents.Tail(ctx, func(ctx context.Context, ev PortEvent) error {
+		got = append(got, Deref(ev.Vessel.Imo))
+		if len(got) == 3 {
+			cancel()
+		}
+		return nil
+	}, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The future event does not move the watermark past now, so the event
+	// after it is still inside the window.
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Errorf("expected [1 2 3], got %v", got)
+	}
+	if fmt.Sprint(late) != "[4]" {
+		t.Errorf("expected late [4], got %v", late)
+	}
+	w, _ := store.Load(context.Background())
+	if w.Time.After(time.Now()) {
+		t.Errorf("expected watermark no later than now, got %v", w.Time)
+	}
+}
+
+func TestTail_StopsOnPermanentError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"bad key"}}`)
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = vc.PortEvents.Tail(context.Background(), func(context.Context, PortEvent) error { return nil }, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsAuthError() {
+		t.Errorf("expected auth error, got %v", err)
+	}
+}
+
+func TestFileWatermarkStore(t *testing.T) {
+	store := FileWatermarkStore{Path: filepath.Join(t.TempDir(), "wm.json")}
+	w, err := store.Load(context.Background())
+	if err != nil || !w.Time.IsZero() {
+		t.Fatalf("expected zero watermark for missing file, got %+v, %v", w, err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := store.Save(context.Background(), Watermark{Time: now, Seen: map[string]time.Time{"k": now}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w, err = store.Load(context.Background())
+	if err != nil || !w.Time.Equal(now) || !w.Seen["k"].Equal(now) {
+		t.Errorf("unexpected round trip %+v, %v", w, err)
+	}
+}
@@ -0,0 +1,26 @@
+package vesselapi
+
+import (
+	"fmt"
+	"time"
+)
+
+// timestampLayouts lists the formats the API has been observed to use for
+// timestamp fields, most specific first.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+}
+
+// parseTimestamp parses an API timestamp string and returns it in UTC.
+// Timestamps without a zone offset are interpreted as UTC.
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("vesselapi: unrecognized timestamp %q", s)
+}
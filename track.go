@@ -0,0 +1,221 @@
+package vesselapi
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// defaultTrackMaxSpeed is the default speed in knots above which the move
+// to a position is considered impossible.
+const defaultTrackMaxSpeed = 50
+
+// TrackPoint is one point of a Track.
+type TrackPoint struct {
+	Point geo.Point
+	Time  time.Time
+
+	// Position is the report the point was built from. It is nil for
+	// points added by Resample.
+	Position *VesselPosition
+}
+
+// Track is a vessel's path as a sequence of points in time order.
+type Track []TrackPoint
+
+// TrackOptions configures NewTrack. A nil *TrackOptions uses the defaults.
+type TrackOptions struct {
+	// MaxSpeed is the speed in knots above which the move to or from a
+	// report is considered impossible. Defaults to 50 knots.
+	MaxSpeed float64
+
+	// KeepGlitches keeps reports the API flags as SuspectedGlitch.
+	KeepGlitches bool
+}
+
+// NewTrack builds a track from one vessel's position reports, such as the
+// output of Vessels.AllPositions. Reports are sorted by Timestamp, and
+// reports without coordinates or a usable timestamp, reports flagged as
+// SuspectedGlitch and repeated timestamps are dropped.
+//
+// A report is also dropped as a spike when both reaching it from the
+// previous report and leaving it for the next imply a speed above MaxSpeed.
+// The first and last reports only have one neighbour, so they are dropped
+// when that single move is impossible and the rest of the track is not.
+func NewTrack(positions []VesselPosition, opts *TrackOptions) Track {
+	o := TrackOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxSpeed <= 0 {
+		o.MaxSpeed = defaultTrackMaxSpeed
+	}
+
+	points := make(Track, 0, len(positions))
+	for i := range positions {
+		p := positions[i]
+		if Deref(p.SuspectedGlitch) && !o.KeepGlitches {
+			continue
+		}
+		pt, ok := PositionPoint(p)
+		if !ok {
+			continue
+		}
+		at, err := parseTimestamp(Deref(p.Timestamp))
+		if err != nil {
+			continue
+		}
+		points = append(points, TrackPoint{Point: pt, Time: at, Position: &p})
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+
+	deduped := points[:0]
+	for _, p := range points {
+		if n := len(deduped); n > 0 && deduped[n-1].Time.Equal(p.Time) {
+			continue
+		}
+		deduped = append(deduped, p)
+	}
+	points = deduped
+
+	impossible := func(a, b TrackPoint) bool { return impliedSpeed(a, b) > o.MaxSpeed }
+	track := make(Track, 0, len(points))
+	for i, p := range points {
+		hasNext := i+1 < len(points)
+		if n := len(track); n > 0 {
+			if impossible(track[n-1], p) && (!hasNext || impossible(p, points[i+1])) {
+				continue
+			}
+		} else if hasNext && impossible(p, points[i+1]) && (i+2 >= len(points) || !impossible(points[i+1], points[i+2])) {
+			continue
+		}
+		track = append(track, p)
+	}
+	return track
+}
+
+// TracksByVessel groups position reports for many vessels, such as the
+// output of Location.AllVesselsBoundingBox, and builds a track for each.
+// Tracks are keyed as "mmsi:<MMSI>", or "imo:<IMO>" for reports without an
+// MMSI; reports with neither are dropped.
+func TracksByVessel(positions []VesselPosition, opts *TrackOptions) map[string]Track {
+	groups := make(map[string][]VesselPosition)
+	for _, p := range positions {
+		if k := positionKey(p); k != "" {
+			groups[k] = append(groups[k], p)
+		}
+	}
+	tracks := make(map[string]Track, len(groups))
+	for k, ps := range groups {
+		tracks[k] = NewTrack(ps, opts)
+	}
+	return tracks
+}
+
+// impliedSpeed returns the speed in knots needed to move from a to b in the
+// time between them.
+func impliedSpeed(a, b TrackPoint) float64 {
+	h := b.Time.Sub(a.Time).Hours()
+	if h <= 0 {
+		return 0
+	}
+	return geo.MetersToNM(geo.Distance(a.Point, b.Point)) / h
+}
+
+// Points returns the coordinates of the track.
+func (t Track) Points() []geo.Point {
+	pts := make([]geo.Point, len(t))
+	for i, p := range t {
+		pts[i] = p.Point
+	}
+	return pts
+}
+
+// Distance returns the great-circle length of the track in meters.
+func (t Track) Distance() float64 {
+	var d float64
+	for i := 1; i < len(t); i++ {
+		d += geo.Distance(t[i-1].Point, t[i].Point)
+	}
+	return d
+}
+
+// Duration returns the time between the first and last points.
+func (t Track) Duration() time.Duration {
+	if len(t) < 2 {
+		return 0
+	}
+	return t[len(t)-1].Time.Sub(t[0].Time)
+}
+
+// Resample returns a track with a point every interval from the first point
+// to the last, interpolated along the great circle between the surrounding
+// points. Gaps between consecutive points longer than maxGap are not
+// interpolated across: the samples resume at the point after the gap. A
+// non-positive maxGap interpolates across every gap.
+func (t Track) Resample(interval, maxGap time.Duration) Track {
+	if len(t) < 2 || interval <= 0 {
+		return append(Track(nil), t...)
+	}
+	var out Track
+	i := 0
+	for at := t[0].Time; !at.After(t[len(t)-1].Time); at = at.Add(interval) {
+		for i+1 < len(t) && t[i+1].Time.Before(at) {
+			i++
+		}
+		a := t[i]
+		if a.Time.Equal(at) || i+1 == len(t) {
+			out = append(out, a)
+			continue
+		}
+		b := t[i+1]
+		if b.Time.Equal(at) {
+			out = append(out, b)
+			continue
+		}
+		if gap := b.Time.Sub(a.Time); maxGap > 0 && gap > maxGap {
+			at = b.Time.Add(-interval)
+			continue
+		}
+		f := float64(at.Sub(a.Time)) / float64(b.Time.Sub(a.Time))
+		out = append(out, TrackPoint{Point: geo.Intermediate(a.Point, b.Point, f), Time: at})
+	}
+	return out
+}
+
+// Simplify returns the track reduced with the Douglas-Peucker algorithm: a
+// point is kept only if it lies more than tolerance meters from the
+// great-circle segment between the points kept around it. The first and
+// last points are always kept.
+func (t Track) Simplify(tolerance float64) Track {
+	if len(t) < 3 {
+		return append(Track(nil), t...)
+	}
+	keep := make([]bool, len(t))
+	keep[0], keep[len(t)-1] = true, true
+	stack := [][2]int{{0, len(t) - 1}}
+	for len(stack) > 0 {
+		r := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		first, last := r[0], r[1]
+		worst, worstDist := -1, tolerance
+		for i := first + 1; i < last; i++ {
+			if d := geo.DistanceToSegment(t[i].Point, t[first].Point, t[last].Point); d > worstDist {
+				worst, worstDist = i, d
+			}
+		}
+		if worst < 0 {
+			continue
+		}
+		keep[worst] = true
+		stack = append(stack, [2]int{first, worst}, [2]int{worst, last})
+	}
+	var out Track
+	for i, p := range t {
+		if keep[i] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
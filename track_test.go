@@ -0,0 +1,145 @@
+package vesselapi
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// trackTime returns the timestamp h hours after midnight on 2025-01-01.
+func trackTime(h float64) string {
+	return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(h * float64(time.Hour))).Format(time.RFC3339)
+}
+
+func TestNewTrack_FiltersAndSorts(t *testing.T) {
+	glitch := fencePos(trackTime(1.5), 0, 0.15)
+	glitch.SuspectedGlitch = Ptr(true)
+	positions := []VesselPosition{
+		fencePos(trackTime(2), 0, 0.2),
+		fencePos(trackTime(0), 0, 0),
+		fencePos(trackTime(1), 0, 0.1),
+		fencePos(trackTime(1), 0, 0.1), // duplicate
+		glitch,
+		fencePos(trackTime(2.5), 10, 10), // spike: 800+ NM in half an hour
+		fencePos(trackTime(3), 0, 0.3),
+		{Mmsi: Ptr(1), Timestamp: Ptr(trackTime(4))},            // no coordinates
+		{Mmsi: Ptr(1), Latitude: Ptr(0.0), Longitude: Ptr(0.4)}, // no timestamp
+	}
+	track := NewTrack(positions, nil)
+	if len(track) != 4 {
+		t.Fatalf("expected 4 points, got %d: %+v", len(track), track)
+	}
+	for i, p := range track {
+		if want := 0.1 * float64(i); math.Abs(p.Point.Lon-want) > 1e-9 {
+			t.Errorf("point %d: expected lon %.1f, got %v", i, want, p.Point.Lon)
+		}
+		if p.Position == nil {
+			t.Errorf("point %d: expected the source report", i)
+		}
+	}
+	if track.Duration() != 3*time.Hour {
+		t.Errorf("expected duration 3h, got %v", track.Duration())
+	}
+	if want := geo.Distance(geo.Point{}, geo.Point{Lon: 0.3}); math.Abs(track.Distance()-want) > 1 {
+		t.Errorf("expected distance %.0f, got %.0f", want, track.Distance())
+	}
+
+	withGlitches := NewTrack(positions, &TrackOptions{KeepGlitches: true})
+	if len(withGlitches) != 5 {
+		t.Errorf("expected 5 points with glitches kept, got %d", len(withGlitches))
+	}
+}
+
+func TestNewTrack_SpikeAtStart(t *testing.T) {
+	track := NewTrack([]VesselPosition{
+		fencePos(trackTime(0), 20, 20),
+		fencePos(trackTime(1), 0, 0),
+		fencePos(trackTime(2), 0, 0.1),
+	}, nil)
+	if len(track) != 2 || track[0].Point.Lon != 0 {
+		t.Errorf("expected the leading spike to be dropped, got %+v", track)
+	}
+}
+
+func TestTracksByVessel(t *testing.T) {
+	a := fencePos(trackTime(0), 0, 0)
+	b := fencePos(trackTime(0), 1, 1)
+	b.Mmsi = Ptr(2)
+	c := fencePos(trackTime(1), 1, 1.1)
+	c.Mmsi = Ptr(2)
+	tracks := TracksByVessel([]VesselPosition{a, b, c, {Timestamp: Ptr(trackTime(0))}}, nil)
+	if len(tracks) != 2 || len(tracks["mmsi:1"]) != 1 || len(tracks["mmsi:2"]) != 2 {
+		t.Errorf("unexpected tracks %+v", tracks)
+	}
+}
+
+func TestTrack_Resample(t *testing.T) {
+	track := NewTrack([]VesselPosition{
+		fencePos(trackTime(0), 0, 0),
+		fencePos(trackTime(1), 0, 0.1),
+		// Five hour gap.
+		fencePos(trackTime(6), 0, 0.6),
+		fencePos(trackTime(7), 0, 0.7),
+	}, nil)
+
+	all := track.Resample(30*time.Minute, 0)
+	if len(all) != 15 {
+		t.Fatalf("expected 15 samples, got %d", len(all))
+	}
+	for i, p := range all {
+		if want := 0.05 * float64(i); math.Abs(p.Point.Lon-want) > 1e-6 {
+			t.Errorf("sample %d: expected lon %.2f, got %v", i, want, p.Point.Lon)
+		}
+	}
+	if all[1].Position != nil || all[2].Position == nil {
+		t.Errorf("expected only original points to carry a report")
+	}
+
+	gapped := track.Resample(30*time.Minute, 2*time.Hour)
+	var times []string
+	for _, p := range gapped {
+		times = append(times, p.Time.Format("15:04"))
+	}
+	want := []string{"00:00", "00:30", "01:00", "06:00", "06:30", "07:00"}
+	if len(times) != len(want) {
+		t.Fatalf("expected samples at %v, got %v", want, times)
+	}
+	for i := range want {
+		if times[i] != want[i] {
+			t.Errorf("expected samples at %v, got %v", want, times)
+			break
+		}
+	}
+}
+
+func TestTrack_Simplify(t *testing.T) {
+	var positions []VesselPosition
+	// A straight line east with a small wobble and one real turn north.
+	for i := 0; i <= 10; i++ {
+		lat := 0.0
+		if i == 5 {
+			lat = 0.0001 // about 11 m
+		}
+		positions = append(positions, fencePos(trackTime(float64(i)), lat, 0.1*float64(i)))
+	}
+	positions = append(positions, fencePos(trackTime(11), 0.1, 1.0))
+	track := NewTrack(positions, nil)
+
+	simple := track.Simplify(100)
+	if len(simple) != 3 {
+		t.Fatalf("expected 3 points, got %d: %+v", len(simple), simple.Points())
+	}
+	if simple[1].Point.Lon != 1.0 || simple[1].Point.Lat != 0 {
+		t.Errorf("expected the corner to be kept, got %+v", simple[1].Point)
+	}
+	fine := track.Simplify(1)
+	var wobble bool
+	for _, p := range fine {
+		wobble = wobble || p.Point.Lat == 0.0001
+	}
+	if !wobble || len(fine) >= len(track) {
+		t.Errorf("expected a 1 m tolerance to keep the wobble but drop collinear points, got %+v", fine.Points())
+	}
+}
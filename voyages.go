@@ -0,0 +1,176 @@
+package vesselapi
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+// defaultVoyagePortConcurrency is the number of port lookups Voyages makes
+// at once.
+const defaultVoyagePortConcurrency = 4
+
+// Voyage is one leg of a vessel's itinerary: from its departure from one
+// port to its next arrival.
+type Voyage struct {
+	Vessel *GithubComVesselapiCommonVesselDataContractsTypesVesselReference
+
+	// From and To are the ports as referenced by the port events, and
+	// FromPort and ToPort their full records. FromPort and ToPort are nil
+	// when the port could not be looked up.
+	From     *GithubComVesselapiCommonVesselDataContractsTypesPortReference
+	To       *GithubComVesselapiCommonVesselDataContractsTypesPortReference
+	FromPort *Port
+	ToPort   *Port
+
+	Departure time.Time
+	Arrival   time.Time
+
+	// Distance is the great-circle distance between the ports in meters,
+	// and AverageSpeed the speed in knots needed to cover it in the transit
+	// time. Both are zero when either port's position is unknown. Actual
+	// routes are longer, so these are lower bounds.
+	Distance     float64
+	AverageSpeed float64
+}
+
+// TransitTime returns the time between departure and arrival.
+func (v Voyage) TransitTime() time.Duration {
+	return v.Arrival.Sub(v.Departure)
+}
+
+// Itinerary is a vessel's voyages in departure order.
+type Itinerary []Voyage
+
+// Distance returns the total great-circle distance of the voyages in meters.
+func (it Itinerary) Distance() float64 {
+	var d float64
+	for _, v := range it {
+		d += v.Distance
+	}
+	return d
+}
+
+// TransitTime returns the total time spent at sea between the ports.
+func (it Itinerary) TransitTime() time.Duration {
+	var d time.Duration
+	for _, v := range it {
+		d += v.TransitTime()
+	}
+	return d
+}
+
+// VoyagesFromCalls builds the voyages between consecutive port calls of each
+// vessel, as returned by ReconstructPortCalls. A leg is only built when the
+// departure from the first port and the arrival at the next are both known.
+// Ports are not looked up, so FromPort, ToPort, Distance and AverageSpeed
+// are left empty.
+func VoyagesFromCalls(calls []PortCall) Itinerary {
+	byVessel := make(map[string][]PortCall)
+	for _, c := range calls {
+		k := vesselRefKey(c.Vessel)
+		byVessel[k] = append(byVessel[k], c)
+	}
+
+	var voyages Itinerary
+	for _, cs := range byVessel {
+		sort.SliceStable(cs, func(i, j int) bool { return cs[i].start().Before(cs[j].start()) })
+		for i := 0; i+1 < len(cs); i++ {
+			from, to := cs[i], cs[i+1]
+			if from.Departure == nil || to.Arrival == nil {
+				continue
+			}
+			voyages = append(voyages, Voyage{
+				Vessel:    from.Vessel,
+				From:      from.Port,
+				To:        to.Port,
+				Departure: from.DepartureTime,
+				Arrival:   to.ArrivalTime,
+			})
+		}
+	}
+	sort.SliceStable(voyages, func(i, j int) bool {
+		if !voyages[i].Departure.Equal(voyages[j].Departure) {
+			return voyages[i].Departure.Before(voyages[j].Departure)
+		}
+		return vesselRefKey(voyages[i].Vessel) < vesselRefKey(voyages[j].Vessel)
+	})
+	return voyages
+}
+
+// Voyages returns the itinerary of the vessel with IMO number id between
+// from and to. A zero from or to leaves that end of the range to the API
+// default.
+//
+// The voyages are built from the vessel's port events, and each port is
+// looked up with Ports.Get to fill in FromPort, ToPort, Distance and
+// AverageSpeed. Ports the API has no record for are left nil; any other
+// lookup error fails the call. Voyages that started before from or end
+// after to are not included.
+func (s *PortEventsService) Voyages(ctx context.Context, id string, from, to time.Time) (Itinerary, error) {
+	params := &GetPorteventsVesselIdParams{FilterIdType: GetPorteventsVesselIdParamsFilterIdTypeImo}
+	if !from.IsZero() {
+		params.TimeFrom = Ptr(from.UTC().Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		params.TimeTo = Ptr(to.UTC().Format(time.RFC3339))
+	}
+	calls, err := s.PortCallsByVessel(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+	voyages := VoyagesFromCalls(calls)
+
+	var codes []string
+	seen := make(map[string]bool)
+	for _, v := range voyages {
+		for _, ref := range []*GithubComVesselapiCommonVesselDataContractsTypesPortReference{v.From, v.To} {
+			if code := Deref(ref.UnloCode); code != "" && !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	ports := make(map[string]*Port, len(codes))
+	var mu sync.Mutex
+	portsService := &PortsService{client: s.client}
+	workers := concurrency(0, defaultVoyagePortConcurrency, len(codes))
+	err = runPool(ctx, len(codes), workers, func(ctx context.Context, i int) error {
+		resp, err := portsService.Get(ctx, codes[i])
+		if isNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		ports[codes[i]] = resp.Port
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range voyages {
+		v := &voyages[i]
+		v.FromPort = ports[Deref(v.From.UnloCode)]
+		v.ToPort = ports[Deref(v.To.UnloCode)]
+		if v.FromPort == nil || v.ToPort == nil {
+			continue
+		}
+		a, okA := portPoint(*v.FromPort)
+		b, okB := portPoint(*v.ToPort)
+		if !okA || !okB {
+			continue
+		}
+		v.Distance = geo.Distance(a, b)
+		if h := v.TransitTime().Hours(); h > 0 {
+			v.AverageSpeed = geo.MetersToNM(v.Distance) / h
+		}
+	}
+	return voyages, nil
+}
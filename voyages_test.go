@@ -0,0 +1,115 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+func TestVoyagesFromCalls(t *testing.T) {
+	calls := ReconstructPortCalls([]PortEvent{
+		tailEvent(1, "NLRTM", "Departure", "2025-01-01T00:00:00Z"),
+		tailEvent(1, "BEANR", "Arrival", "2025-01-01T10:00:00Z"),
+		tailEvent(1, "BEANR", "Departure", "2025-01-02T00:00:00Z"),
+		// Departure from Hamburg missing: no leg from Hamburg.
+		tailEvent(1, "DEHAM", "Arrival", "2025-01-03T00:00:00Z"),
+		tailEvent(1, "NOOSL", "Arrival", "2025-01-05T00:00:00Z"),
+		tailEvent(2, "NLRTM", "Departure", "2025-01-01T00:00:00Z"),
+		tailEvent(2, "GBLON", "Arrival", "2025-01-01T12:00:00Z"),
+	})
+	voyages := VoyagesFromCalls(calls)
+	want := []struct {
+		vessel, from, to string
+		transit          time.Duration
+	}{
+		{"imo:1", "NLRTM", "BEANR", 10 * time.Hour},
+		{"imo:2", "NLRTM", "GBLON", 12 * time.Hour},
+		{"imo:1", "BEANR", "DEHAM", 24 * time.Hour},
+	}
+	if len(voyages) != len(want) {
+		t.Fatalf("expected %d voyages, got %d: %+v", len(want), len(voyages), voyages)
+	}
+	for i, w := range want {
+		v := voyages[i]
+		if vesselRefKey(v.Vessel) != w.vessel || portRefKey(v.From) != w.from || portRefKey(v.To) != w.to {
+			t.Errorf("voyage %d: expected %s %s→%s, got %s %s→%s", i, w.vessel, w.from, w.to, vesselRefKey(v.Vessel), portRefKey(v.From), portRefKey(v.To))
+		}
+		if v.TransitTime() != w.transit {
+			t.Errorf("voyage %d: expected transit %v, got %v", i, w.transit, v.TransitTime())
+		}
+	}
+	if got := voyages.TransitTime(); got != 46*time.Hour {
+		t.Errorf("expected total transit 46h, got %v", got)
+	}
+}
+
+func TestVoyages(t *testing.T) {
+	rtm := geo.Point{Lat: 51.95, Lon: 4.05}
+	anr := geo.Point{Lat: 51.25, Lon: 4.40}
+	var timeFrom string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/portevents/vessel/9811000":
+			timeFrom = r.URL.Query().Get("time.from")
+			events := []PortEvent{
+				tailEvent(9811000, "NLRTM", "Arrival", "2025-01-01T00:00:00Z"),
+				tailEvent(9811000, "NLRTM", "Departure", "2025-01-01T12:00:00Z"),
+				tailEvent(9811000, "BEANR", "Arrival", "2025-01-01T16:00:00Z"),
+				tailEvent(9811000, "BEANR", "Departure", "2025-01-02T00:00:00Z"),
+				tailEvent(9811000, "XXUNK", "Arrival", "2025-01-03T00:00:00Z"),
+			}
+			json.NewEncoder(w).Encode(PortEventsResponse{PortEvents: &events})
+		case "/port/NLRTM":
+			json.NewEncoder(w).Encode(PortResponse{Port: &Port{UnloCode: Ptr("NLRTM"), Latitude: Ptr(rtm.Lat), Longitude: Ptr(rtm.Lon)}})
+		case "/port/BEANR":
+			json.NewEncoder(w).Encode(PortResponse{Port: &Port{UnloCode: Ptr("BEANR"), Latitude: Ptr(anr.Lat), Longitude: Ptr(anr.Lon)}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"not found"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	voyages, err := vc.PortEvents.Voyages(context.Background(), "9811000", from, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeFrom != "2025-01-01T00:00:00Z" {
+		t.Errorf("expected time.from 2025-01-01T00:00:00Z, got %q", timeFrom)
+	}
+	if len(voyages) != 2 {
+		t.Fatalf("expected 2 voyages, got %d", len(voyages))
+	}
+
+	leg := voyages[0]
+	if leg.FromPort == nil || leg.ToPort == nil {
+		t.Fatalf("expected both ports to be enriched, got %+v", leg)
+	}
+	wantDist := geo.Distance(rtm, anr)
+	if math.Abs(leg.Distance-wantDist) > 1 {
+		t.Errorf("expected distance %.0f m, got %.0f m", wantDist, leg.Distance)
+	}
+	if wantSpeed := geo.MetersToNM(wantDist) / 4; math.Abs(leg.AverageSpeed-wantSpeed) > 0.01 {
+		t.Errorf("expected average speed %.2f kn, got %.2f kn", wantSpeed, leg.AverageSpeed)
+	}
+
+	unknown := voyages[1]
+	if unknown.ToPort != nil || unknown.Distance != 0 || unknown.AverageSpeed != 0 {
+		t.Errorf("expected unknown destination to be left empty, got %+v", unknown)
+	}
+	if voyages.Distance() != leg.Distance {
+		t.Errorf("expected itinerary distance %.0f, got %.0f", leg.Distance, voyages.Distance())
+	}
+}
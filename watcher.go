@@ -0,0 +1,370 @@
+package vesselapi
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vessel-api/vesselapi-go/v3/geo"
+)
+
+const (
+	defaultWatchInterval        = time.Minute
+	defaultWatchJitter          = 0.1
+	defaultWatchMoveThreshold   = 100
+	defaultWatchSpeedThreshold  = 1
+	defaultWatchCourseThreshold = 10
+	defaultWatchDarkAfter       = 30 * time.Minute
+)
+
+// WatchEventType identifies the kind of change a WatchEvent reports.
+type WatchEventType string
+
+const (
+	// WatchPosition reports every new position. It is only emitted when
+	// WatcherOptions.EmitPositions is set.
+	WatchPosition WatchEventType = "position"
+
+	// WatchMoved reports a vessel that moved at least MoveThreshold meters
+	// since its previous report.
+	WatchMoved WatchEventType = "moved"
+
+	// WatchSpeedChanged reports a change in speed over ground of at least
+	// SpeedThreshold knots.
+	WatchSpeedChanged WatchEventType = "speed_changed"
+
+	// WatchCourseChanged reports a change in course over ground of at least
+	// CourseThreshold degrees.
+	WatchCourseChanged WatchEventType = "course_changed"
+
+	// WatchNavStatusChanged reports a change in AIS navigational status.
+	WatchNavStatusChanged WatchEventType = "nav_status_changed"
+
+	// WatchDark reports a vessel whose latest report is older than
+	// DarkAfter.
+	WatchDark WatchEventType = "dark"
+
+	// WatchReappeared reports a new position from a vessel that had gone
+	// dark.
+	WatchReappeared WatchEventType = "reappeared"
+
+	// WatchError reports a failed poll. The watcher keeps running.
+	WatchError WatchEventType = "error"
+)
+
+// WatchEvent is a change detected by a Watcher.
+type WatchEvent struct {
+	Type WatchEventType
+
+	// ID is the watched IMO or MMSI number. It is zero for WatchError.
+	ID int
+
+	// Position is the vessel's latest known position, and Previous the one
+	// before it, if any.
+	Position VesselPosition
+	Previous *VesselPosition
+
+	// Distance is the distance in meters between Previous and Position.
+	Distance float64
+
+	// At is the time the change was detected.
+	At time.Time
+
+	// Err is the poll error for WatchError events.
+	Err error
+}
+
+// WatcherOptions configures a Watcher. A nil *WatcherOptions uses the
+// defaults.
+type WatcherOptions struct {
+	// IDType selects whether the watched IDs are IMO (default) or MMSI
+	// numbers.
+	IDType GetVesselsPositionsParamsFilterIdType
+
+	// Interval is the time between polls. Defaults to one minute.
+	Interval time.Duration
+
+	// Jitter randomizes each interval by up to this fraction in either
+	// direction, so that many watchers do not poll in lockstep. Defaults to
+	// 0.1; set a negative value to disable.
+	Jitter float64
+
+	// MoveThreshold, SpeedThreshold and CourseThreshold are the minimum
+	// changes, in meters, knots and degrees, reported as WatchMoved,
+	// WatchSpeedChanged and WatchCourseChanged. They default to 100 m,
+	// 1 knot and 10 degrees.
+	MoveThreshold   float64
+	SpeedThreshold  float64
+	CourseThreshold float64
+
+	// DarkAfter is how old a vessel's latest report may get before it is
+	// reported as dark. Defaults to 30 minutes.
+	DarkAfter time.Duration
+
+	// EmitPositions also emits a WatchPosition event for every new report.
+	EmitPositions bool
+
+	// Buffer is the capacity of the events channel.
+	Buffer int
+
+	// Batch configures the underlying PositionsBatch calls. Its IDType is
+	// ignored in favour of IDType above.
+	Batch *PositionsBatchOptions
+}
+
+// Watcher polls the positions of a set of vessels and emits an event for
+// each change it detects. Create one with NewWatcher, start it with Run and
+// read Events until it is closed.
+type Watcher struct {
+	vessels *VesselsService
+	opts    WatcherOptions
+	events  chan WatchEvent
+	now     func() time.Time
+
+	mu      sync.Mutex
+	running bool
+	state   map[int]*watchState
+}
+
+// watchState is what a Watcher knows about one vessel.
+type watchState struct {
+	last *VesselPosition
+	seen time.Time
+	dark bool
+}
+
+// NewWatcher returns a Watcher for the vessels with the given IDs.
+func NewWatcher(vessels *VesselsService, ids []int, opts *WatcherOptions) *Watcher {
+	o := WatcherOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.IDType == "" {
+		o.IDType = GetVesselsPositionsParamsFilterIdTypeImo
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultWatchInterval
+	}
+	if o.Jitter == 0 {
+		o.Jitter = defaultWatchJitter
+	}
+	if o.MoveThreshold <= 0 {
+		o.MoveThreshold = defaultWatchMoveThreshold
+	}
+	if o.SpeedThreshold <= 0 {
+		o.SpeedThreshold = defaultWatchSpeedThreshold
+	}
+	if o.CourseThreshold <= 0 {
+		o.CourseThreshold = defaultWatchCourseThreshold
+	}
+	if o.DarkAfter <= 0 {
+		o.DarkAfter = defaultWatchDarkAfter
+	}
+	w := &Watcher{
+		vessels: vessels,
+		opts:    o,
+		events:  make(chan WatchEvent, max(o.Buffer, 0)),
+		now:     time.Now,
+		state:   make(map[int]*watchState),
+	}
+	w.Add(ids...)
+	return w
+}
+
+// Events returns the channel on which events are delivered. It is closed
+// when Run returns.
+func (w *Watcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Add starts watching the given vessels from the next poll.
+func (w *Watcher) Add(ids ...int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := w.state[id]; !ok {
+			w.state[id] = &watchState{}
+		}
+	}
+}
+
+// Remove stops watching the given vessels.
+func (w *Watcher) Remove(ids ...int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range ids {
+		delete(w.state, id)
+	}
+}
+
+// IDs returns the watched vessel IDs in ascending order.
+func (w *Watcher) IDs() []int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ids := make([]int, 0, len(w.state))
+	for id := range w.state {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Run polls immediately and then every Interval until ctx is cancelled,
+// then closes the events channel and returns nil. A poll in progress when
+// ctx is cancelled is abandoned. Run may only be called once.
+func (w *Watcher) Run(ctx context.Context) error {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return errors.New("vesselapi: watcher already running")
+	}
+	w.running = true
+	w.mu.Unlock()
+	defer close(w.events)
+
+	for {
+		for _, ev := range w.poll(ctx) {
+			select {
+			case w.events <- ev:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		if sleepCtx(ctx, jitterDuration(w.opts.Interval, w.opts.Jitter)) != nil {
+			return nil
+		}
+	}
+}
+
+// jitterDuration returns d randomized by up to frac of its length in either
+// direction. A non-positive frac returns d unchanged.
+func jitterDuration(d time.Duration, frac float64) time.Duration {
+	f := float64(d)
+	if frac > 0 {
+		f += f * frac * (2*rand.Float64() - 1) //nolint:gosec
+	}
+	return time.Duration(f)
+}
+
+// poll fetches the current positions and returns the resulting events.
+func (w *Watcher) poll(ctx context.Context) []WatchEvent {
+	ids := w.IDs()
+	if len(ids) == 0 {
+		return nil
+	}
+	batch := PositionsBatchOptions{}
+	if w.opts.Batch != nil {
+		batch = *w.opts.Batch
+	}
+	batch.IDType = w.opts.IDType
+	res, err := w.vessels.PositionsBatch(ctx, ids, &batch)
+	now := w.now()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return []WatchEvent{{Type: WatchError, At: now, Err: err}}
+	}
+
+	latest := latestByID(res.Positions, w.opts.IDType)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var events []WatchEvent
+	for _, id := range ids {
+		st, ok := w.state[id]
+		if !ok {
+			continue // removed during the poll
+		}
+		if p, ok := latest[id]; ok {
+			events = append(events, w.update(id, st, p, now)...)
+		}
+		if st.last != nil && !st.dark && now.Sub(st.seen) > w.opts.DarkAfter {
+			st.dark = true
+			events = append(events, WatchEvent{Type: WatchDark, ID: id, Position: *st.last, At: now})
+		}
+	}
+	return events
+}
+
+// positionChanged reports whether a differs from b when neither has a
+// usable timestamp, in which case newerPosition cannot order them.
+func positionChanged(a, b VesselPosition) bool {
+	_, errA := parseTimestamp(Deref(a.Timestamp))
+	_, errB := parseTimestamp(Deref(b.Timestamp))
+	return errA != nil && errB != nil && !reflect.DeepEqual(a, b)
+}
+
+// update records p for the vessel and returns the events it causes.
+func (w *Watcher) update(id int, st *watchState, p VesselPosition, now time.Time) []WatchEvent {
+	prev := st.last
+	if prev != nil && !newerPosition(p, *prev) && !positionChanged(p, *prev) {
+		return nil
+	}
+	st.last = &p
+	st.seen = now
+	if ts, err := parseTimestamp(Deref(p.Timestamp)); err == nil {
+		st.seen = ts
+	}
+
+	var events []WatchEvent
+	emit := func(t WatchEventType, distance float64) {
+		events = append(events, WatchEvent{Type: t, ID: id, Position: p, Previous: prev, Distance: distance, At: now})
+	}
+	if w.opts.EmitPositions {
+		emit(WatchPosition, 0)
+	}
+	if prev == nil {
+		return events
+	}
+	if st.dark && now.Sub(st.seen) <= w.opts.DarkAfter {
+		st.dark = false
+		emit(WatchReappeared, 0)
+	}
+	a, okA := PositionPoint(*prev)
+	b, okB := PositionPoint(p)
+	if okA && okB {
+		if d := geo.Distance(a, b); d >= w.opts.MoveThreshold {
+			emit(WatchMoved, d)
+		}
+	}
+	if sa, sb, ok := validSpeeds(*prev, p); ok && math.Abs(sb-sa) >= w.opts.SpeedThreshold {
+		emit(WatchSpeedChanged, 0)
+	}
+	if ca, cb, ok := validCourses(*prev, p); ok && courseDelta(ca, cb) >= w.opts.CourseThreshold {
+		emit(WatchCourseChanged, 0)
+	}
+	if prev.NavStatus != nil && p.NavStatus != nil && *prev.NavStatus != *p.NavStatus {
+		emit(WatchNavStatusChanged, 0)
+	}
+	return events
+}
+
+// validSpeeds returns the speeds over ground of a and b in knots, if both
+// are available.
+func validSpeeds(a, b VesselPosition) (float64, float64, bool) {
+	if a.Sog == nil || b.Sog == nil || *a.Sog >= sogNotAvailable || *b.Sog >= sogNotAvailable {
+		return 0, 0, false
+	}
+	return float64(*a.Sog), float64(*b.Sog), true
+}
+
+// validCourses returns the courses over ground of a and b in degrees, if
+// both are available.
+func validCourses(a, b VesselPosition) (float64, float64, bool) {
+	if a.Cog == nil || b.Cog == nil || *a.Cog >= cogNotAvailable || *b.Cog >= cogNotAvailable {
+		return 0, 0, false
+	}
+	return float64(*a.Cog), float64(*b.Cog), true
+}
+
+// courseDelta returns the smallest angle between two courses in degrees.
+func courseDelta(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
@@ -0,0 +1,224 @@
+package vesselapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedPositions serves successive position responses, repeating the
+// last one once the script runs out.
+type scriptedPositions struct {
+	mu    sync.Mutex
+	pages [][]VesselPosition
+}
+
+func (s *scriptedPositions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	page := s.pages[0]
+	if len(s.pages) > 1 {
+		s.pages = s.pages[1:]
+	}
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(VesselPositionsResponse{VesselPositions: &page})
+}
+
+func watchPos(imo int, ts string, lat, lon float64, sog, cog float32, nav int) VesselPosition {
+	return VesselPosition{
+		Imo: Ptr(imo), Timestamp: Ptr(ts),
+		Latitude: Ptr(lat), Longitude: Ptr(lon),
+		Sog: Ptr(sog), Cog: Ptr(cog), NavStatus: Ptr(nav),
+	}
+}
+
+func eventTypes(events []WatchEvent) map[WatchEventType]int {
+	m := make(map[WatchEventType]int)
+	for _, ev := range events {
+		m[ev.Type]++
+	}
+	return m
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	script := &scriptedPositions{pages: [][]VesselPosition{
+		{watchPos(1, "2025-01-01T00:00:00Z", 0, 0, 10, 90, 0)},
+		// Same report again: no events.
+		{watchPos(1, "2025-01-01T00:00:00Z", 0, 0, 10, 90, 0)},
+		// Moved about 1.1 km, slowed, turned and anchored.
+		{watchPos(1, "2025-01-01T00:05:00Z", 0, 0.01, 2, 180, 1)},
+		// Small changes below every threshold.
+		{watchPos(1, "2025-01-01T00:10:00Z", 0, 0.0105, 2.5, 185, 1)},
+		// No reports for an hour.
+		{},
+		{},
+		{watchPos(1, "2025-01-01T01:30:00Z", 0, 0.0105, 2.5, 185, 1)},
+	}}
+	ts := httptest.NewServer(script)
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := NewWatcher(vc.Vessels, []int{1}, nil)
+	now := time.Date(2025, 1, 1, 0, 0, 30, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if events := w.poll(ctx); len(events) != 0 {
+		t.Errorf("expected no events for the first report, got %v", eventTypes(events))
+	}
+	if events := w.poll(ctx); len(events) != 0 {
+		t.Errorf("expected no events for a repeated report, got %v", eventTypes(events))
+	}
+
+	now = now.Add(5 * time.Minute)
+	events := w.poll(ctx)
+	got := eventTypes(events)
+	for _, typ := range []WatchEventType{WatchMoved, WatchSpeedChanged, WatchCourseChanged, WatchNavStatusChanged} {
+		if got[typ] != 1 {
+			t.Errorf("expected one %s event, got %v", typ, got)
+		}
+	}
+	for _, ev := range events {
+		if ev.ID != 1 || ev.Previous == nil || Deref(ev.Previous.Timestamp) != "2025-01-01T00:00:00Z" {
+			t.Errorf("unexpected event %+v", ev)
+		}
+		if ev.Type == WatchMoved && (ev.Distance < 1000 || ev.Distance > 1200) {
+			t.Errorf("expected about 1.1 km moved, got %.0f m", ev.Distance)
+		}
+	}
+
+	now = now.Add(5 * time.Minute)
+	if events := w.poll(ctx); len(events) != 0 {
+		t.Errorf("expected no events below thresholds, got %v", eventTypes(events))
+	}
+
+	now = now.Add(time.Hour)
+	events = w.poll(ctx)
+	if len(events) != 1 || events[0].Type != WatchDark {
+		t.Fatalf("expected one dark event, got %v", eventTypes(events))
+	}
+	if events := w.poll(ctx); len(events) != 0 {
+		t.Errorf("expected dark to be reported once, got %v", eventTypes(events))
+	}
+
+	now = time.Date(2025, 1, 1, 1, 30, 30, 0, time.UTC)
+	events = w.poll(ctx)
+	if got := eventTypes(events); got[WatchReappeared] != 1 || len(events) != 1 {
+		t.Errorf("expected one reappeared event, got %v", got)
+	}
+}
+
+func TestWatcher_PollWithoutTimestamps(t *testing.T) {
+	untimed := func(lat, lon float64) VesselPosition {
+		p := watchPos(1, "", lat, lon, 10, 90, 0)
+		p.Timestamp = nil
+		return p
+	}
+	script := &scriptedPositions{pages: [][]VesselPosition{
+		{untimed(0, 0)},
+		// Moved about 1.1 km: a new report despite the missing timestamp.
+		{untimed(0, 0.01)},
+		// Same report again: no events.
+		{untimed(0, 0.01)},
+	}}
+	ts := httptest.NewServer(script)
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := NewWatcher(vc.Vessels, []int{1}, nil)
+	ctx := context.Background()
+
+	if events := w.poll(ctx); len(events) != 0 {
+		t.Errorf("expected no events for the first report, got %v", eventTypes(events))
+	}
+	if got := eventTypes(w.poll(ctx)); got[WatchMoved] != 1 {
+		t.Errorf("expected a moved event, got %v", got)
+	}
+	if events := w.poll(ctx); len(events) != 0 {
+		t.Errorf("expected no events for a repeated report, got %v", eventTypes(events))
+	}
+}
+
+func TestWatcher_EmitPositionsAndErrors(t *testing.T) {
+	fail := true
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":{"message":"boom"}}`))
+			return
+		}
+		json.NewEncoder(w).Encode(VesselPositionsResponse{VesselPositions: &[]VesselPosition{
+			{Mmsi: Ptr(7), Timestamp: Ptr(time.Now().UTC().Format(time.RFC3339))},
+		}})
+	}))
+	defer ts.Close()
+
+	vc, err := NewVesselClient("test-key", WithVesselBaseURL(ts.URL), WithVesselRetry(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := NewWatcher(vc.Vessels, []int{7}, &WatcherOptions{
+		IDType:        GetVesselsPositionsParamsFilterIdTypeMmsi,
+		Interval:      5 * time.Millisecond,
+		EmitPositions: true,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	ev := <-w.Events()
+	if ev.Type != WatchError || ev.Err == nil {
+		t.Errorf("expected error event, got %+v", ev)
+	}
+	ev = <-w.Events()
+	if ev.Type != WatchPosition || ev.ID != 7 {
+		t.Errorf("expected position event for MMSI 7, got %+v", ev)
+	}
+
+	cancel()
+	for range w.Events() {
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+	if err := w.Run(context.Background()); err == nil {
+		t.Error("expected error running a watcher twice")
+	}
+}
+
+func TestWatcher_AddRemove(t *testing.T) {
+	w := NewWatcher(nil, []int{3, 1}, nil)
+	w.Add(2, 1)
+	w.Remove(3)
+	if got := w.IDs(); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("expected IDs [1 2], got %v", got)
+	}
+	if events := NewWatcher(nil, nil, nil).poll(context.Background()); events != nil {
+		t.Errorf("expected no events without vessels, got %v", events)
+	}
+}
+
+func TestCourseDelta(t *testing.T) {
+	for _, tt := range []struct{ a, b, want float64 }{
+		{10, 350, 20}, {350, 10, 20}, {90, 270, 180}, {0, 0, 0},
+	} {
+		if got := courseDelta(tt.a, tt.b); got != tt.want {
+			t.Errorf("courseDelta(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}